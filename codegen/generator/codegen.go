@@ -99,6 +99,16 @@ func Generate() {
 		databases = []db.DatabaseI{db.GetDatabase("goradd")}
 	}
 
+	var models []*db.Model
+	for _, database := range databases {
+		if database.Model() != nil {
+			models = append(models, database.Model())
+		}
+	}
+	if err := db.CheckExternalReferences(models); err != nil {
+		panic(err)
+	}
+
 	// map object names to tables, making sure there are no duplicates
 
 	for _, database := range databases {
@@ -72,6 +72,12 @@ func DefaultControlType(ref interface{}) string {
 			return "github.com/goradd/goradd/pkg/page/control/textbox/FloatTextbox"
 		case query.ColTypeBool:
 			return "github.com/goradd/goradd/pkg/page/control/button/Checkbox"
+		case query.ColTypeJSON:
+			return ""
+		case query.ColTypeDecimal:
+			return "github.com/goradd/goradd/pkg/page/control/textbox/DecimalTextbox"
+		case query.ColTypeUUID:
+			return "github.com/goradd/goradd/pkg/page/control/Span"
 		case query.ColTypeUnknown:
 			return ""
 		default:
@@ -49,6 +49,12 @@ CREATE TABLE `reverse` (
                            `name` varchar(100) NOT NULL
 ) ENGINE=InnoDB DEFAULT CHARSET=latin1;
 
+CREATE TABLE `tenant_item` (
+                               `id` int(11) NOT NULL,
+                               `tenant_id` int(11) NOT NULL,
+                               `name` varchar(100) NOT NULL
+) ENGINE=InnoDB DEFAULT CHARSET=latin1 COMMENT='{"tenant":"tenant_id"}';
+
 CREATE TABLE `two_key` (
                            `server` varchar(50) NOT NULL,
                            `directory` varchar(50) NOT NULL,
@@ -134,6 +140,9 @@ ALTER TABLE `forward_restrict_unique`
 ALTER TABLE `reverse`
     ADD PRIMARY KEY (`id`);
 
+ALTER TABLE `tenant_item`
+    ADD PRIMARY KEY (`id`);
+
 ALTER TABLE `two_key`
     ADD PRIMARY KEY (`server`,`directory`);
 
@@ -166,6 +175,9 @@ ALTER TABLE `forward_restrict_unique`
 ALTER TABLE `reverse`
     MODIFY `id` int(11) NOT NULL AUTO_INCREMENT, AUTO_INCREMENT=61;
 
+ALTER TABLE `tenant_item`
+    MODIFY `id` int(11) NOT NULL AUTO_INCREMENT;
+
 ALTER TABLE `type_test`
     MODIFY `id` int(11) NOT NULL AUTO_INCREMENT, AUTO_INCREMENT=2;
 
@@ -339,6 +339,47 @@ ALTER TABLE goradd_unit.reverse_id_seq OWNER TO root;
 ALTER SEQUENCE goradd_unit.reverse_id_seq OWNED BY goradd_unit.reverse.id;
 
 
+--
+-- Name: tenant_item; Type: TABLE; Schema: goradd_unit; Owner: root
+--
+
+CREATE TABLE goradd_unit.tenant_item (
+    id integer NOT NULL,
+    tenant_id integer NOT NULL,
+    name character varying(100) NOT NULL
+);
+
+
+ALTER TABLE goradd_unit.tenant_item OWNER TO root;
+
+--
+-- Name: TABLE tenant_item; Type: COMMENT; Schema: goradd_unit; Owner: root
+--
+
+COMMENT ON TABLE goradd_unit.tenant_item IS '{"tenant":"tenant_id"}';
+
+
+--
+-- Name: tenant_item_id_seq; Type: SEQUENCE; Schema: goradd_unit; Owner: root
+--
+
+CREATE SEQUENCE goradd_unit.tenant_item_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+ALTER TABLE goradd_unit.tenant_item_id_seq OWNER TO root;
+
+--
+-- Name: tenant_item_id_seq; Type: SEQUENCE OWNED BY; Schema: goradd_unit; Owner: root
+--
+
+ALTER SEQUENCE goradd_unit.tenant_item_id_seq OWNED BY goradd_unit.tenant_item.id;
+
+
 --
 -- TOC entry 232 (class 1259 OID 16621)
 -- Name: two_key; Type: TABLE; Schema: goradd_unit; Owner: root
@@ -509,6 +550,13 @@ ALTER TABLE ONLY goradd_unit.forward_restrict_unique ALTER COLUMN id SET DEFAULT
 ALTER TABLE ONLY goradd_unit.reverse ALTER COLUMN id SET DEFAULT nextval('goradd_unit.reverse_id_seq'::regclass);
 
 
+--
+-- Name: tenant_item id; Type: DEFAULT; Schema: goradd_unit; Owner: root
+--
+
+ALTER TABLE ONLY goradd_unit.tenant_item ALTER COLUMN id SET DEFAULT nextval('goradd_unit.tenant_item_id_seq'::regclass);
+
+
 --
 -- TOC entry 3252 (class 2604 OID 16628)
 -- Name: type_test id; Type: DEFAULT; Schema: goradd_unit; Owner: root
@@ -823,6 +871,14 @@ ALTER TABLE ONLY goradd_unit.reverse
     ADD CONSTRAINT idx_16617_primary PRIMARY KEY (id);
 
 
+--
+-- Name: tenant_item tenant_item_pkey; Type: CONSTRAINT; Schema: goradd_unit; Owner: root
+--
+
+ALTER TABLE ONLY goradd_unit.tenant_item
+    ADD CONSTRAINT tenant_item_pkey PRIMARY KEY (id);
+
+
 --
 -- TOC entry 3281 (class 2606 OID 16668)
 -- Name: two_key idx_16621_primary; Type: CONSTRAINT; Schema: goradd_unit; Owner: root
@@ -0,0 +1,143 @@
+PRAGMA foreign_keys = OFF;
+
+CREATE TABLE "double_index" (
+    "id" INT NOT NULL PRIMARY KEY,
+    "field_int" INT NOT NULL,
+    "field_string" VARCHAR(50) NOT NULL
+);
+CREATE UNIQUE INDEX "idx_dbl" ON "double_index" ("field_int", "field_string");
+
+CREATE TABLE "reverse" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "name" VARCHAR(100) NOT NULL
+);
+
+INSERT INTO "reverse" ("id", "name") VALUES
+    (123, 'testReverse'),
+    (124, 'testReverse'),
+    (125, 'testReverse'),
+    (126, 'testReverse'),
+    (127, 'testReverse'),
+    (128, 'testReverse'),
+    (129, 'testReverse'),
+    (132, 'testReverse'),
+    (133, 'testReverse');
+
+CREATE TABLE "forward_cascade" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "name" VARCHAR(100) NOT NULL,
+    "reverse_id" INT DEFAULT NULL REFERENCES "reverse" ("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX "forward_cascade_reverse_id" ON "forward_cascade" ("reverse_id");
+
+CREATE TABLE "forward_cascade_unique" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "name" VARCHAR(100) NOT NULL,
+    "reverse_id" INT DEFAULT NULL REFERENCES "reverse" ("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE UNIQUE INDEX "forward_cascade_unique_reverse_id" ON "forward_cascade_unique" ("reverse_id");
+
+CREATE TABLE "forward_null" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "name" VARCHAR(100) NOT NULL,
+    "reverse_id" INT DEFAULT NULL REFERENCES "reverse" ("id") ON DELETE SET NULL ON UPDATE SET NULL
+);
+CREATE INDEX "forward_null_reverse_id" ON "forward_null" ("reverse_id");
+
+INSERT INTO "forward_null" ("id", "name", "reverse_id") VALUES
+    (34, 'testForward1', NULL),
+    (35, 'Other', 123),
+    (36, 'testForward1', NULL),
+    (37, 'Other', 124),
+    (38, 'testForward1', NULL),
+    (39, 'Other', 125),
+    (40, 'testForward1', 126),
+    (41, 'Other', 126),
+    (42, 'testForward1', NULL),
+    (43, 'Other', 127),
+    (44, 'testForward3', 127),
+    (45, 'testForward1', 128),
+    (46, 'Other', 128),
+    (47, 'testForward1', 129),
+    (48, 'Other', 129),
+    (51, 'Other', 132),
+    (52, 'testForward3', 132),
+    (53, 'testForward1', 133),
+    (54, 'Other', 133);
+
+CREATE TABLE "forward_null_unique" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "name" VARCHAR(100) NOT NULL,
+    "reverse_id" INT DEFAULT NULL REFERENCES "reverse" ("id") ON DELETE SET NULL ON UPDATE SET NULL
+);
+CREATE UNIQUE INDEX "forward_null_unique_reverse_id" ON "forward_null_unique" ("reverse_id");
+
+CREATE TABLE "forward_restrict" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "name" VARCHAR(100) NOT NULL,
+    "reverse_id" INT NOT NULL REFERENCES "reverse" ("id") ON DELETE RESTRICT ON UPDATE RESTRICT
+);
+CREATE INDEX "forward_restrict_reverse_id" ON "forward_restrict" ("reverse_id");
+
+CREATE TABLE "forward_restrict_unique" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "name" VARCHAR(100) NOT NULL,
+    "reverse_id" INT DEFAULT NULL REFERENCES "reverse" ("id") ON DELETE RESTRICT ON UPDATE RESTRICT
+);
+CREATE UNIQUE INDEX "forward_restrict_unique_reverse_id" ON "forward_restrict_unique" ("reverse_id");
+
+CREATE TABLE "tenant_item" ( -- {"tenant":"tenant_id"}
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "tenant_id" INT NOT NULL,
+    "name" VARCHAR(100) NOT NULL
+);
+
+CREATE TABLE "two_key" (
+    "server" VARCHAR(50) NOT NULL,
+    "directory" VARCHAR(50) NOT NULL,
+    "file_name" VARCHAR(50) NOT NULL,
+    PRIMARY KEY ("server", "directory")
+);
+
+INSERT INTO "two_key" ("server", "directory", "file_name") VALUES
+    ('cnn.com', 'us', 'news'),
+    ('google.com', 'drive', ''),
+    ('google.com', 'mail', 'mail.html'),
+    ('google.com', 'news', 'news.php'),
+    ('mail.google.com', 'mail', 'inbox'),
+    ('yahoo.com', '', '');
+
+CREATE TABLE "type_test" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "date" DATE DEFAULT NULL,
+    "time" TIME DEFAULT NULL,
+    "date_time" DATETIME DEFAULT NULL,
+    "ts" TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    "test_int" INT DEFAULT 5,
+    "test_float" FLOAT DEFAULT NULL,
+    "test_double" DOUBLE NOT NULL,
+    "test_text" TEXT,
+    "test_bit" BOOLEAN DEFAULT NULL,
+    "test_varchar" VARCHAR(10) DEFAULT NULL,
+    "test_blob" BLOB NOT NULL
+);
+
+INSERT INTO "type_test" ("id", "date", "time", "date_time", "ts", "test_int", "test_float", "test_double", "test_text", "test_bit", "test_varchar", "test_blob") VALUES
+    (1, '2019-01-02', '06:17:28', '2019-01-02 06:17:28', '2002-07-02 14:04:03', 5, 1.2, 3.33, 'Sample', 1, 'Sample', X'61626364');
+
+CREATE TABLE "unsupported_types" (
+    "type_decimal" DECIMAL(10,4) NOT NULL,
+    "type_double" DOUBLE NOT NULL,
+    "type_tiny_blob" BLOB NOT NULL,
+    "type_longtext" TEXT NOT NULL,
+    "type_small" SMALLINT NOT NULL,
+    "type_medium" MEDIUMINT NOT NULL,
+    "type_big" BIGINT NOT NULL,
+    "type_serial" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "type_unsigned" INT UNSIGNED NOT NULL,
+    "type_multfk1" VARCHAR(50) NOT NULL,
+    "type_multifk2" VARCHAR(50) NOT NULL
+);
+CREATE INDEX "unsupported_types_type_multfk1" ON "unsupported_types" ("type_multfk1", "type_multifk2");
+
+PRAGMA foreign_keys = ON;
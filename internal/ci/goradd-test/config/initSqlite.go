@@ -0,0 +1,26 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/goradd/goradd/pkg/orm/db"
+	"github.com/goradd/goradd/pkg/orm/db/sql/sqlite"
+	_ "modernc.org/sqlite"
+)
+
+func initDatabases() {
+	dir := os.TempDir()
+
+	key := "goradd"
+	db1 := sqlite.NewDB(key, "sqlite", "file:"+filepath.Join(dir, "goradd.db")+"?_pragma=foreign_keys(1)")
+	db1.Analyze(sqlite.DefaultOptions())
+
+	db.AddDatabase(db1, key)
+
+	key = "goraddUnit"
+	db2 := sqlite.NewDB(key, "sqlite", "file:"+filepath.Join(dir, "goradd_unit.db")+"?_pragma=foreign_keys(1)")
+	db2.Analyze(sqlite.DefaultOptions())
+
+	db.AddDatabase(db2, key)
+}
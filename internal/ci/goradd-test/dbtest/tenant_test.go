@@ -0,0 +1,25 @@
+package dbtest
+
+import (
+	"github.com/goradd/goradd/pkg/orm/db"
+	"github.com/stretchr/testify/assert"
+	model2 "goradd-project/gen/goraddUnit/model"
+	"testing"
+)
+
+func TestTenantDelete(t *testing.T) {
+	ctx1 := db.WithTenant(getContext(), 1)
+	ctx2 := db.WithTenant(getContext(), 2)
+
+	item := model2.NewTenantItem()
+	item.SetName("tenant 1")
+	item.Save(ctx1)
+	defer model2.QueryTenantItems(db.WithoutTenant(getContext())).Delete()
+	assert.Equal(t, 1, item.TenantID())
+
+	model2.DeleteTenantItem(ctx2, item.ID())
+	assert.NotNil(t, model2.LoadTenantItem(ctx1, item.ID()), "another tenant cannot delete the record")
+
+	model2.DeleteTenantItem(ctx1, item.ID())
+	assert.Nil(t, model2.LoadTenantItem(ctx1, item.ID()))
+}
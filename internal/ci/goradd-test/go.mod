@@ -4,4 +4,5 @@ require (
 	github.com/go-sql-driver/mysql latest
 	github.com/jackc/pgx/v5 latest
 	github.com/goradd/goradd latest
+	modernc.org/sqlite latest
 )
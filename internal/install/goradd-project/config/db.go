@@ -14,6 +14,7 @@ func initDatabases() {
 	// Uncomment one of these to make the examples work
 	//addMysqlGoraddDatabase()
 	//addPostgresGoraddDatabase()
+	//addSqliteGoraddDatabase()
 
 	// add your own development databases
 	// addMyDatabase()
@@ -37,6 +38,11 @@ func addMysqlGoraddDatabase() {
 	// Tell the database to analyze its own structure
 	db1.Analyze(mysql2.DefaultOptions())
 
+	// To send queries from the query builder to read replicas, add them here. For example:
+	//   replicaCfg := cfg.Clone()
+	//   replicaCfg.Addr = "replica1:3306"
+	//   db1.AddReadReplica("", replicaCfg)
+
 	if !config.Release {
 		db1.StartProfiling()
 	}
@@ -68,6 +74,27 @@ func addPostgresGoraddDatabase() {
 	db.AddDatabase(db1, key)
 }
 
+// addSqliteGoraddDatabase adds the SQLite goradd sample database to the database list.
+// You will need this to run some of the examples.
+// You also need to create the database itself from the sqlite.goradd.sql file in the examples/db directory,
+// and import a SQLite driver, which will register itself with the database/sql package.
+// To use it, uncomment the function and add the following imports:
+//
+//	"github.com/goradd/goradd/pkg/orm/db/sql/sqlite"
+//	_ "modernc.org/sqlite"
+/*
+func addSqliteGoraddDatabase() {
+	key := "goradd"
+	db1 := sqlite.NewDB(key, "sqlite", "file:goradd.db?_pragma=foreign_keys(1)")
+	db1.Analyze(sqlite.DefaultOptions())
+
+	if !config.Release {
+		db1.StartProfiling()
+	}
+
+	db.AddDatabase(db1, key)
+}*/
+
 // addMyDatabase is a sample of how to add your own database to the database list.
 // It uses a db.cfg file to hold the credentials for the deployed version of the
 // app. Modify as needed.
@@ -0,0 +1,104 @@
+package control
+
+import (
+	"context"
+	"github.com/goradd/goradd/pkg/page"
+	"github.com/goradd/goradd/pkg/page/control/textbox"
+	"github.com/goradd/html5tag"
+)
+
+type DecimalTextboxI interface {
+	textbox.DecimalI
+}
+
+type DecimalTextbox struct {
+	textbox.DecimalTextbox
+}
+
+func NewDecimalTextbox(parent page.ControlI, id string) *DecimalTextbox {
+	t := new(DecimalTextbox)
+	t.Init(t, parent, id)
+	return t
+}
+
+func (t *DecimalTextbox) DrawingAttributes(ctx context.Context) html5tag.Attributes {
+	a := t.DecimalTextbox.DrawingAttributes(ctx)
+	a.AddClass("form-control")
+	return a
+}
+
+// DecimalTextboxCreator creates a textbox that only accepts decimal numbers.
+// Pass it to AddControls of a control, or as a Child of
+// a FormFieldWrapper.
+type DecimalTextboxCreator struct {
+	// ID is the control id of the html widget and must be unique to the page
+	ID string
+	// Placeholder is the placeholder attribute of the textbox and shows as help text inside the field
+	Placeholder string
+	// Type is the type attribute of the textbox
+	Type string
+	// MinLength is the minimum number of characters that the user is required to enter. If the
+	// length is less than this number, a validation error will be shown.
+	MinLength int
+	// MaxLength is the maximum number of characters that the user is required to enter. If the
+	// length is more than this number, a validation error will be shown.
+	MaxLength int
+	// ColumnCount is the number of characters wide the textbox will be, and becomes the width attribute in the tag.
+	// The actual width is browser dependent. For better control, use a width style property.
+	ColumnCount int
+	// ReadOnly sets the readonly attribute of the textbox, which prevents it from being changed by the user.
+	ReadOnly bool
+	// SaveState will save the text in the textbox, to be restored if the user comes back to the page.
+	// It is particularly helpful when the textbox is being used to filter the results of a query, so that
+	// when the user comes back to the page, he does not have to type the filter text again.
+	SaveState bool
+	// MinValue is the minimum value the user can enter. If the user does not
+	// enter at least this amount, or enters something that is not a number, it will fail validation
+	// and the FormFieldWrapper will show an error.
+	MinValue *textbox.DecimalLimit
+	// MaxValue is the maximum value the user can enter. If the user enter more
+	// than this amount, or enters something that is not a number, it will fail validation
+	// and the FormFieldWrapper will show an error.
+	MaxValue *textbox.DecimalLimit
+	// Value is the initial value of the textbox. Often its best to load the value in a separate Load step after creating the control.
+	Value interface{}
+
+	page.ControlOptions
+}
+
+// Create is called by the framework to create a new control from the Creator. You
+// do not normally need to call this.
+func (c DecimalTextboxCreator) Create(ctx context.Context, parent page.ControlI) page.ControlI {
+	ctrl := NewDecimalTextbox(parent, c.ID)
+	c.Init(ctx, ctrl)
+	return ctrl
+}
+
+// Init is called by implementations of Textboxes to initialize a control with the
+// creator.
+func (c DecimalTextboxCreator) Init(ctx context.Context, ctrl DecimalTextboxI) {
+	// Reuse subclass
+	sub := textbox.DecimalTextboxCreator{
+		Placeholder:    c.Placeholder,
+		Type:           c.Type,
+		MinLength:      c.MinLength,
+		MaxLength:      c.MaxLength,
+		ColumnCount:    c.ColumnCount,
+		ReadOnly:       c.ReadOnly,
+		ControlOptions: c.ControlOptions,
+		SaveState:      c.SaveState,
+		MinValue:       c.MinValue,
+		MaxValue:       c.MaxValue,
+		Value:          c.Value,
+	}
+	sub.Init(ctx, ctrl)
+}
+
+// GetDecimalTextbox is a convenience method to return the control with the given id from the page.
+func GetDecimalTextbox(c page.ControlI, id string) *DecimalTextbox {
+	return c.Page().GetControl(id).(*DecimalTextbox)
+}
+
+func init() {
+	page.RegisterControl(&DecimalTextbox{})
+}
@@ -50,6 +50,10 @@ func BootstrapCodegenSetup() {
 				return "github.com/goradd/goradd/pkg/bootstrap/control/FloatTextbox"
 			case query.ColTypeFloat64:
 				return "github.com/goradd/goradd/pkg/bootstrap/control/FloatTextbox"
+			case query.ColTypeDecimal:
+				return "github.com/goradd/goradd/pkg/bootstrap/control/DecimalTextbox"
+			case query.ColTypeUUID:
+				return "github.com/goradd/goradd/pkg/page/control/Span"
 			case query.ColTypeBool:
 				return "github.com/goradd/goradd/pkg/bootstrap/control/Checkbox"
 			case query.ColTypeTime:
@@ -0,0 +1,35 @@
+package generator
+
+import (
+	"fmt"
+	generator2 "github.com/goradd/goradd/codegen/generator"
+	"github.com/goradd/goradd/pkg/orm/db"
+	generator3 "github.com/goradd/goradd/pkg/page/control/generator"
+)
+
+func init() {
+	generator2.RegisterControlGenerator(DecimalTextbox{}, "github.com/goradd/goradd/pkg/bootstrap/control/DecimalTextbox")
+}
+
+// DecimalTextbox describes the textbox to the connector dialog and code generator
+type DecimalTextbox struct {
+	generator3.DecimalTextbox // base it on the built-in generator
+}
+
+func (d DecimalTextbox) Imports() []string {
+	return nil // the creator does not use min and max values
+}
+
+func (d DecimalTextbox) GenerateCreator(ref interface{}, desc *generator2.ControlDescription) (s string) {
+	col := ref.(*db.Column)
+
+	s = fmt.Sprintf(
+		`%s.DecimalTextboxCreator{
+			ID:        p.ID() + "-%s",
+			ControlOptions: page.ControlOptions{
+				IsRequired:      %#v,
+				DataConnector: %s{},
+			},
+		}`, desc.Package, desc.ControlID, !col.IsNullable, desc.Connector)
+	return
+}
@@ -38,25 +38,47 @@ func (b DefaultBroadcaster) BulkChange(ctx context.Context, dbId string, table s
 	watcher.BroadcastBulkChange(ctx, dbId, table)
 }
 
+// ChangeListener is a function that is called whenever a change to a table is broadcast.
+type ChangeListener func(ctx context.Context, dbId string, table string)
+
+var changeListeners []ChangeListener
+
+// AddChangeListener adds a function that will be called whenever a change to a table is broadcast,
+// whether or not a Broadcaster is set. Listeners are called in the current process before the change is
+// broadcast, and are useful for things like clearing caches. Only call this during app startup.
+func AddChangeListener(l ChangeListener) {
+	changeListeners = append(changeListeners, l)
+}
+
+func notifyListeners(ctx context.Context, dbId string, table string) {
+	for _, l := range changeListeners {
+		l(ctx, dbId, table)
+	}
+}
+
 func Insert(ctx context.Context, dbId string, table string, pk interface{}) {
+	notifyListeners(ctx, dbId, table)
 	if Broadcaster != nil {
 		Broadcaster.Insert(ctx, dbId, table, pk)
 	}
 }
 
 func Update(ctx context.Context, dbId string, table string, pk interface{}, fieldnames ...string) {
+	notifyListeners(ctx, dbId, table)
 	if Broadcaster != nil {
 		Broadcaster.Update(ctx, dbId, table, pk, fieldnames...)
 	}
 }
 
 func Delete(ctx context.Context, dbId string, table string, pk interface{}) {
+	notifyListeners(ctx, dbId, table)
 	if Broadcaster != nil {
 		Broadcaster.Delete(ctx, dbId, table, pk)
 	}
 }
 
 func BulkChange(ctx context.Context, dbId string, table string) {
+	notifyListeners(ctx, dbId, table)
 	if Broadcaster != nil {
 		Broadcaster.BulkChange(ctx, dbId, table)
 	}
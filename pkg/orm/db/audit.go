@@ -0,0 +1,21 @@
+package db
+
+import (
+	"context"
+	"github.com/goradd/goradd/pkg/goradd"
+)
+
+const auditUserContext = goradd.ContextKey("goradd.auditUser")
+
+// WithAuditUser returns a context that records user as the person making changes to the database.
+// Generated Save functions put the user in the createdBy and updatedBy audit columns of the records they save.
+// The type of user must be the Go type of those columns, usually the primary key of your user records,
+// or the columns are left alone.
+func WithAuditUser(ctx context.Context, user interface{}) context.Context {
+	return context.WithValue(ctx, auditUserContext, user)
+}
+
+// AuditUser returns the user that was put in the context with WithAuditUser, or nil if there is none.
+func AuditUser(ctx context.Context) interface{} {
+	return ctx.Value(auditUserContext)
+}
@@ -9,8 +9,46 @@ import (
 
 const (
 	currentTime = "now"
+	// newUUID is the default value of UUID primary keys that are generated by the application
+	newUUID = "new"
 )
 
+// AuditType is the kind of information an audit column records about changes to a record.
+type AuditType string
+
+const (
+	AuditCreatedAt AuditType = "createdAt" // The time the record was inserted
+	AuditUpdatedAt AuditType = "updatedAt" // The time the record was last saved
+	AuditCreatedBy AuditType = "createdBy" // The user that inserted the record
+	AuditUpdatedBy AuditType = "updatedBy" // The user that last saved the record
+)
+
+// auditColumnNames are the names of the columns that are audit columns without an audit option.
+var auditColumnNames = map[string]AuditType{
+	"created_at": AuditCreatedAt,
+	"updated_at": AuditUpdatedAt,
+	"created_by": AuditCreatedBy,
+	"updated_by": AuditUpdatedBy,
+}
+
+func (a AuditType) isValid() bool {
+	switch a {
+	case AuditCreatedAt, AuditUpdatedAt, AuditCreatedBy, AuditUpdatedBy:
+		return true
+	}
+	return false
+}
+
+// IsTime returns true if the column records a time, and false if it records a user.
+func (a AuditType) IsTime() bool {
+	return a == AuditCreatedAt || a == AuditUpdatedAt
+}
+
+// IsUpdate returns true if the column is changed each time a record is saved, and not just when it is inserted.
+func (a AuditType) IsUpdate() bool {
+	return a == AuditUpdatedAt || a == AuditUpdatedBy
+}
+
 // Column describes a database column. Most of the information is either
 // gleaned from the structure of the database, or is taken from a file that describes the relationships between
 // different record types. Some information is filled in after analysis. Some information can be
@@ -45,7 +83,7 @@ type Column struct {
 	IsUnique bool
 	// IsTimestamp is true if the field is a timestamp. Timestamps represent a specific point in world time.
 	// By default, timestamps are treated as not editable by the user. To automatically update a timestamp
-	// value when its saved, you should edit the Save method in the model.
+	// value when its saved, make it an audit column with the audit option.
 	IsTimestamp bool
 	// IsDateOnly indicates that we have a time type of column that should only be concerned about the date and not the time.
 	IsDateOnly bool
@@ -53,6 +91,22 @@ type Column struct {
 	IsTimeOnly bool
 	// Comment is the contents of the comment associated with this field
 	Comment string
+	// IsOptimisticLock is true if the column is a version number used for optimistic locking.
+	// The generated Save will increment it, and fail with an OptimisticLockError if another process has changed it.
+	IsOptimisticLock bool
+	// IsSoftDelete is true if the column is the time a record was deleted. Tables with a soft delete column
+	// only mark records as deleted, and generated queries leave out the deleted records.
+	IsSoftDelete bool
+	// IsTenant is true if the column holds the tenant that the record belongs to.
+	IsTenant bool
+	// Audit is the kind of audit column this is, or blank if it is not one. Generated code fills in audit
+	// columns when records are saved.
+	Audit AuditType
+	// JsonGoType is the Go type that the data in a JSON column unmarshalls into, as given by the jsonType option.
+	// It is blank if no type was given.
+	JsonGoType string
+	// JsonGoTypeImport is the import path of the package of JsonGoType, or blank if none is needed.
+	JsonGoTypeImport string
 
 	// Filled in by analyzer
 
@@ -60,6 +114,8 @@ type Column struct {
 	Options map[string]interface{}
 	// ForeignKey is additional information describing a foreign key relationship
 	ForeignKey *ForeignKeyInfo
+	// ExternalReference describes the record that the column refers to if it is in a table of another database.
+	ExternalReference *ExternalReference
 	// modelName is a cache for the internal model name of this column.
 	modelName string
 	// referenceFunction is a cache for the name of the function to call to get to the referenced object. This will work for referenced types too.
@@ -93,14 +149,25 @@ func (cd *Column) DefaultValueAsValue() string {
 			t := cd.DefaultValue.(time.Time)
 			return fmt.Sprintf("time2.NewDateTime(%d, %d, %d, %d, %d, %d, %d)", t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond())
 		}
+	} else if cd.ColumnType == ColTypeUUID {
+		if cd.DefaultValue == newUUID {
+			return "uuid.New()"
+		} else {
+			return fmt.Sprintf("uuid.MustParse(%#v)", fmt.Sprint(cd.DefaultValue))
+		}
+	} else if cd.ColumnType == ColTypeDecimal {
+		return fmt.Sprintf("decimal.RequireFromString(%#v)", fmt.Sprint(cd.DefaultValue))
 	} else {
 		return fmt.Sprintf("%#v", cd.DefaultValue)
 	}
 }
 
-// DefaultValueAsConstant returns the default value of the column as a Go constant
+// DefaultValueAsConstant returns the default value of the column as a Go constant.
+// It returns an empty string if the type of the column cannot be a constant.
 func (cd *Column) DefaultValueAsConstant() string {
-	if cd.ColumnType == ColTypeTime {
+	if cd.ColumnType == ColTypeDecimal || cd.ColumnType == ColTypeUUID {
+		return ""
+	} else if cd.ColumnType == ColTypeTime {
 		if cd.DefaultValue == currentTime {
 			return `time2.Current`
 		} else if cd.DefaultValue == nil {
@@ -121,6 +188,27 @@ func (cd *Column) DefaultValueAsConstant() string {
 	}
 }
 
+// parseJsonType splits the value of a jsonType option into the Go type as it should appear in code, and the
+// import path needed to use it. "github.com/me/types.Address" returns "types.Address" and "github.com/me/types",
+// and "[]*github.com/me/types.Address" returns "[]*types.Address". Types without a package, like
+// "map[string]string", are returned as is.
+func parseJsonType(s string) (goType string, importPath string) {
+	s = strings.TrimSpace(s)
+	typ := strings.TrimLeft(s, "[]*")
+	prefix := s[:len(s)-len(typ)]
+	i := strings.LastIndex(typ, ".")
+	if i < 0 || strings.ContainsAny(typ, "[]") {
+		return s, ""
+	}
+	importPath = typ[:i]
+	pkg := importPath
+	if j := strings.LastIndex(pkg, "/"); j >= 0 {
+		pkg = pkg[j+1:]
+	}
+	goType = prefix + pkg + typ[i:]
+	return
+}
+
 // JsonKey returns the key used for the column when outputting JSON.
 func (cd *Column) JsonKey() string {
 	return cd.modelName
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"github.com/goradd/goradd/pkg/orm/query"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseJsonType(t *testing.T) {
+	tests := []struct {
+		in         string
+		goType     string
+		importPath string
+	}{
+		{"github.com/me/types.Address", "types.Address", "github.com/me/types"},
+		{"[]*github.com/me/types.Address", "[]*types.Address", "github.com/me/types"},
+		{"time.Time", "time.Time", "time"},
+		{"map[string]string", "map[string]string", ""},
+		{"[]string", "[]string", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			goType, importPath := parseJsonType(tt.in)
+			assert.Equal(t, tt.goType, goType)
+			assert.Equal(t, tt.importPath, importPath)
+		})
+	}
+}
+
+func TestSoftDeleteAndAuditColumns(t *testing.T) {
+	desc := DatabaseDescription{
+		Tables: []TableDescription{
+			{
+				Name: "project",
+				Columns: []ColumnDescription{
+					{Name: "id", GoType: "string", IsId: true, IsPk: true},
+					{Name: "created_at", GoType: "time.Time"},
+					{Name: "updated_at", GoType: "time.Time", IsNullable: true},
+					{Name: "updated_by", GoType: "string", Options: map[string]interface{}{AuditOption: false}},
+					{Name: "changer", GoType: "string", Options: map[string]interface{}{AuditOption: "updatedBy"}},
+					{Name: "created_by", GoType: "time.Time"},
+					{Name: "deleted_at", GoType: "time.Time", IsNullable: true},
+				},
+			},
+			{
+				Name:    "person",
+				Options: map[string]interface{}{SoftDeleteOption: "removed"},
+				Columns: []ColumnDescription{
+					{Name: "id", GoType: "string", IsId: true, IsPk: true},
+					{Name: "removed", GoType: "time.Time", IsNullable: true},
+					{Name: "deleted_at", GoType: "time.Time", IsNullable: true},
+				},
+			},
+		},
+	}
+	m := NewModel("test", "", "_id", "_enum", true, desc)
+
+	p := m.Table("project")
+	assert.Equal(t, AuditCreatedAt, p.GetColumn("created_at").Audit)
+	assert.Equal(t, AuditUpdatedAt, p.GetColumn("updated_at").Audit)
+	assert.Empty(t, p.GetColumn("updated_by").Audit)
+	assert.Equal(t, AuditUpdatedBy, p.GetColumn("changer").Audit)
+	assert.Empty(t, p.GetColumn("created_by").Audit, "a user column cannot be a time")
+	assert.Len(t, p.AuditColumns(), 3)
+	assert.True(t, p.HasUpdateAudit())
+	assert.Equal(t, "deleted_at", p.SoftDeleteColumn().DbName)
+
+	assert.Equal(t, "removed", m.Table("person").SoftDeleteColumn().DbName)
+	assert.False(t, m.Table("person").GetColumn("deleted_at").IsSoftDelete)
+}
+
+func TestUUIDPrimaryKey(t *testing.T) {
+	desc := DatabaseDescription{
+		Tables: []TableDescription{
+			{
+				Name: "account",
+				Columns: []ColumnDescription{
+					// as described by Postgres for a uuid key with a default of gen_random_uuid()
+					{Name: "id", NativeType: "uuid", GoType: "uuid.UUID", IsId: true, IsPk: true},
+				},
+			},
+			{
+				Name: "session",
+				Columns: []ColumnDescription{
+					{Name: "id", NativeType: "uuid", GoType: "uuid.UUID", IsPk: true},
+				},
+			},
+		},
+	}
+	m := NewModel("test", "", "_id", "_enum", true, desc)
+
+	for _, table := range []string{"account", "session"} {
+		c := m.Table(table).PrimaryKeyColumn()
+		assert.Equal(t, query.ColTypeUUID, c.ColumnType, table)
+		assert.False(t, c.IsId, table)
+		assert.Equal(t, "uuid.New()", c.DefaultValueAsValue(), table)
+	}
+}
@@ -16,6 +16,8 @@ var datastore struct {
 
 //type LoaderFunc func(QueryBuilderI, map[string]interface{})
 
+// TransactionID identifies a transaction started by Begin. The outermost transaction has an id of 1, and each
+// nested transaction has an id one greater than the transaction it is nested in.
 type TransactionID int
 
 // DatabaseI is the interface that describes the behaviors required for a database implementation.
@@ -47,7 +49,9 @@ type DatabaseI interface {
 		relatedColumn string,
 		relatedPks interface{})
 
-	// Begin will begin a transaction in the database and return the transaction id
+	// Begin will begin a transaction in the database and return the transaction id.
+	// If the context already has a transaction in progress, a nested transaction is started that can be
+	// rolled back without rolling back the outer transaction.
 	Begin(ctx context.Context) TransactionID
 	// Commit will commit the given transaction
 	Commit(ctx context.Context, txid TransactionID)
@@ -55,6 +59,43 @@ type DatabaseI interface {
 	// committed, it will do nothing. Rollback can therefore be used in a defer statement as a safeguard in case
 	// a transaction fails.
 	Rollback(ctx context.Context, txid TransactionID)
+
+	// UpdateE is like Update, but returns an error instead of panicking. Errors that can be identified will
+	// be a UniqueViolationError, ForeignKeyViolationError or DeadlockError.
+	//
+	// If lockName is not empty, it is the name of an optimistic lock column, and the record will only be updated
+	// if that column still has lockValue. If the record was changed by someone else, an OptimisticLockError is returned.
+	UpdateE(ctx context.Context, table string, fields map[string]interface{}, pkName string, pkValue interface{}, lockName string, lockValue interface{}) error
+	// InsertE is like Insert, but returns an error instead of panicking.
+	InsertE(ctx context.Context, table string, fields map[string]interface{}) (string, error)
+	// DeleteE is like Delete, but returns an error instead of panicking.
+	DeleteE(ctx context.Context, table string, pkName string, pkValue interface{}) error
+	// AssociateE is like Associate, but returns an error instead of panicking.
+	AssociateE(ctx context.Context,
+		table string,
+		column string,
+		pk interface{},
+		relatedTable string,
+		relatedColumn string,
+		relatedPks interface{}) error
+	// InsertBatch inserts many new records with as few statements as possible.
+	// Rows that set the same fields are inserted together.
+	// It returns the primary keys of the new records in the same order as rows if the database generated them,
+	// and empty strings otherwise.
+	InsertBatch(ctx context.Context, table string, rows []map[string]interface{}) ([]string, error)
+	// Upsert inserts many records with as few statements as possible. If a row conflicts with an existing record on
+	// conflictColumns, which are usually the primary key columns, the existing record is updated with the
+	// rest of the values in the row instead.
+	// MySQL detects conflicts using all the unique indexes of the table, and ignores conflictColumns.
+	// An existing record is only updated if it has the same values as the row in scopeColumns, like the tenant
+	// column, so that a row cannot replace a record of another tenant.
+	Upsert(ctx context.Context, table string, rows []map[string]interface{}, conflictColumns []string, scopeColumns []string) error
+	// BeginE is like Begin, but returns an error if the transaction could not be started.
+	BeginE(ctx context.Context) (TransactionID, error)
+	// CommitE is like Commit, but returns an error if the transaction could not be committed.
+	// The transaction is rolled back by the database in that case.
+	CommitE(ctx context.Context, txid TransactionID) error
+
 	// PutBlankContext is called early in the processing of a response to insert an empty context that the database can use if needed.
 	PutBlankContext(ctx context.Context) context.Context
 }
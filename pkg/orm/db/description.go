@@ -3,143 +3,167 @@ package db
 // DatabaseDescription generically describes a database to GoRADD. It is sent to NewModel() to create a
 // DB object that is used internally by GoRADD to access the database. DatabaseDescription should be able to be
 // inferred by reading the structure of SQL databases, or read directly from an import file.
+//
+// See ReadDescriptionFile and WriteDescriptionFile for working with description files.
 type DatabaseDescription struct {
 	// Tables are the tables in the database
-	Tables []TableDescription
+	Tables []TableDescription `json:"tables,omitempty" yaml:"tables,omitempty"`
 	// MM are the many-to-many links between tables. In SQL databases, these are actual tables,
 	// but in NoSQL, these might be array fields on either side of the relationship.
-	MM []ManyManyDescription
+	MM []ManyManyDescription `json:"manyMany,omitempty" yaml:"manyMany,omitempty"`
 }
 
 // TableDescription describes a database object to GoRADD.
 type TableDescription struct {
 	// Name is the name of the database table or collection.
 	// Schemas will be delineated with a period in the name.
-	Name string
+	Name string `json:"name" yaml:"name"`
 	// Columns is a list of ColumnDescriptions, one for each column in the table.
 	// The first columns are the primary keys. Usually there is just one primary key.
-	Columns []ColumnDescription
+	Columns []ColumnDescription `json:"columns,omitempty" yaml:"columns,omitempty"`
 	// Indexes are the indexes defined in the database. Unique indexes will result in LoadBy* functions.
-	Indexes []IndexDescription
+	Indexes []IndexDescription `json:"indexes,omitempty" yaml:"indexes,omitempty"`
 	// EnumData is the data of the enum table if this is a enum table. The data structure must match that of the columns.
-	EnumData []map[string]interface{}
+	EnumData []map[string]interface{} `json:"enumData,omitempty" yaml:"enumData,omitempty"`
+	// IsNativeEnum indicates that the enum table is not a table, but an enum type defined by the database, like a
+	// Postgres ENUM type. Its columns are an integer id, which is the position of a value in the type starting with 1,
+	// and the name, which is the label of the value. Columns of the type refer to it with a foreign key.
+	IsNativeEnum bool `json:"isNativeEnum,omitempty" yaml:"isNativeEnum,omitempty"`
 
 	// Comment is an optional comment about the table
-	Comment string
+	Comment string `json:"comment,omitempty" yaml:"comment,omitempty"`
 	// Options are key-value settings that can be used to further describe code generation
-	Options map[string]interface{}
+	Options map[string]interface{} `json:"options,omitempty" yaml:"options,omitempty"`
 	// SupportsForeignKeys indicates that the engine for the table will automatically
 	// update foreign keys per its internal constraints.
-	SupportsForeignKeys bool
+	SupportsForeignKeys bool `json:"supportsForeignKeys,omitempty" yaml:"supportsForeignKeys,omitempty"`
 }
 
 // ColumnDescription describes a field of a database object to GoRADD.
 type ColumnDescription struct {
 	// Name is the name of the column in the database. This is blank if this is a "virtual" table for sql tables like an association or virtual attribute query.
-	Name string
+	Name string `json:"name" yaml:"name"`
 	// NativeType is the type of the column as described by the database itself.
-	NativeType string
+	NativeType string `json:"nativeType,omitempty" yaml:"nativeType,omitempty"`
 	//  GoType is the goradd defined column type
-	GoType string
+	GoType string `json:"goType,omitempty" yaml:"goType,omitempty"`
 	// SubType has additional information to the type of column that can help control code generation
 	// When column type is "time.Time", the column will default to both a date and time format. You can also make it:
 	//   date (which means date only)
 	//   time (time only)
 	//   timestamp (not editable by the user)
-	SubType string
+	// When the column holds a native enum type of the database, SubType is "enum" and NativeType is the name of the type.
+	SubType string `json:"subType,omitempty" yaml:"subType,omitempty"`
 	// MaxCharLength is the maximum length of characters to allow in the column if a string type column.
 	// If the database has the ability to specify this, this will correspond to what is specified.
 	// In any case, we will generate code to prevent fields from getting bigger than this. Zero indicates there is
 	// no length checking or limiting.
-	MaxCharLength uint64
+	MaxCharLength uint64 `json:"maxCharLength,omitempty" yaml:"maxCharLength,omitempty"`
 	// DefaultValue is the default value as specified by the database. We will initialize new ORM objects
 	// with this value. It will be cast to the corresponding GO type.
-	DefaultValue interface{}
+	DefaultValue interface{} `json:"defaultValue,omitempty" yaml:"defaultValue,omitempty"`
 	// MaxValue is the maximum value allowed for numeric values. This can be used by UI objects to tell the user what the limits are.
-	MaxValue interface{}
+	MaxValue interface{} `json:"maxValue,omitempty" yaml:"maxValue,omitempty"`
 	// MinValue is the minimum value allowed for numeric values. This can be used by UI objects to tell the user what the limits are.
-	MinValue interface{}
+	MinValue interface{} `json:"minValue,omitempty" yaml:"minValue,omitempty"`
 	// IsId is true if this column represents a unique identifier generated by the database.
-	IsId bool
+	IsId bool `json:"isId,omitempty" yaml:"isId,omitempty"`
 	// IsPk is true if this is a primary key column. PK's do not necessarily need to be ID columns, and if not,
 	// we will need to do our own work to generate unique PKs.
-	IsPk bool
+	IsPk bool `json:"isPk,omitempty" yaml:"isPk,omitempty"`
 	// IsNullable is true if the column can be given a NULL value
-	IsNullable bool
+	IsNullable bool `json:"isNullable,omitempty" yaml:"isNullable,omitempty"`
 	// IsUnique indicates that the column holds a single unique value. If this column
 	// is part of a multi-column unique value, IsUnique will be false since its value
 	// could be repeated.
-	IsUnique bool
+	IsUnique bool `json:"isUnique,omitempty" yaml:"isUnique,omitempty"`
 	// ForeignKey is additional information describing a foreign key relationship
-	ForeignKey *ForeignKeyDescription
+	ForeignKey *ForeignKeyDescription `json:"foreignKey,omitempty" yaml:"foreignKey,omitempty"`
 	// Comment is the contents of the comment associated with this column
-	Comment string
+	Comment string `json:"comment,omitempty" yaml:"comment,omitempty"`
 	// Options are key-value settings that can be used to further describe code generation
-	Options map[string]interface{}
+	Options map[string]interface{} `json:"options,omitempty" yaml:"options,omitempty"`
 }
 
 // ForeignKeyDescription describes a pointer from one database column to another database column.
-// Cross database foreign keys are not supported. Foreign keys between schemas for databases
-// that support schemas ARE supported.
+// Foreign keys between schemas for databases that support schemas are supported.
+// A column can also refer to a table in another database registered with AddDatabase by setting ReferencedDatabase.
+// The database cannot enforce such a reference or join to it, so the generated code loads the referenced
+// record with a separate query, and migrations do not create a constraint for it.
 type ForeignKeyDescription struct {
 	// ReferencedTable is the name of the table on the other end of the foreign key
-	ReferencedTable string
+	ReferencedTable string `json:"referencedTable,omitempty" yaml:"referencedTable,omitempty"`
+	// ReferencedDatabase is the key of the database that holds ReferencedTable, if it is not the database of the column.
+	ReferencedDatabase string `json:"referencedDatabase,omitempty" yaml:"referencedDatabase,omitempty"`
 	// ReferencedColumn is the database column name in the linked table that matches this column. Often that is the primary key of the other table.
-	ReferencedColumn string
+	ReferencedColumn string `json:"referencedColumn,omitempty" yaml:"referencedColumn,omitempty"`
 	// UpdateAction indicates how the column will react when the referenced item's ID changes.
-	UpdateAction FKAction
+	UpdateAction FKAction `json:"updateAction,omitempty" yaml:"updateAction,omitempty"`
 	// DeleteAction indicates how the column will react when the referenced item is deleted.
-	DeleteAction FKAction
+	DeleteAction FKAction `json:"deleteAction,omitempty" yaml:"deleteAction,omitempty"`
 	// IsUnique is true if the reference is one-to-one
-	IsUnique bool
+	IsUnique bool `json:"isUnique,omitempty" yaml:"isUnique,omitempty"`
 
 	// GoName is the name we should use to refer to the related object. Leave blank to get a computed value.
-	GoName string
+	GoName string `json:"goName,omitempty" yaml:"goName,omitempty"`
 	// ReverseName is the name that the reverse reference should use to refer to the collection of objects pointing to it.
 	// Leave blank to get a "ThisAsThat" type default name. The lower-case version of this name will be used as a column name
 	// to store the values if using a NoSQL database.
-	ReverseName string
+	ReverseName string `json:"reverseName,omitempty" yaml:"reverseName,omitempty"`
+	// ConstraintName is the name of the foreign key constraint in the database, if the database names its constraints.
+	// This is used when generating migrations, and can be left blank to get a generated name.
+	ConstraintName string `json:"constraintName,omitempty" yaml:"constraintName,omitempty"`
 }
 
 // IndexDescription gives us information about how columns are indexed.
 // If a column has a unique index, it will get a corresponding "LoadBy" function in its table's model.
 // Otherwise, it will get a corresponding "LoadSliceBy" function.
 type IndexDescription struct {
+	// Name is the name of the index in the database. This is used when generating migrations,
+	// and can be left blank to get a generated name.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
 	// IsUnique indicates whether the index is unique
-	IsUnique bool
+	IsUnique bool `json:"isUnique,omitempty" yaml:"isUnique,omitempty"`
 	// ColumnNames are the columns that are part of the index
-	ColumnNames []string
+	ColumnNames []string `json:"columnNames,omitempty" yaml:"columnNames,omitempty"`
+	// IsFullText indicates the index is a full text search index, which can be used by the Match
+	// and MatchScore operations. In MySQL this is a FULLTEXT index, and in Postgres it is a GIN
+	// index on the to_tsvector of the columns.
+	IsFullText bool `json:"isFullText,omitempty" yaml:"isFullText,omitempty"`
+	// SearchConfig is the text search configuration of a Postgres full text index, like "english".
+	// It is ignored by other databases.
+	SearchConfig string `json:"searchConfig,omitempty" yaml:"searchConfig,omitempty"`
 }
 
 // ManyManyDescription describes a many-to-many relationship table that contains a two-way pointer between database objects.
 type ManyManyDescription struct {
 	// Table1 is the name of the first table that is part of the relationship. The private key of that table will be referred to.
-	Table1 string
+	Table1 string `json:"table1,omitempty" yaml:"table1,omitempty"`
 	// Column1 is the database column name. For SQL databases, this is the name of the column in the assn table. For
 	// NoSQL, this is the name of the column that will be used to store the ids of the other side. This is optional for
 	// NoSQL, as one will be created based on the table names if left blank.
-	Column1 string
+	Column1 string `json:"column1,omitempty" yaml:"column1,omitempty"`
 	// GoName1 is the singular name of the object that Table2 will use to refer to Table1 objects.
-	GoName1 string
+	GoName1 string `json:"goName1,omitempty" yaml:"goName1,omitempty"`
 	// GoPlural1 is the plural name of the object that Table2 will use to refer to Table1 objects.
-	GoPlural1 string
+	GoPlural1 string `json:"goPlural1,omitempty" yaml:"goPlural1,omitempty"`
 
 	// Table2 is the name of the second table that is part of the relationship. The private key of that table will be referred to.
-	Table2 string
+	Table2 string `json:"table2,omitempty" yaml:"table2,omitempty"`
 	// Column2 is the database column name. For SQL databases, this is the name of the column in the assn table. For
 	// NoSQL, this is the name of the column that will be used to store the ids of the other side. This is optional for
 	// NoSQL, as one will be created based on the table names if left blank.
-	Column2 string
+	Column2 string `json:"column2,omitempty" yaml:"column2,omitempty"`
 	// GoName2 is the singular name of the object that Table1 will use to refer to Table2 objects.
-	GoName2 string
+	GoName2 string `json:"goName2,omitempty" yaml:"goName2,omitempty"`
 	// GoPlural2 is the plural name of the object that Table1 will use to refer to Table2 objects.
-	GoPlural2 string
+	GoPlural2 string `json:"goPlural2,omitempty" yaml:"goPlural2,omitempty"`
 
 	// AssnTableName is the name of the intermediate association table that will be used to create the relationship. This is
 	// needed for SQL databases, but not for NoSQL, as NoSQL will create additional array columns on each side of the relationship.
-	AssnTableName string
+	AssnTableName string `json:"assnTableName,omitempty" yaml:"assnTableName,omitempty"`
 	// SupportsForeignKeys indicates that the database engine for the table will automatically take
 	// care of updating foreign key pointers when the item pointed to has an updated key or is deleted.
 	// If this is false, the code generator will need to do the updating.
-	SupportsForeignKeys bool
+	SupportsForeignKeys bool `json:"supportsForeignKeys,omitempty" yaml:"supportsForeignKeys,omitempty"`
 }
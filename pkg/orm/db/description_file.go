@@ -0,0 +1,610 @@
+package db
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Description file formats supported by ReadDescriptionFile and WriteDescriptionFile.
+const (
+	DescriptionFormatJson = "json"
+	DescriptionFormatYaml = "yaml"
+)
+
+// descriptionTimeFormats are the formats accepted for time values in a description file.
+var descriptionTimeFormats = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999",
+	"2006-01-02",
+	"15:04:05.999999",
+}
+
+// ReadDescriptionFile reads the database description in the given file and validates it.
+//
+// The format of the file is determined by its extension, which must be .json, .yaml or .yml.
+// Default values, minimum and maximum values and enum table data are converted to the Go types that
+// the live database describers produce, so that the result can be passed directly to NewModel.
+func ReadDescriptionFile(path string) (desc DatabaseDescription, err error) {
+	var format string
+	if format, err = descriptionFormat(path); err != nil {
+		return
+	}
+	var data []byte
+	if data, err = os.ReadFile(path); err != nil {
+		return
+	}
+	if desc, err = UnmarshalDescription(data, format); err != nil {
+		err = fmt.Errorf("%s: %w", path, err)
+	}
+	return
+}
+
+// WriteDescriptionFile writes the database description to the given file in the format determined
+// by its extension. Tables and many-many relationships are sorted by name so that the output is
+// stable and can be compared with previous versions.
+func WriteDescriptionFile(path string, desc DatabaseDescription) error {
+	format, err := descriptionFormat(path)
+	if err != nil {
+		return err
+	}
+	data, err := MarshalDescription(desc, format)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// UnmarshalDescription decodes data in the given format into a DatabaseDescription, and then
+// normalizes and validates it.
+func UnmarshalDescription(data []byte, format string) (desc DatabaseDescription, err error) {
+	switch format {
+	case DescriptionFormatJson:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&desc)
+	case DescriptionFormatYaml:
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(&desc)
+	default:
+		err = fmt.Errorf("unknown description format %q", format)
+	}
+	if err != nil {
+		return
+	}
+	if err = desc.normalize(); err != nil {
+		return
+	}
+	err = desc.Validate()
+	return
+}
+
+// MarshalDescription encodes the description in the given format.
+func MarshalDescription(desc DatabaseDescription, format string) (data []byte, err error) {
+	desc = desc.exportable()
+	switch format {
+	case DescriptionFormatJson:
+		data, err = json.MarshalIndent(desc, "", "  ")
+		if err == nil {
+			data = append(data, '\n')
+		}
+	case DescriptionFormatYaml:
+		var b bytes.Buffer
+		enc := yaml.NewEncoder(&b)
+		enc.SetIndent(2)
+		if err = enc.Encode(desc); err == nil {
+			err = enc.Close()
+		}
+		data = b.Bytes()
+	default:
+		err = fmt.Errorf("unknown description format %q", format)
+	}
+	return
+}
+
+func descriptionFormat(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return DescriptionFormatJson, nil
+	case ".yaml", ".yml":
+		return DescriptionFormatYaml, nil
+	default:
+		return "", fmt.Errorf("%s: description files must have a .json, .yaml or .yml extension", path)
+	}
+}
+
+// Validate checks the description for internal consistency, and returns an error describing
+// every problem found, or nil if there are none.
+func (d DatabaseDescription) Validate() error {
+	var errs []error
+	tables := make(map[string]*TableDescription, len(d.Tables))
+
+	for i := range d.Tables {
+		t := &d.Tables[i]
+		if t.Name == "" {
+			errs = append(errs, fmt.Errorf("table %d: table has no name", i))
+			continue
+		}
+		if _, ok := tables[t.Name]; ok {
+			errs = append(errs, fmt.Errorf("table %q: duplicate table name", t.Name))
+			continue
+		}
+		tables[t.Name] = t
+		errs = append(errs, t.validate()...)
+	}
+
+	for i := range d.Tables {
+		errs = append(errs, d.Tables[i].validateForeignKeys(tables)...)
+	}
+
+	assnNames := make(map[string]bool)
+	for i, mm := range d.MM {
+		if mm.AssnTableName == "" {
+			errs = append(errs, fmt.Errorf("many-many %d: assnTableName is required", i))
+		} else if assnNames[mm.AssnTableName] {
+			errs = append(errs, fmt.Errorf("many-many %q: duplicate association table name", mm.AssnTableName))
+		} else {
+			assnNames[mm.AssnTableName] = true
+			if _, ok := tables[mm.AssnTableName]; ok {
+				errs = append(errs, fmt.Errorf("many-many %q: association table is also described as a regular table", mm.AssnTableName))
+			}
+		}
+		name := mm.AssnTableName
+		if name == "" {
+			name = strconv.Itoa(i)
+		}
+		if mm.Column1 == "" || mm.Column2 == "" {
+			errs = append(errs, fmt.Errorf("many-many %q: column1 and column2 are required", name))
+		}
+		for n, tableName := range []string{mm.Table1, mm.Table2} {
+			if tableName == "" {
+				errs = append(errs, fmt.Errorf("many-many %q: table%d is required", name, n+1))
+				continue
+			}
+			t, ok := tables[tableName]
+			if !ok {
+				errs = append(errs, fmt.Errorf("many-many %q: table%d %q does not exist", name, n+1, tableName))
+				continue
+			}
+			if n == 0 && t.EnumData != nil {
+				errs = append(errs, fmt.Errorf("many-many %q: table1 %q cannot be an enum table", name, tableName))
+			}
+			if !t.hasPk() {
+				errs = append(errs, fmt.Errorf("many-many %q: table%d %q has no primary key", name, n+1, tableName))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (t *TableDescription) validate() (errs []error) {
+	if len(t.Columns) == 0 {
+		return []error{fmt.Errorf("table %q: table has no columns", t.Name)}
+	}
+	cols := make(map[string]bool, len(t.Columns))
+	pkDone := false
+	for i, c := range t.Columns {
+		if c.Name == "" {
+			errs = append(errs, fmt.Errorf("table %q: column %d: column has no name", t.Name, i))
+			continue
+		}
+		if cols[c.Name] {
+			errs = append(errs, fmt.Errorf("table %q: column %q: duplicate column name", t.Name, c.Name))
+			continue
+		}
+		cols[c.Name] = true
+		if !isDescriptionGoType(c.GoType) {
+			errs = append(errs, fmt.Errorf("table %q: column %q: unknown goType %q", t.Name, c.Name, c.GoType))
+		}
+		if c.SubType == "enum" {
+			if c.GoType != "int" || c.NativeType == "" {
+				errs = append(errs, fmt.Errorf("table %q: column %q: enum columns must be an int, and have the name of the type as their nativeType", t.Name, c.Name))
+			}
+		} else if c.SubType != "" {
+			if c.GoType != "time.Time" {
+				errs = append(errs, fmt.Errorf("table %q: column %q: subType can only be used with time.Time columns", t.Name, c.Name))
+			} else if c.SubType != "date" && c.SubType != "time" && c.SubType != "timestamp" {
+				errs = append(errs, fmt.Errorf("table %q: column %q: subType must be date, time or timestamp", t.Name, c.Name))
+			}
+		}
+		if c.IsId && !c.IsPk {
+			errs = append(errs, fmt.Errorf("table %q: column %q: isId columns must also be primary keys", t.Name, c.Name))
+		}
+		if c.IsPk {
+			if pkDone {
+				errs = append(errs, fmt.Errorf("table %q: column %q: primary key columns must come before all other columns", t.Name, c.Name))
+			}
+		} else {
+			pkDone = true
+		}
+	}
+
+	for i, idx := range t.Indexes {
+		if len(idx.ColumnNames) == 0 {
+			errs = append(errs, fmt.Errorf("table %q: index %d: index has no columns", t.Name, i))
+		}
+		for _, name := range idx.ColumnNames {
+			if !cols[name] {
+				errs = append(errs, fmt.Errorf("table %q: index %d: column %q does not exist", t.Name, i, name))
+			}
+		}
+		if idx.IsFullText && idx.IsUnique {
+			errs = append(errs, fmt.Errorf("table %q: index %d: a full text index cannot be unique", t.Name, i))
+		}
+	}
+
+	if t.EnumData != nil {
+		errs = append(errs, t.validateEnum()...)
+	} else if t.IsNativeEnum {
+		errs = append(errs, fmt.Errorf("table %q: native enum types must have enumData", t.Name))
+	}
+	return
+}
+
+func (t *TableDescription) validateEnum() (errs []error) {
+	if len(t.Columns) < 2 {
+		return []error{fmt.Errorf("table %q: enum tables must have at least two columns", t.Name)}
+	}
+	if !isIntegerGoType(t.Columns[0].GoType) {
+		errs = append(errs, fmt.Errorf("table %q: the first column of an enum table must be an integer", t.Name))
+	}
+	if t.Columns[1].GoType != "string" {
+		errs = append(errs, fmt.Errorf("table %q: the second column of an enum table must be a string", t.Name))
+	}
+	keys := make(map[int]bool, len(t.EnumData))
+	for i, row := range t.EnumData {
+		for _, c := range t.Columns {
+			if _, ok := row[c.Name]; !ok {
+				errs = append(errs, fmt.Errorf("table %q: enumData %d: missing value for column %q", t.Name, i, c.Name))
+			}
+		}
+		for k := range row {
+			if !t.hasColumn(k) {
+				errs = append(errs, fmt.Errorf("table %q: enumData %d: column %q does not exist", t.Name, i, k))
+			}
+		}
+		if key, ok := row[t.Columns[0].Name].(int); ok {
+			if keys[key] {
+				errs = append(errs, fmt.Errorf("table %q: enumData %d: duplicate key %d", t.Name, i, key))
+			}
+			keys[key] = true
+		}
+	}
+	return
+}
+
+func (t *TableDescription) validateForeignKeys(tables map[string]*TableDescription) (errs []error) {
+	for _, c := range t.Columns {
+		fk := c.ForeignKey
+		if fk == nil {
+			continue
+		}
+		if fk.ReferencedDatabase != "" {
+			// the table is in another database, which is checked when generating code
+			continue
+		}
+		ref, ok := tables[fk.ReferencedTable]
+		if !ok {
+			errs = append(errs, fmt.Errorf("table %q: column %q: referenced table %q does not exist", t.Name, c.Name, fk.ReferencedTable))
+			continue
+		}
+		if fk.ReferencedColumn != "" && !ref.hasColumn(fk.ReferencedColumn) {
+			errs = append(errs, fmt.Errorf("table %q: column %q: referenced column %q does not exist in table %q", t.Name, c.Name, fk.ReferencedColumn, fk.ReferencedTable))
+		}
+		if (fk.DeleteAction == FKActionSetNull || fk.UpdateAction == FKActionSetNull) && !c.IsNullable {
+			errs = append(errs, fmt.Errorf("table %q: column %q: a foreign key that is set to null must be nullable", t.Name, c.Name))
+		}
+	}
+	return
+}
+
+func (t *TableDescription) hasColumn(name string) bool {
+	for _, c := range t.Columns {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *TableDescription) hasPk() bool {
+	return len(t.Columns) > 0 && t.Columns[0].IsPk
+}
+
+// isIntegerGoType returns true if name is one of the integer GoTypes the describers produce.
+func isIntegerGoType(name string) bool {
+	switch name {
+	case "int", "uint", "int64", "uint64":
+		return true
+	}
+	return false
+}
+
+func isDescriptionGoType(name string) bool {
+	switch name {
+	case "[]byte", "string", "int", "uint", "int64", "uint64", "time.Time", "float32", "float64", "bool",
+		"json.RawMessage", "decimal.Decimal", "uuid.UUID", "[]string", "[]int", "[]int64":
+		return true
+	}
+	return false
+}
+
+// normalize converts values that were decoded into interface{} fields to the types the describers produce.
+func (d *DatabaseDescription) normalize() error {
+	var errs []error
+	for i := range d.Tables {
+		t := &d.Tables[i]
+		t.Options = normalizeOptions(t.Options)
+		for j := range t.Columns {
+			c := &t.Columns[j]
+			c.Options = normalizeOptions(c.Options)
+			var err error
+			if c.DefaultValue, err = descriptionValue(c.DefaultValue, c.GoType); err != nil {
+				errs = append(errs, fmt.Errorf("table %q: column %q: defaultValue: %w", t.Name, c.Name, err))
+			}
+			if c.MinValue, err = descriptionLimit(c.MinValue, c.GoType); err != nil {
+				errs = append(errs, fmt.Errorf("table %q: column %q: minValue: %w", t.Name, c.Name, err))
+			}
+			if c.MaxValue, err = descriptionLimit(c.MaxValue, c.GoType); err != nil {
+				errs = append(errs, fmt.Errorf("table %q: column %q: maxValue: %w", t.Name, c.Name, err))
+			}
+		}
+		for j, row := range t.EnumData {
+			for k, c := range t.Columns {
+				v, ok := row[c.Name]
+				if !ok {
+					continue
+				}
+				goType := c.GoType
+				if k == 0 {
+					goType = "int" // keys of enum tables are always ints
+				}
+				var err error
+				if row[c.Name], err = descriptionValue(v, goType); err != nil {
+					errs = append(errs, fmt.Errorf("table %q: enumData %d: column %q: %w", t.Name, j, c.Name, err))
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// normalizeOptions converts all numbers in the options to float64, which is what the describers
+// produce when they decode options from json in a comment.
+func normalizeOptions(o map[string]interface{}) map[string]interface{} {
+	for k, v := range o {
+		o[k] = normalizeOption(v)
+	}
+	return o
+}
+
+func normalizeOption(v interface{}) interface{} {
+	switch v2 := v.(type) {
+	case json.Number:
+		f, _ := v2.Float64()
+		return f
+	case int:
+		return float64(v2)
+	case map[string]interface{}:
+		return normalizeOptions(v2)
+	case []interface{}:
+		for i := range v2 {
+			v2[i] = normalizeOption(v2[i])
+		}
+	}
+	return v
+}
+
+// descriptionValue converts a decoded value to the type used for values of the given GoType.
+func descriptionValue(v interface{}, goType string) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	switch goType {
+	case "string":
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+	case "[]byte":
+		switch v2 := v.(type) {
+		case string:
+			return []byte(v2), nil
+		case []byte:
+			return v2, nil
+		}
+	case "bool":
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+	case "time.Time":
+		switch v2 := v.(type) {
+		case time.Time:
+			return v2, nil
+		case string:
+			if v2 == "now" {
+				return v2, nil
+			}
+			for _, layout := range descriptionTimeFormats {
+				if t, err := time.Parse(layout, v2); err == nil {
+					return t, nil
+				}
+			}
+			return nil, fmt.Errorf("%q is not a valid time. Use RFC 3339 format or \"now\"", v2)
+		}
+	case "int":
+		i, err := descriptionInt(v, math.MinInt, math.MaxInt)
+		return int(i), err
+	case "int64":
+		return descriptionInt(v, math.MinInt64, math.MaxInt64)
+	case "uint":
+		u, err := descriptionUint(v, math.MaxUint)
+		return uint(u), err
+	case "uint64":
+		return descriptionUint(v, math.MaxUint64)
+	case "float32":
+		f, err := descriptionFloat(v)
+		return float32(f), err
+	case "float64":
+		return descriptionFloat(v)
+	default:
+		return v, nil // unknown types are reported by Validate
+	}
+	return nil, fmt.Errorf("%v is not a valid %s value", v, goType)
+}
+
+// descriptionLimit converts a decoded minimum or maximum value to the type used for limits of the given GoType.
+func descriptionLimit(v interface{}, goType string) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	switch goType {
+	case "int", "int64":
+		return descriptionInt(v, math.MinInt64, math.MaxInt64)
+	case "uint", "uint64":
+		return descriptionUint(v, math.MaxUint64)
+	case "float32", "float64":
+		return descriptionFloat(v)
+	default:
+		return nil, fmt.Errorf("minimum and maximum values can only be used on numeric columns")
+	}
+}
+
+func descriptionInt(v interface{}, min int64, max int64) (i int64, err error) {
+	switch v2 := v.(type) {
+	case json.Number:
+		i, err = strconv.ParseInt(string(v2), 10, 64)
+	case int:
+		i = int64(v2)
+	case int64:
+		i = v2
+	case uint64:
+		if v2 > math.MaxInt64 {
+			err = fmt.Errorf("%d is out of range", v2)
+		}
+		i = int64(v2)
+	case float64:
+		if v2 != math.Trunc(v2) {
+			err = fmt.Errorf("%v is not an integer", v2)
+		}
+		i = int64(v2)
+	default:
+		err = fmt.Errorf("%v is not an integer", v)
+	}
+	if err == nil && (i < min || i > max) {
+		err = fmt.Errorf("%d is out of range", i)
+	}
+	return
+}
+
+func descriptionUint(v interface{}, max uint64) (u uint64, err error) {
+	switch v2 := v.(type) {
+	case json.Number:
+		u, err = strconv.ParseUint(string(v2), 10, 64)
+	case int:
+		if v2 < 0 {
+			err = fmt.Errorf("%d is negative", v2)
+		}
+		u = uint64(v2)
+	case int64:
+		if v2 < 0 {
+			err = fmt.Errorf("%d is negative", v2)
+		}
+		u = uint64(v2)
+	case uint64:
+		u = v2
+	case float64:
+		if v2 < 0 || v2 != math.Trunc(v2) {
+			err = fmt.Errorf("%v is not an unsigned integer", v2)
+		}
+		u = uint64(v2)
+	default:
+		err = fmt.Errorf("%v is not an unsigned integer", v)
+	}
+	if err == nil && u > max {
+		err = fmt.Errorf("%d is out of range", u)
+	}
+	return
+}
+
+func descriptionFloat(v interface{}) (f float64, err error) {
+	switch v2 := v.(type) {
+	case json.Number:
+		f, err = v2.Float64()
+	case int:
+		f = float64(v2)
+	case int64:
+		f = float64(v2)
+	case uint64:
+		f = float64(v2)
+	case float64:
+		f = v2
+	default:
+		err = fmt.Errorf("%v is not a number", v)
+	}
+	return
+}
+
+// exportable returns a copy of the description that is sorted and whose values will encode as
+// readable text in both json and yaml.
+func (d DatabaseDescription) exportable() DatabaseDescription {
+	var out DatabaseDescription
+	out.Tables = make([]TableDescription, len(d.Tables))
+	copy(out.Tables, d.Tables)
+	sort.SliceStable(out.Tables, func(i, j int) bool {
+		return out.Tables[i].Name < out.Tables[j].Name
+	})
+	for i := range out.Tables {
+		t := &out.Tables[i]
+		t.Columns = append([]ColumnDescription(nil), t.Columns...)
+		for j := range t.Columns {
+			c := &t.Columns[j]
+			c.DefaultValue = exportableValue(c.DefaultValue, c.SubType)
+		}
+		if t.EnumData != nil {
+			rows := make([]map[string]interface{}, len(t.EnumData))
+			for j, row := range t.EnumData {
+				rows[j] = make(map[string]interface{}, len(row))
+				for k, v := range row {
+					rows[j][k] = exportableValue(v, "")
+				}
+			}
+			t.EnumData = rows
+		}
+	}
+	out.MM = make([]ManyManyDescription, len(d.MM))
+	copy(out.MM, d.MM)
+	sort.SliceStable(out.MM, func(i, j int) bool {
+		return out.MM[i].AssnTableName < out.MM[j].AssnTableName
+	})
+	return out
+}
+
+func exportableValue(v interface{}, subType string) interface{} {
+	switch v2 := v.(type) {
+	case []byte:
+		return string(v2)
+	case time.Time:
+		switch subType {
+		case "date":
+			return v2.Format("2006-01-02")
+		case "time":
+			return v2.Format("15:04:05.999999")
+		default:
+			return v2.Format(time.RFC3339Nano)
+		}
+	}
+	return v
+}
@@ -0,0 +1,138 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testDescription() DatabaseDescription {
+	return DatabaseDescription{
+		Tables: []TableDescription{
+			{
+				Name: "person",
+				Columns: []ColumnDescription{
+					{Name: "id", GoType: "string", IsId: true, IsPk: true},
+					{Name: "name", GoType: "string", MaxCharLength: 50},
+					{Name: "age", GoType: "int", DefaultValue: 21, MinValue: int64(0), MaxValue: int64(150)},
+					{Name: "created", GoType: "time.Time", DefaultValue: "now"},
+					{Name: "born", GoType: "time.Time", SubType: "date", IsNullable: true,
+						DefaultValue: time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC)},
+					{Name: "photo", GoType: "[]byte", DefaultValue: []byte("abc")},
+					{Name: "type_id", GoType: "int", IsNullable: true,
+						ForeignKey: &ForeignKeyDescription{ReferencedTable: "person_type", ReferencedColumn: "id", DeleteAction: FKActionSetNull}},
+				},
+				Indexes: []IndexDescription{{IsUnique: true, ColumnNames: []string{"name"}}},
+				Options: map[string]interface{}{"goName": "Person", "min": float64(1)},
+			},
+			{
+				Name: "person_type",
+				Columns: []ColumnDescription{
+					{Name: "id", GoType: "int", IsPk: true},
+					{Name: "name", GoType: "string"},
+					{Name: "weight", GoType: "float64"},
+				},
+				EnumData: []map[string]interface{}{
+					{"id": 1, "name": "Manager", "weight": 1.5},
+					{"id": 2, "name": "Worker", "weight": float64(2)},
+				},
+			},
+		},
+		MM: []ManyManyDescription{
+			{Table1: "person", Column1: "person_id", Table2: "person", Column2: "friend_id", AssnTableName: "friend_assn"},
+		},
+	}
+}
+
+func TestDescriptionFileRoundTrip(t *testing.T) {
+	desc := testDescription()
+	require.NoError(t, desc.Validate())
+
+	for _, ext := range []string{".json", ".yaml"} {
+		t.Run(ext, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "schema"+ext)
+			require.NoError(t, WriteDescriptionFile(path, desc))
+			desc2, err := ReadDescriptionFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, desc, desc2)
+		})
+	}
+}
+
+func TestDescriptionFileUnsignedEnum(t *testing.T) {
+	// MySQL describes an "int unsigned" enum key as a uint
+	desc := DatabaseDescription{
+		Tables: []TableDescription{
+			{
+				Name: "status",
+				Columns: []ColumnDescription{
+					{Name: "id", GoType: "uint", IsPk: true, MinValue: uint64(0), MaxValue: uint64(4294967295)},
+					{Name: "name", GoType: "string"},
+				},
+				EnumData: []map[string]interface{}{
+					{"id": 1, "name": "Open"},
+					{"id": 2, "name": "Closed"},
+				},
+			},
+		},
+	}
+	require.NoError(t, desc.Validate())
+
+	data, err := MarshalDescription(desc, DescriptionFormatYaml)
+	require.NoError(t, err)
+	desc2, err := UnmarshalDescription(data, DescriptionFormatYaml)
+	require.NoError(t, err)
+	assert.Equal(t, desc, desc2)
+}
+
+func TestDescriptionValidate(t *testing.T) {
+	data := `
+tables:
+  - name: person
+    columns:
+      - name: name
+        goType: string
+      - name: id
+        goType: int
+        isPk: true
+      - name: type_id
+        goType: int
+        foreignKey:
+          referencedTable: missing
+      - name: age
+        goType: integer
+        subType: date
+    indexes:
+      - columnNames: [nope]
+  - name: person_type
+    columns:
+      - name: id
+        goType: string
+      - name: name
+        goType: string
+    enumData:
+      - id: 1
+        name: A
+`
+	_, err := UnmarshalDescription([]byte(data), DescriptionFormatYaml)
+	require.Error(t, err)
+	msg := err.Error()
+	assert.Contains(t, msg, `table "person": column "id": primary key columns must come before all other columns`)
+	assert.Contains(t, msg, `table "person": column "type_id": referenced table "missing" does not exist`)
+	assert.Contains(t, msg, `table "person": column "age": unknown goType "integer"`)
+	assert.Contains(t, msg, `table "person": column "age": subType can only be used with time.Time columns`)
+	assert.Contains(t, msg, `table "person": index 0: column "nope" does not exist`)
+	assert.Contains(t, msg, `table "person_type": the first column of an enum table must be an integer`)
+
+	_, err = UnmarshalDescription([]byte(`{"tables": [{"name": "a", "columns": [{"name": "b", "goType": "int", "defaultValue": "x"}]}]}`), DescriptionFormatJson)
+	assert.ErrorContains(t, err, `table "a": column "b": defaultValue: x is not an integer`)
+
+	_, err = UnmarshalDescription([]byte(`{"tables": [], "extra": 1}`), DescriptionFormatJson)
+	assert.Error(t, err)
+
+	_, err = UnmarshalDescription([]byte(`tables: [{name: a, columns: [{name: b, goType: int, foreignKey: {referencedTable: a, deleteAction: Explode}}]}]`), DescriptionFormatYaml)
+	assert.ErrorContains(t, err, `unknown foreign key action "Explode"`)
+}
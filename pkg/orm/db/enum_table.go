@@ -33,6 +33,8 @@ type EnumTable struct {
 	Values []map[string]interface{}
 	// PkField is the name of the private key field
 	PkField string
+	// IsNativeEnum is true if the values are defined by an enum type in the database rather than a table.
+	IsNativeEnum bool
 
 	// Filled in by analyzer
 	Constants map[int]string
@@ -0,0 +1,79 @@
+package db
+
+import "fmt"
+
+// UniqueViolationError is returned by the error returning database functions when a write
+// would put a duplicate value in a unique index or primary key.
+//
+// Use errors.As to detect it:
+//
+//	var e db.UniqueViolationError
+//	if errors.As(err, &e) {
+//		// respond with http.StatusConflict
+//	}
+type UniqueViolationError struct {
+	// Table is the table being written to.
+	Table string
+	// Err is the original error reported by the database driver.
+	Err error
+}
+
+func (e UniqueViolationError) Error() string {
+	return fmt.Sprintf("unique constraint violation in table %s: %v", e.Table, e.Err)
+}
+
+func (e UniqueViolationError) Unwrap() error {
+	return e.Err
+}
+
+// ForeignKeyViolationError is returned when a write or delete would break a foreign key relationship,
+// either because the referenced record does not exist, or because a record being deleted is still referenced.
+type ForeignKeyViolationError struct {
+	// Table is the table being written to.
+	Table string
+	// Err is the original error reported by the database driver, or a description of the restricted
+	// relationship if the ORM detected the violation.
+	Err error
+}
+
+func (e ForeignKeyViolationError) Error() string {
+	return fmt.Sprintf("foreign key violation in table %s: %v", e.Table, e.Err)
+}
+
+func (e ForeignKeyViolationError) Unwrap() error {
+	return e.Err
+}
+
+// OptimisticLockError is returned when an update of a record with an optimistic lock column fails because
+// the record was changed or deleted by someone else since it was read.
+type OptimisticLockError struct {
+	// Table is the table being written to.
+	Table string
+	// Pk is the primary key of the record that was being updated.
+	Pk interface{}
+}
+
+func (e OptimisticLockError) Error() string {
+	return fmt.Sprintf("record %v in table %s was changed or deleted by another process", e.Pk, e.Table)
+}
+
+// DeadlockError is returned when the database aborts a statement or transaction because it could not
+// get the locks it needed, for example because of a deadlock, a serialization failure or a locked database.
+// Retrying the whole transaction will usually succeed.
+type DeadlockError struct {
+	// Table is the table being written to, if known.
+	Table string
+	// Err is the original error reported by the database driver.
+	Err error
+}
+
+func (e DeadlockError) Error() string {
+	if e.Table == "" {
+		return fmt.Sprintf("deadlock: %v", e.Err)
+	}
+	return fmt.Sprintf("deadlock in table %s: %v", e.Table, e.Err)
+}
+
+func (e DeadlockError) Unwrap() error {
+	return e.Err
+}
@@ -1,7 +1,13 @@
 package db
 
+import (
+	"fmt"
+	"github.com/goradd/goradd/pkg/stringmap"
+	"strings"
+)
+
 // ForeignKeyInfo is additional information to describe what a foreign key points to.
-// Cross database foreign keys are not supported.
+// References to tables in other databases are described by ExternalReference instead.
 type ForeignKeyInfo struct {
 	// ReferencedTable is the name of the table on the other end of the foreign key
 	ReferencedTable string
@@ -28,6 +34,83 @@ type ForeignKeyInfo struct {
 	RR *ReverseReference
 }
 
+// ExternalReference describes a column that refers to a record in a table of another database.
+// Since the databases cannot join the tables, the generated code loads the record with a separate query
+// to the other database.
+type ExternalReference struct {
+	// DbKey is the key of the database that holds the referenced table.
+	DbKey string
+	// ReferencedTable is the name of the table on the other end of the reference.
+	ReferencedTable string
+	// ReferencedColumn is the database column name in the referenced table that matches this column.
+	// This must be the primary key of the table.
+	ReferencedColumn string
+	// GoName is the name we should use to refer to the related object
+	GoName string
+}
+
+// Table returns the referenced table. The referenced database must be registered, and have a model.
+func (r *ExternalReference) Table() *Table {
+	d := GetDatabase(r.DbKey)
+	if d == nil || d.Model() == nil {
+		panic(fmt.Sprintf("database %s is not registered", r.DbKey))
+	}
+	t := d.Model().Table(r.ReferencedTable)
+	if t == nil {
+		panic(fmt.Sprintf("table %s was not found in database %s", r.ReferencedTable, r.DbKey))
+	}
+	return t
+}
+
+// CheckExternalReferences returns an error if the models refer to each other in a cycle through their external
+// references, like a users database that refers to a business database that refers back to the users database.
+// The generated model packages of the databases would import each other, which Go does not allow.
+func CheckExternalReferences(models []*Model) error {
+	refs := make(map[string][]string)
+	for _, m := range models {
+		found := make(map[string]bool)
+		for _, k := range stringmap.SortedKeys(m.Tables) {
+			for _, col := range m.Tables[k].Columns {
+				if r := col.ExternalReference; r != nil && !found[r.DbKey] {
+					found[r.DbKey] = true
+					refs[m.DbKey] = append(refs[m.DbKey], r.DbKey)
+				}
+			}
+		}
+	}
+
+	// depth first search, with the path of the search kept in path
+	done := make(map[string]bool)
+	var path []string
+	var visit func(dbKey string) error
+	visit = func(dbKey string) error {
+		for i, k := range path {
+			if k == dbKey {
+				return fmt.Errorf("the databases %s refer to each other, so their generated model packages would import each other. Remove the referencedDatabase of a foreign key in one of them",
+					strings.Join(append(path[i:], dbKey), " -> "))
+			}
+		}
+		if done[dbKey] {
+			return nil
+		}
+		path = append(path, dbKey)
+		for _, k := range refs[dbKey] {
+			if err := visit(k); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		done[dbKey] = true
+		return nil
+	}
+	for _, m := range models {
+		if err := visit(m.DbKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 /*
 // ForeignKeyDescription describes a foreign key relationship between columns in one table and columns in a different table.
 // We currently allow the collection of multi-table and cross-database fk data, but we don't currently support them in codegen.
@@ -95,3 +178,26 @@ func FKActionFromString(s string) FKAction {
 		return FKActionNone
 	}
 }
+
+// MarshalText satisfies the encoding.TextMarshaler interface so that actions are written as strings
+// to description files.
+func (a FKAction) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText satisfies the encoding.TextUnmarshaler interface so that actions can be read from
+// description files. Case is ignored.
+func (a *FKAction) UnmarshalText(text []byte) error {
+	s := string(text)
+	for _, action := range []FKAction{FKActionNone, FKActionSetNull, FKActionSetDefault, FKActionCascade, FKActionRestrict} {
+		if strings.EqualFold(s, action.String()) {
+			*a = action
+			return nil
+		}
+	}
+	if strings.EqualFold(s, "None") {
+		*a = FKActionNone
+		return nil
+	}
+	return fmt.Errorf(`unknown foreign key action "%s". Valid values are "Null", "Default", "Cascade", "Restrict" or "None"`, s)
+}
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExternalReference(t *testing.T) {
+	desc := DatabaseDescription{
+		Tables: []TableDescription{
+			{
+				Name: "project",
+				Columns: []ColumnDescription{
+					{Name: "id", GoType: "string", IsId: true, IsPk: true},
+					{Name: "manager_id", GoType: "int", IsNullable: true,
+						ForeignKey: &ForeignKeyDescription{ReferencedDatabase: "users", ReferencedTable: "person", ReferencedColumn: "id"}},
+				},
+			},
+		},
+	}
+	m := NewModel("business", "", "_id", "_enum", true, desc)
+	c := m.Table("project").GetColumn("manager_id")
+	assert.Nil(t, c.ForeignKey)
+	assert.False(t, c.IsReference())
+	assert.Equal(t, &ExternalReference{DbKey: "users", ReferencedTable: "person", ReferencedColumn: "id", GoName: "Manager"},
+		c.ExternalReference)
+	assert.Panics(t, func() { c.ExternalReference.Table() }, "the users database is not registered")
+}
+
+func TestExternalReferenceName(t *testing.T) {
+	desc := DatabaseDescription{
+		Tables: []TableDescription{
+			{
+				Name: "project",
+				Columns: []ColumnDescription{
+					{Name: "id", GoType: "string", IsId: true, IsPk: true},
+					{Name: "manager", GoType: "int", IsNullable: true,
+						ForeignKey: &ForeignKeyDescription{ReferencedDatabase: "users", ReferencedTable: "person", ReferencedColumn: "id"}},
+				},
+			},
+		},
+	}
+	m := NewModel("business", "", "_id", "_enum", true, desc)
+	c := m.Table("project").GetColumn("manager")
+	assert.Equal(t, "Manager", c.ExternalReference.GoName)
+	assert.Equal(t, "ManagerID", c.GoName, "the column is renamed so its getter does not collide with the object's")
+}
+
+func TestCheckExternalReferences(t *testing.T) {
+	ref := func(dbKey string, refKey string) *Model {
+		return NewModel(dbKey, "", "_id", "_enum", true, DatabaseDescription{
+			Tables: []TableDescription{
+				{
+					Name: "project",
+					Columns: []ColumnDescription{
+						{Name: "id", GoType: "string", IsId: true, IsPk: true},
+						{Name: "person_id", GoType: "int", IsNullable: true,
+							ForeignKey: &ForeignKeyDescription{ReferencedDatabase: refKey, ReferencedTable: "person", ReferencedColumn: "id"}},
+					},
+				},
+			},
+		})
+	}
+	assert.NoError(t, CheckExternalReferences([]*Model{ref("a", "b"), ref("b", "c")}))
+	assert.EqualError(t, CheckExternalReferences([]*Model{ref("a", "b"), ref("b", "c"), ref("c", "a")}),
+		"the databases a -> b -> c -> a refer to each other, so their generated model packages would import each other. Remove the referencedDatabase of a foreign key in one of them")
+}
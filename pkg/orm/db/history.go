@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// HistoryAction is the kind of change recorded in a history table.
+type HistoryAction string
+
+const (
+	HistoryInsert HistoryAction = "insert"
+	HistoryUpdate HistoryAction = "update"
+	HistoryDelete HistoryAction = "delete"
+)
+
+// The names of the columns of history tables
+const (
+	HistoryRecordIDColumn  = "record_id"
+	HistoryActionColumn    = "action"
+	HistoryChangesColumn   = "changes"
+	HistoryChangedByColumn = "changed_by"
+	HistoryChangedAtColumn = "changed_at"
+)
+
+// HistoryTableName returns the name of the table that records the history of changes to the given table.
+func HistoryTableName(table string) string {
+	return table + "_history"
+}
+
+// WithHistoryTables returns the description with a history table added for each table that has
+// the history option, unless the description already has one.
+func WithHistoryTables(desc DatabaseDescription) DatabaseDescription {
+	names := make(map[string]bool, len(desc.Tables))
+	for _, t := range desc.Tables {
+		names[t.Name] = true
+	}
+	var added []TableDescription
+	for _, t := range desc.Tables {
+		if h, _ := t.Options[HistoryOption].(bool); h && !names[HistoryTableName(t.Name)] {
+			added = append(added, historyTableDescription(t.Name))
+		}
+	}
+	if added != nil {
+		desc.Tables = append(append([]TableDescription(nil), desc.Tables...), added...)
+	}
+	return desc
+}
+
+// historyTableDescription returns the description of the history table of the named table.
+// The record_id is the primary key of the changed record as a string, so that one structure works with
+// all primary keys. It has no foreign key, so that the history of a record outlives the record.
+func historyTableDescription(table string) TableDescription {
+	return TableDescription{
+		Name:    HistoryTableName(table),
+		Comment: "The history of changes to " + table,
+		Columns: []ColumnDescription{
+			{Name: "id", GoType: "string", IsId: true, IsPk: true},
+			{Name: HistoryRecordIDColumn, GoType: "string", MaxCharLength: 100},
+			{Name: HistoryActionColumn, GoType: "string", MaxCharLength: 10},
+			{Name: HistoryChangesColumn, GoType: "json.RawMessage", IsNullable: true},
+			{Name: HistoryChangedByColumn, GoType: "string", MaxCharLength: 100, IsNullable: true},
+			{Name: HistoryChangedAtColumn, GoType: "time.Time", SubType: "timestamp"},
+		},
+		Indexes: []IndexDescription{{ColumnNames: []string{HistoryRecordIDColumn}}},
+	}
+}
+
+// HistoryFields returns the fields of the history record of a change to the record with primary key pk.
+// fields are the values that were changed, keyed by column name. The user comes from the context,
+// and is set with WithAuditUser.
+func HistoryFields(ctx context.Context, pk interface{}, action HistoryAction, fields map[string]interface{}) (map[string]interface{}, error) {
+	changes, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	h := map[string]interface{}{
+		HistoryRecordIDColumn:  fmt.Sprint(pk),
+		HistoryActionColumn:    string(action),
+		HistoryChangesColumn:   json.RawMessage(changes),
+		HistoryChangedByColumn: nil,
+		HistoryChangedAtColumn: time.Now().UTC(),
+	}
+	if u := AuditUser(ctx); u != nil {
+		h[HistoryChangedByColumn] = fmt.Sprint(u)
+	}
+	return h, nil
+}
@@ -0,0 +1,43 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithHistoryTables(t *testing.T) {
+	desc := DatabaseDescription{
+		Tables: []TableDescription{
+			{
+				Name:    "project",
+				Options: map[string]interface{}{HistoryOption: true},
+				Columns: []ColumnDescription{{Name: "id", GoType: "string", IsId: true, IsPk: true}},
+			},
+			{
+				Name:    "person",
+				Columns: []ColumnDescription{{Name: "id", GoType: "string", IsId: true, IsPk: true}},
+			},
+		},
+	}
+	desc2 := WithHistoryTables(desc)
+	assert.Len(t, desc.Tables, 2)
+	assert.Len(t, desc2.Tables, 3)
+	assert.Equal(t, "project_history", desc2.Tables[2].Name)
+	assert.Len(t, WithHistoryTables(desc2).Tables, 3, "an existing history table is not added again")
+
+	m := NewModel("test", "", "_id", "_enum", true, desc)
+	h := m.Table("project").HistoryTable
+	assert.Equal(t, "ProjectHistory", h.GoName)
+	assert.Nil(t, m.Table("person").HistoryTable)
+
+	ctx := WithAuditUser(context.Background(), 5)
+	fields, err := HistoryFields(ctx, "12", HistoryUpdate, map[string]interface{}{"name": "a"})
+	assert.NoError(t, err)
+	assert.Equal(t, "12", fields[HistoryRecordIDColumn])
+	assert.Equal(t, "update", fields[HistoryActionColumn])
+	assert.Equal(t, json.RawMessage(`{"name":"a"}`), fields[HistoryChangesColumn])
+	assert.Equal(t, "5", fields[HistoryChangedByColumn])
+}
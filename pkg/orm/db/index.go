@@ -7,4 +7,8 @@ type Index struct {
 	IsUnique bool
 	// Columns are the columns that are part of the index
 	Columns []*Column
+	// IsFullText indicates the index is a full text search index
+	IsFullText bool
+	// SearchConfig is the text search configuration of a Postgres full text index
+	SearchConfig string
 }
@@ -2,6 +2,7 @@ package db
 
 import (
 	"fmt"
+	"github.com/shopspring/decimal"
 	"strconv"
 )
 
@@ -80,3 +81,16 @@ func parseVal[C numberIn](v C, s string) (C, error) {
 		return v, fmt.Errorf("invalid default value")
 	}
 }
+
+// getDecimalOption returns the value of a min or max option of a decimal column.
+// Give the value as a string to avoid losing precision.
+func getDecimalOption(opt interface{}) (interface{}, error) {
+	switch v := opt.(type) {
+	case float64: // returned by json conversion of number
+		return decimal.NewFromFloat(v), nil
+	case string:
+		return decimal.NewFromString(v)
+	default:
+		return nil, fmt.Errorf("value type must be either numeric or string")
+	}
+}
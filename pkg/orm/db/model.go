@@ -21,8 +21,30 @@ const (
 	GoPluralOption      = "goPlural"      // Used in tables and columns
 	MinOption           = "min"           // Used in numeric columns
 	MaxOption           = "max"           // Used in number columns
+	// OptimisticLockOption marks an integer column as a version number that is incremented on each save, and that must
+	// not have changed since the record was read. Used in columns only.
+	OptimisticLockOption = "optimisticLock"
+	// JsonTypeOption names a Go type that the data in a JSON column can be unmarshalled into, as an import path
+	// followed by a dot and the type name, like "github.com/me/project/model/types.Address". Used in columns only.
+	JsonTypeOption = "jsonType"
+	// SoftDeleteOption names the nullable time column that marks the records of a table as deleted. A column named
+	// deleted_at is used without the option, and setting the option to false turns soft deletes off. Used in tables only.
+	SoftDeleteOption = "softDelete"
+	// AuditOption marks a column as one that is filled in automatically when a record is saved. The value is one of
+	// createdAt, updatedAt, createdBy or updatedBy. Columns with those names in snake case are audit columns without
+	// the option, and setting the option to false turns this off. Used in columns only.
+	AuditOption = "audit"
+	// HistoryOption set to true gives a table a history table that records each change to its records,
+	// along with who made the change and when. Used in tables only.
+	HistoryOption = "history"
+	// TenantOption names the column of a table that holds the tenant that each record belongs to. Queries
+	// of the table only find the records of the tenant in the context. See WithTenant. Used in tables only.
+	TenantOption = "tenant"
 )
 
+// defaultSoftDeleteColumn is the name of the column that is used for soft deletes when a table has no softDelete option.
+const defaultSoftDeleteColumn = "deleted_at"
+
 // Model is the top level struct that contains a description of the database modeled as objects.
 // It is used in code generation and query creation.
 type Model struct {
@@ -72,7 +94,7 @@ func NewModel(dbKey string,
 		EnumTableSuffix:  enumTableSuffix,
 		ignoreSchemas:    ignoreSchemas,
 	}
-	d.importDescription(desc)
+	d.importDescription(WithHistoryTables(desc))
 	return &d
 }
 
@@ -117,6 +139,14 @@ func (m *Model) importDescription(desc DatabaseDescription) {
 	for _, assn := range desc.MM {
 		m.importAssociation(assn)
 	}
+
+	for _, table := range desc.Tables {
+		if h, _ := table.Options[HistoryOption].(bool); h {
+			if t := m.Table(table.Name); t != nil {
+				t.HistoryTable = m.Table(HistoryTableName(table.Name))
+			}
+		}
+	}
 }
 
 // importEnumTable will import the enum table provided by the database description
@@ -131,6 +161,7 @@ func (m *Model) importEnumTable(desc TableDescription) *EnumTable {
 		LiteralPlural: m.dbNameToEnglishPlural(typeName),
 		GoName:        m.dbNameToGoName(typeName),
 		GoPlural:      m.dbNameToGoPlural(typeName),
+		IsNativeEnum:  desc.IsNativeEnum,
 	}
 
 	var ok bool
@@ -268,11 +299,61 @@ func (m *Model) importTable(desc TableDescription) *Table {
 			}
 			columns = append(columns, col)
 		}
-		t.Indexes = append(t.Indexes, Index{IsUnique: idx.IsUnique, Columns: columns})
+		t.Indexes = append(t.Indexes, Index{
+			IsUnique:     idx.IsUnique,
+			Columns:      columns,
+			IsFullText:   idx.IsFullText,
+			SearchConfig: idx.SearchConfig,
+		})
+	}
+
+	m.importSoftDelete(t, desc)
+
+	if opt := desc.Options[TenantOption]; opt != nil {
+		if name, ok2 := opt.(string); !ok2 {
+			log.Warning("Error in option for table " + desc.Name + ": tenant is not a column name")
+		} else if c := t.GetColumn(name); c == nil || c.IsPk {
+			log.Warning("Error in option for table " + desc.Name + ": tenant column " + name + " does not exist or is the primary key")
+		} else {
+			c.IsTenant = true
+		}
 	}
 	return t
 }
 
+// importSoftDelete marks the column of the table that is used for soft deletes, if it has one.
+func (m *Model) importSoftDelete(t *Table, desc TableDescription) {
+	name := defaultSoftDeleteColumn
+	var explicit bool
+	switch v := desc.Options[SoftDeleteOption].(type) {
+	case nil:
+	case string:
+		name = v
+		explicit = true
+	case bool:
+		if !v {
+			return
+		}
+		explicit = true
+	default:
+		log.Warning("Error in option for table " + desc.Name + ": softDelete is not a column name or a boolean")
+		return
+	}
+
+	c := t.GetColumn(name)
+	if c == nil {
+		if explicit {
+			log.Warning("Error in option for table " + desc.Name + ": softDelete column " + name + " does not exist")
+		}
+	} else if c.ColumnType != ColTypeTime || !c.IsNullable {
+		if explicit {
+			log.Warning("Error in option for table " + desc.Name + ": softDelete column " + name + " must be a nullable time column")
+		}
+	} else {
+		c.IsSoftDelete = true
+	}
+}
+
 func (m *Model) importReverseReferences(td *Table) {
 	var td2 *Table
 
@@ -454,7 +535,14 @@ func (m *Model) importColumn(desc ColumnDescription) *Column {
 		Options:       desc.Options,
 	}
 
-	if c.IsId {
+	if c.IsPk && c.ColumnType == ColTypeUUID {
+		// The application generates the key, even if the database has a default that would generate one,
+		// so that the key is known before the record is saved and stays a uuid.UUID.
+		c.IsId = false
+		if c.DefaultValue == nil || desc.IsId {
+			c.DefaultValue = newUUID
+		}
+	} else if c.IsId {
 		c.ColumnType = ColTypeString // We treat auto-generated ids as strings for cross database compatibility.
 	}
 
@@ -468,15 +556,78 @@ func (m *Model) importColumn(desc ColumnDescription) *Column {
 	c.modelName = LowerCaseIdentifier(c.DbName)
 
 	var err error
-	if opt := desc.Options[MinOption]; opt != nil {
-		if c.MinValue, err = getMinOption(c.MinValue, opt); err != nil {
-			log.Warningf("Error in 'min' option for column %s: %s", desc.Name, err.Error())
+	if c.ColumnType == ColTypeDecimal {
+		// Decimal columns have no limits of their own, so the options are used as given
+		if opt := desc.Options[MinOption]; opt != nil {
+			if c.MinValue, err = getDecimalOption(opt); err != nil {
+				log.Warningf("Error in 'min' option for column %s: %s", desc.Name, err.Error())
+			}
+		}
+		if opt := desc.Options[MaxOption]; opt != nil {
+			if c.MaxValue, err = getDecimalOption(opt); err != nil {
+				log.Warningf("Error in 'max' option for column %s: %s", desc.Name, err.Error())
+			}
+		}
+	} else {
+		if opt := desc.Options[MinOption]; opt != nil {
+			if c.MinValue, err = getMinOption(c.MinValue, opt); err != nil {
+				log.Warningf("Error in 'min' option for column %s: %s", desc.Name, err.Error())
+			}
+		}
+		if opt := desc.Options[MaxOption]; opt != nil {
+			if c.MaxValue, err = getMaxOption(c.MaxValue, opt); err != nil {
+				log.Warningf("Error in 'max' option for column %s: %s", desc.Name, err.Error())
+			}
+		}
+	}
+
+	if opt := desc.Options[OptimisticLockOption]; opt != nil {
+		if c.IsOptimisticLock, ok = opt.(bool); !ok {
+			log.Warningf("Error in option for column " + desc.Name + ": optimisticLock is not a boolean")
+		} else if c.IsOptimisticLock {
+			switch c.ColumnType {
+			case ColTypeInteger, ColTypeUnsigned, ColTypeInteger64, ColTypeUnsigned64:
+			default:
+				log.Warningf("Error in option for column " + desc.Name + ": optimisticLock columns must be integers")
+				c.IsOptimisticLock = false
+			}
+			if c.IsPk || c.IsNullable {
+				log.Warningf("Error in option for column " + desc.Name + ": optimisticLock columns cannot be primary keys or nullable")
+				c.IsOptimisticLock = false
+			}
 		}
 	}
 
-	if opt := desc.Options[MaxOption]; opt != nil {
-		if c.MaxValue, err = getMaxOption(c.MaxValue, opt); err != nil {
-			log.Warningf("Error in 'max' option for column %s: %s", desc.Name, err.Error())
+	switch opt := desc.Options[AuditOption].(type) {
+	case nil:
+		// Columns with the conventional names are audit columns if they have the right type
+		if a := auditColumnNames[desc.Name]; a.IsTime() == (c.ColumnType == ColTypeTime) {
+			c.Audit = a
+		}
+	case string:
+		c.Audit = AuditType(opt)
+		if !c.Audit.isValid() {
+			log.Warningf("Error in option for column " + desc.Name + ": audit must be createdAt, updatedAt, createdBy or updatedBy")
+			c.Audit = ""
+		} else if c.Audit.IsTime() != (c.ColumnType == ColTypeTime) {
+			log.Warningf("Error in option for column " + desc.Name + ": only createdAt and updatedAt columns can be time columns, and they must be")
+			c.Audit = ""
+		}
+	case bool:
+		if opt {
+			log.Warningf("Error in option for column " + desc.Name + ": audit must be the kind of audit column or false")
+		}
+	default:
+		log.Warningf("Error in option for column " + desc.Name + ": audit is not a string")
+	}
+
+	if opt := desc.Options[JsonTypeOption]; opt != nil {
+		if s, ok2 := opt.(string); !ok2 {
+			log.Warningf("Error in option for column " + desc.Name + ": jsonType is not a string")
+		} else if c.ColumnType != ColTypeJSON {
+			log.Warningf("Error in option for column " + desc.Name + ": jsonType can only be used on JSON columns")
+		} else {
+			c.JsonGoType, c.JsonGoTypeImport = parseJsonType(s)
 		}
 	}
 
@@ -507,6 +658,10 @@ func (m *Model) importForeignKeys(desc TableDescription) {
 
 func (m *Model) importForeignKey(t *Table, cd ColumnDescription) {
 	c := t.columnMap[cd.Name]
+	if cd.ForeignKey != nil && cd.ForeignKey.ReferencedDatabase != "" && cd.ForeignKey.ReferencedDatabase != m.DbKey {
+		m.importExternalReference(t, c, cd)
+		return
+	}
 	if cd.ForeignKey != nil {
 		f := &ForeignKeyInfo{
 			ReferencedTable:  cd.ForeignKey.ReferencedTable,
@@ -540,6 +695,14 @@ func (m *Model) importForeignKey(t *Table, cd ColumnDescription) {
 			f.GoTypePlural = tt.GoPlural
 			suf := UpperCaseIdentifier(m.ForeignKeySuffix)
 			c.referenceFunction = strings.TrimSuffix(f.GoName, suf)
+			if c.referenceFunction == c.GoName {
+				// The column does not end with the foreign key suffix, as is usual for columns of native enum types,
+				// so add it to the column to keep its accessors apart from those of the enum value.
+				if suf == "" {
+					suf = "ID"
+				}
+				c.GoName += suf
+			}
 		} else {
 			r := m.Table(cd.ForeignKey.ReferencedTable)
 			f.GoType = r.GoName
@@ -554,6 +717,32 @@ func (m *Model) importForeignKey(t *Table, cd ColumnDescription) {
 	}
 }
 
+// importExternalReference records that the column refers to a table in another database.
+// The other database may not be analyzed yet, so the reference is resolved when it is used.
+func (m *Model) importExternalReference(t *Table, c *Column, cd ColumnDescription) {
+	if c.IsPk {
+		panic(fmt.Sprintf("the primary key of a table cannot refer to another database. Table: %s, Col: %s", t.DbName, cd.Name))
+	}
+	suf := UpperCaseIdentifier(m.ForeignKeySuffix)
+	goName := strings.TrimSuffix(c.GoName, suf)
+	if goName == "" {
+		goName = UpperCaseIdentifier(cd.ForeignKey.ReferencedTable)
+	} else if goName == c.GoName {
+		// The column does not end with the foreign key suffix, so add it to the column to keep its accessors
+		// apart from those of the referenced object.
+		if suf == "" {
+			suf = "ID"
+		}
+		c.GoName += suf
+	}
+	c.ExternalReference = &ExternalReference{
+		DbKey:            cd.ForeignKey.ReferencedDatabase,
+		ReferencedTable:  cd.ForeignKey.ReferencedTable,
+		ReferencedColumn: cd.ForeignKey.ReferencedColumn,
+		GoName:           goName,
+	}
+}
+
 // cleanTableName converts the table name to a snake case name that is
 // suitable for converting to equivalent descriptions.
 func (m *Model) cleanTableName(n string) string {
@@ -696,3 +885,14 @@ func ExecuteTransaction(ctx context.Context, d DatabaseI, f func()) {
 	f()
 	d.Commit(ctx, txid)
 }
+
+// ExecuteTransactionE wraps the function in a database transaction, and returns any error that occurs.
+// If f returns an error, the transaction is rolled back and the error is returned.
+// A panic in f will also roll back the transaction.
+//
+// If a transaction is already in progress, the function is run in a nested transaction, and only the work
+// done by f is rolled back.
+func ExecuteTransactionE(ctx context.Context, d DatabaseI, f func() error) error {
+	_, err := executeTransaction(ctx, d, f)
+	return err
+}
@@ -30,6 +30,9 @@ type QueryBuilder struct {
 	LimitInfo  *LimitInfo
 	HavingNode NodeI
 	IsSubquery bool
+	IsCached   bool
+	// IsWithDeleted includes records that are marked as deleted by a soft delete column
+	IsWithDeleted bool
 }
 
 type AliasNodesType = maps.SliceMap[string, Aliaser]
@@ -45,11 +48,10 @@ func (b *QueryBuilder) Context() context.Context {
 }
 
 // Join will attach the given reference node to the builder.
+// To join the same relationship more than once with different conditions, give each
+// join an alias with the As function of the reference node, and use that node to refer to the join
+// in other clauses.
 func (b *QueryBuilder) Join(n NodeI, condition NodeI) {
-	// Possible TBD: If we ever want to support joining the same tables multiple
-	// times with different conditions, we could use an alias to name each join. We would
-	// then need to create an Alias node to specify which join is meant in different clauses.
-
 	if b.Joins != nil {
 		if !NodeIsReferenceI(n) {
 			panic("you can only join Reference, ReverseReference and ManyManyReference nodes")
@@ -128,6 +130,17 @@ func (b *QueryBuilder) Distinct() {
 	b.IsDistinct = true
 }
 
+// Cache sets the cache bit, causing the results of the query to be cached if the database has a query cache.
+func (b *QueryBuilder) Cache() {
+	b.IsCached = true
+}
+
+// WithDeleted sets the with deleted bit, causing the query to include records that are marked as deleted
+// by a soft delete column.
+func (b *QueryBuilder) WithDeleted() {
+	b.IsWithDeleted = true
+}
+
 // GroupBy sets the nodes that are grouped. According to SQL rules, these then are the only nodes that can be
 // selected, and they MUST be selected.
 func (b *QueryBuilder) GroupBy(nodes ...NodeI) {
@@ -159,6 +172,10 @@ func (b *QueryBuilder) Load() []map[string]interface{} {
 func (b *QueryBuilder) Delete() {
 }
 
+// Update is a stub that helps the QueryBuilder implement the query.QueryBuilderI interface so it can be included in sub-queries.
+func (b *QueryBuilder) Update(_ map[NodeI]interface{}) {
+}
+
 // Count is a stub that helps the QueryBuilder implement the query.QueryBuilderI interface so it can be included in sub-queries.
 func (b *QueryBuilder) Count(_ bool, _ ...NodeI) uint {
 	return 0
@@ -0,0 +1,21 @@
+package db
+
+import (
+	"context"
+	"github.com/goradd/goradd/pkg/goradd"
+)
+
+const readYourWritesContext = goradd.ContextKey("goradd.readYourWrites")
+
+// ReadYourWrites returns a context in which reads are sent to the primary database instead of to
+// read replicas. Use it when a request needs to read data that it just wrote, since
+// replicas may not have received the changes yet.
+func ReadYourWrites(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readYourWritesContext, true)
+}
+
+// IsReadingYourWrites returns true if the context was prepared with ReadYourWrites.
+func IsReadingYourWrites(ctx context.Context) bool {
+	v, _ := ctx.Value(readYourWritesContext).(bool)
+	return v
+}
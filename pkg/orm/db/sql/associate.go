@@ -21,6 +21,20 @@ func Associate(ctx context.Context,
 	relatedColumn string,
 	relatedPks interface{}) { //relatedPks must be a slice of items
 
+	if err := AssociateE(ctx, db, table, column, pk, relatedColumn, relatedPks); err != nil {
+		panic(err.Error())
+	}
+}
+
+// AssociateE is like Associate, but returns the first database error it encounters instead of panicking.
+func AssociateE(ctx context.Context,
+	db DbI,
+	table string,
+	column string,
+	pk interface{},
+	relatedColumn string,
+	relatedPks interface{}) error {
+
 	// TODO: Could optimize by separating out what gets deleted, what gets added, and what stays the same.
 
 	// TODO: Make this part of a transaction
@@ -29,10 +43,10 @@ func Associate(ctx context.Context,
 		db.QuoteIdentifier(column) + "=" + db.FormatArgument(1)
 	_, e := db.Exec(ctx, sql, pk)
 	if e != nil {
-		panic(e.Error())
+		return e
 	}
 	if relatedPks == nil {
-		return
+		return nil
 	}
 
 	// Add new associations
@@ -42,7 +56,8 @@ func Associate(ctx context.Context,
 			") VALUES (" + db.FormatArgument(1) + "," + db.FormatArgument(2) + ")"
 		_, e = db.Exec(ctx, sql, pk, relatedPk)
 		if e != nil {
-			panic(e.Error())
+			return e
 		}
 	}
+	return nil
 }
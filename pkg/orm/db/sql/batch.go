@@ -0,0 +1,78 @@
+package sql
+
+import (
+	"github.com/goradd/goradd/pkg/stringmap"
+	"strings"
+)
+
+// BatchGroup is a group of rows that set the same columns, and so can be written with one multi-row statement.
+type BatchGroup struct {
+	// Columns are the names of the columns set by every row in the group, in sorted order.
+	Columns []string
+	// Rows are the indexes of the rows in the slice that was passed to GroupBatchRows.
+	Rows []int
+}
+
+// GroupBatchRows divides rows into groups that can each be written with one statement.
+// Rows are grouped by the columns they set, in the order the groups first appear, and groups are split
+// so that no statement needs more than maxArgs arguments.
+func GroupBatchRows(rows []map[string]any, maxArgs int) (groups []BatchGroup) {
+	groupIndexes := make(map[string]int)
+	for i, row := range rows {
+		if len(row) == 0 {
+			panic("No fields to insert")
+		}
+		columns := stringmap.SortedKeys(row)
+		key := strings.Join(columns, "\x00")
+		gi, ok := groupIndexes[key]
+		if !ok || len(groups[gi].Rows)*len(columns)+len(columns) > maxArgs {
+			gi = len(groups)
+			groups = append(groups, BatchGroup{Columns: columns})
+			groupIndexes[key] = gi
+		}
+		groups[gi].Rows = append(groups[gi].Rows, i)
+	}
+	return
+}
+
+// GenerateBatchInsert is a helper function for database implementations to generate a multi-row
+// insert statement for the rows in the group.
+func GenerateBatchInsert(db DbI, table string, g BatchGroup, rows []map[string]any) (sql string, args []any) {
+	var keys []string
+	for _, col := range g.Columns {
+		keys = append(keys, db.QuoteIdentifier(col))
+	}
+
+	var values []string
+	for _, ri := range g.Rows {
+		var placeholders []string
+		for _, col := range g.Columns {
+			args = append(args, rows[ri][col])
+			placeholders = append(placeholders, columnWriteSql(db, table, col, db.FormatArgument(len(args))))
+		}
+		values = append(values, "("+strings.Join(placeholders, ",")+")")
+	}
+
+	sql = "INSERT INTO " + db.QuoteIdentifier(table)
+	sql += "(" + strings.Join(keys, ",") + ")\nVALUES "
+	sql += strings.Join(values, ",\n") + "\n"
+	return
+}
+
+// UpsertUpdateColumns returns the columns of the group that should be updated when a row conflicts with an
+// existing record, which are all the columns that are not conflict columns.
+func UpsertUpdateColumns(g BatchGroup, conflictColumns []string) (columns []string) {
+	for _, col := range g.Columns {
+		var isConflict bool
+		for _, c := range conflictColumns {
+			if c == col {
+				isConflict = true
+				break
+			}
+		}
+		if !isConflict {
+			columns = append(columns, col)
+		}
+	}
+	return
+}
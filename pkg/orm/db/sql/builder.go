@@ -2,11 +2,13 @@ package sql
 
 import (
 	"context"
+	sql2 "database/sql"
 	"errors"
 	"fmt"
 	db2 "github.com/goradd/goradd/pkg/orm/db"
 	. "github.com/goradd/goradd/pkg/orm/query"
 	"github.com/goradd/maps"
+	"sort"
 	"strconv"
 )
 
@@ -14,6 +16,11 @@ const countAlias = "_count"
 const columnAliasPrefix = "c_"
 const tableAliasPrefix = "t_"
 
+// replicaQueryer is implemented by databases that can send read-only queries to a read replica.
+type replicaQueryer interface {
+	ReplicaQuery(ctx context.Context, sql string, args ...interface{}) (*sql2.Rows, error)
+}
+
 type objectMapType = maps.SliceMap[string, any]
 type aliasMapType = maps.SliceMap[string, any]
 type JoinTreeItemSliceMap = maps.SliceMap[string, *JoinTreeItem]
@@ -33,6 +40,7 @@ type Builder struct {
 
 	IsCount           bool
 	IsDelete          bool
+	IsUpdate          bool
 	RootDbTable       string                  // The database name for the table that is the root of the query
 	RootJoinTreeItem  *JoinTreeItem           // The top of the join tree
 	SubPrefix         string                  // The prefix for sub items. If this is a sub query, this gets updated
@@ -43,6 +51,18 @@ type Builder struct {
 	NodeMap           map[NodeI]*JoinTreeItem // A map that gets us to a JoinTreeItem from a node.
 	RowId             int                     // Counter for creating fake ids when doing distinct or orderby selects
 	ParentBuilder     *Builder                // The parent builder of a subquery
+
+	updateFields []updateField // The columns and values to set in an update
+
+	ctes        []*CommonTableExpression // The common table expressions defined in the WITH clause of the top query
+	cteRefs     []*CommonTableExpression // The common table expressions that are joined in the FROM clause of this query
+	withBuilder *Builder                 // If this is a query of a common table expression, the builder that defines it
+}
+
+// updateField is a column to set in an update, and the value or node to set it to.
+type updateField struct {
+	column *ColumnNode
+	value  interface{}
 }
 
 // NewSqlBuilder creates a new Builder object.
@@ -71,31 +91,31 @@ func (b *Builder) Load() (result []map[string]interface{}) {
 	b.makeColumnAliases()
 
 	sql, args := b.generateSelectSql()
-	rows, err := b.db.Query(b.Ctx, sql, args...)
+	return b.cachedQuery(sql, args, func() interface{} {
+		rows, err := b.readQuery(sql, args)
 
-	if err != nil {
-		// This is possibly generating an error related to the sql itself, so put the sql in the error message.
-		s := err.Error()
-		s += "\nSql: " + sql
-
-		panic(errors.New(s))
-	}
+		if err != nil {
+			// This is possibly generating an error related to the sql itself, so put the sql in the error message.
+			s := err.Error()
+			s += "\nSql: " + sql
 
-	names, _ := rows.Columns()
+			panic(errors.New(s))
+		}
 
-	columnTypes := make([]GoColumnType, len(names))
-	colCount := b.ColumnAliases.Len()
-	for i := 0; i < colCount; i++ {
-		columnTypes[i] = ColumnNodeGoType(b.ColumnAliases.Get(names[i]).Node.(*ColumnNode))
-	}
-	// add special aliases
-	for i := colCount; i < len(names); i++ {
-		columnTypes[i] = ColTypeBytes // These will be unpacked when they are retrieved
-	}
+		names, _ := rows.Columns()
 
-	result = SqlReceiveRows(rows, columnTypes, names, b)
+		columnTypes := make([]GoColumnType, len(names))
+		colCount := b.ColumnAliases.Len()
+		for i := 0; i < colCount; i++ {
+			columnTypes[i] = ColumnNodeGoType(b.ColumnAliases.Get(names[i]).Node.(*ColumnNode))
+		}
+		// add special aliases
+		for i := colCount; i < len(names); i++ {
+			columnTypes[i] = ColTypeBytes // These will be unpacked when they are retrieved
+		}
 
-	return result
+		return SqlReceiveRows(rows, columnTypes, names, b)
+	}).([]map[string]interface{})
 }
 
 // LoadCursor terminates the builder, queries the database, and returns a cursor that can be used to step through
@@ -118,7 +138,7 @@ func (b *Builder) LoadCursor() CursorI {
 	// Hand off the generation of sql select statements to the database, since different databases generate sql differently
 	sql, args := b.generateSelectSql()
 
-	rows, err := b.db.Query(b.Ctx, sql, args...)
+	rows, err := b.readQuery(sql, args)
 
 	if err != nil {
 		// This is possibly generating an error related to the sql itself, so put the sql in the error message.
@@ -151,6 +171,51 @@ func (b *Builder) Delete() {
 	}
 }
 
+// Update sets the given columns of all the records that match the conditions of the query, without loading them.
+// The keys of fields must be column nodes of the table being queried. The values can be Go values,
+// or nodes, including operation nodes that calculate new values from the current ones, like
+// op.Add(node.Project().Spent(), 10).
+//
+// In Postgres and SQLite, if the conditions need to join other tables, the values can only refer to columns of the
+// table being updated.
+func (b *Builder) Update(fields map[NodeI]interface{}) {
+	if len(fields) == 0 {
+		panic("No fields to set")
+	}
+	if b.LimitInfo != nil {
+		panic("cannot use Limit with Update")
+	}
+	b.IsUpdate = true
+	for n, v := range fields {
+		c, ok := n.(*ColumnNode)
+		if !ok {
+			panic("the fields to update must be column nodes")
+		}
+		b.updateFields = append(b.updateFields, updateField{c, v})
+	}
+	// Sort so that the same update generates the same sql, which helps databases reuse prepared statements
+	sort.Slice(b.updateFields, func(i, j int) bool {
+		return ColumnNodeDbName(b.updateFields[i].column) < ColumnNodeDbName(b.updateFields[j].column)
+	})
+	for i := 1; i < len(b.updateFields); i++ {
+		if ColumnNodeDbName(b.updateFields[i].column) == ColumnNodeDbName(b.updateFields[i-1].column) {
+			panic("the column " + ColumnNodeDbName(b.updateFields[i].column) + " is set more than once")
+		}
+	}
+
+	b.buildJoinTree()
+	for _, f := range b.updateFields {
+		if b.GetItemFromNode(f.column).Parent != b.RootJoinTreeItem {
+			panic("Update can only set the columns of the table being queried")
+		}
+	}
+	sql, args := b.generateUpdateSql()
+	_, err := b.db.Exec(b.Ctx, sql, args...)
+	if err != nil {
+		panic(err)
+	}
+}
+
 // Count creates a query that selects one thing, a count. If distinct is specified, only distinct items will be selected.
 // If no columns are specified, the count will include NULL items. Otherwise, it will not include NULL results in the count.
 // You cannot include any other select items in a count. If you want to do that, you should do a normal query and add a
@@ -176,17 +241,27 @@ func (b *Builder) Count(distinct bool, nodes ...NodeI) uint {
 	b.buildJoinTree()
 
 	sql, args := b.generateSelectSql()
-	rows, err := b.db.Query(b.Ctx, sql, args...)
+	return b.cachedQuery(sql, args, func() interface{} {
+		rows, err := b.readQuery(sql, args)
 
-	if err != nil {
-		panic(err)
-	}
+		if err != nil {
+			panic(err)
+		}
 
-	names, _ := rows.Columns()
-	columnTypes := []GoColumnType{ColTypeUnsigned}
-	result = SqlReceiveRows(rows, columnTypes, names, nil)
+		names, _ := rows.Columns()
+		columnTypes := []GoColumnType{ColTypeUnsigned}
+		result = SqlReceiveRows(rows, columnTypes, names, nil)
 
-	return result[0][countAlias].(uint)
+		return result[0][countAlias].(uint)
+	}).(uint)
+}
+
+// readQuery sends a query that only reads data, using a read replica if the database has them.
+func (b *Builder) readQuery(sql string, args []any) (*sql2.Rows, error) {
+	if r, ok := b.db.(replicaQueryer); ok {
+		return r.ReplicaQuery(b.Ctx, sql, args...)
+	}
+	return b.db.Query(b.Ctx, sql, args...)
 }
 
 // After the intention of the query is gathered, this will add the various nodes from the query
@@ -196,6 +271,12 @@ func (b *Builder) buildJoinTree() {
 	for _, n := range nodes {
 		b.addNodeToJoinTree(n)
 	}
+	for _, f := range b.updateFields {
+		b.addNodeToJoinTree(f.column)
+		if n, ok := f.value.(NodeI); ok {
+			b.addNodeToJoinTree(n)
+		}
+	}
 	b.assignTableAliases(b.RootJoinTreeItem)
 }
 
@@ -217,6 +298,10 @@ func (b *Builder) addNodeToJoinTree(n NodeI) {
 			b2.buildJoinTree()
 			continue
 		}
+		if cn, ok := node.(*CteColumnNode); ok {
+			b.addCteRef(CteColumnNodeCte(cn))
+			continue
+		}
 
 		rootNode := RootNode(node)
 		if rootNode == nil {
@@ -253,7 +338,11 @@ func (b *Builder) gatherContainedNodes(n NodeI) (nodes []NodeI) {
 
 		// must expand the returned nodes one more time
 		for _, n2 := range db2.Nodes(SubqueryBuilder(sn).(*Builder).QueryBuilder) {
-			nodes = append(nodes, b.gatherContainedNodes(n2)...)
+			for _, n3 := range b.gatherContainedNodes(n2) {
+				if _, ok := n3.(*CteColumnNode); !ok { // the subquery joins its own common table expressions
+					nodes = append(nodes, n3)
+				}
+			}
 		}
 	} else if cn := ContainedNodes(n); cn != nil {
 		nodes = append(nodes, cn...)
@@ -263,6 +352,52 @@ func (b *Builder) gatherContainedNodes(n NodeI) (nodes []NodeI) {
 	return
 }
 
+// addCteRef joins the common table expression to this query, and makes sure it is defined by the top query.
+func (b *Builder) addCteRef(c *CommonTableExpression) {
+	for _, c2 := range b.cteRefs {
+		if CteName(c2) == CteName(c) {
+			return
+		}
+	}
+	b.cteRefs = append(b.cteRefs, c)
+
+	top := b
+	for {
+		if top.ParentBuilder != nil {
+			top = top.ParentBuilder
+		} else if top.withBuilder != nil {
+			top = top.withBuilder
+		} else {
+			break
+		}
+	}
+	top.addCte(c)
+}
+
+// addCte adds the common table expression to the WITH clause, after the ones it depends on.
+func (b *Builder) addCte(c *CommonTableExpression) {
+	for _, c2 := range b.ctes {
+		if CteName(c2) == CteName(c) {
+			return
+		}
+	}
+	builders := CteBuilders(c)
+	b2 := builders[0].(*Builder)
+	if b2.withBuilder != nil {
+		return // already added, or being added because it is recursive
+	}
+	for _, qb := range builders {
+		b2 = qb.(*Builder)
+		b2.withBuilder = b
+	}
+	for _, qb := range builders {
+		b2 = qb.(*Builder)
+		b2.buildJoinTree()
+		b2.makeColumnAliases()
+	}
+	b.ctes = append(b.ctes, c)
+}
+
 /*
 func (b *Builder) logNode(node NodeI, level int) {
 	LogNode(node, level)
@@ -283,18 +418,6 @@ func (b *Builder) mergeNode(srcNode NodeI, destJoinItem *JoinTreeItem) {
 	// make sure node is mapped
 	b.mapNode(srcNode, destJoinItem)
 
-	srcAliaser, ok := srcNode.(Aliaser)
-	if ok &&
-		srcAliaser.GetAlias() != "" &&
-		srcAliaser.GetAlias() != destJoinItem.Alias {
-		_, isColumnNode := srcNode.(*ColumnNode)
-		if !isColumnNode {
-			// Adding a pre-aliased node that is at the same level as this node, so just add it.
-			b.insertNode(srcNode, destJoinItem.Parent)
-			return
-		}
-	}
-
 	var childNode = ChildNode(srcNode)
 	if childNode == nil {
 		// The srcNode already exists in the tree. Since there is nothing below it, we might have additional information
@@ -303,9 +426,7 @@ func (b *Builder) mergeNode(srcNode NodeI, destJoinItem *JoinTreeItem) {
 			if destJoinItem.JoinCondition == nil {
 				destJoinItem.JoinCondition = prevCond
 			} else if !destJoinItem.JoinCondition.Equals(prevCond) {
-				// TODO: We need a mechanism to allow different kinds of conditional joins, perhaps through aliases so that
-				// items further down the chain can be identified as to which conditional join they belong to.
-				panic("Error, attempting to Join with conditions on a node which already has different conditions.")
+				panic("Error, attempting to Join with conditions on a node which already has different conditions. Give each join an alias to join it more than once.")
 			}
 		}
 
@@ -389,7 +510,10 @@ func (b *Builder) makeColumnAliases() {
 		}
 		// We must also select on orderby's, or we cannot actually order by them
 		for _, n := range b.OrderBys {
-			b.assignAlias(b.GetItemFromNode(n))
+			// Operations and aliases, like the score of a search, are not columns of a table
+			if _, isColumn := n.(*ColumnNode); isColumn {
+				b.assignAlias(b.GetItemFromNode(n))
+			}
 		}
 
 		if !(b.IsDistinct || b.IsSubquery || b.IsCount) {
@@ -560,7 +684,7 @@ func (b *Builder) unpackObject(parent *JoinTreeItem, row db2.ValueMap, oMap *obj
 		if !NodeIsTableNodeI(childItem.Node) {
 			panic("leaf node put in the table nodes")
 		} else {
-			arrayKey = NodeGoName(childItem.Node)
+			arrayKey = joinKey(childItem.Node)
 		}
 		// if this is an embedded object, collect a group of objects
 		if i, ok := obj[arrayKey]; !ok {
@@ -731,7 +855,7 @@ func (b *Builder) expandNode(j *JoinTreeItem, nodeObject db2.ValueMap) (outArray
 
 	for _, childItem := range append(j.ChildReferences) {
 		copies = []db2.ValueMap{}
-		tableGoName := NodeGoName(childItem.Node)
+		tableGoName := joinKey(childItem.Node)
 
 		for _, item = range outArray {
 			switch NodeGetType(childItem.Node) {
@@ -839,9 +963,38 @@ func (b *Builder) expandNode(j *JoinTreeItem, nodeObject db2.ValueMap) (outArray
 		}
 	}
 
+	// Move the results of aliased joins to their own map so that they do not replace the results of other joins
+	for _, childItem := range j.ChildReferences {
+		if childItem.Node.(Aliaser).GetAlias() == "" {
+			continue
+		}
+		key := joinKey(childItem.Node)
+		for _, item = range outArray {
+			if v, ok := item[key]; ok {
+				joins, _ := item[JoinAliasResults].(db2.ValueMap)
+				if joins == nil {
+					joins = db2.NewValueMap()
+					item[JoinAliasResults] = joins
+				}
+				joins[key] = v
+				delete(item, key)
+			}
+		}
+	}
+
 	return
 }
 
+// joinKey returns the key used for the results of the join of the given table node.
+// Aliased joins have keys of the form GoName.alias.
+func joinKey(n NodeI) string {
+	key := NodeGoName(n)
+	if alias := n.(Aliaser).GetAlias(); alias != "" {
+		key += "." + alias
+	}
+	return key
+}
+
 // unpack the manually aliased items from the result
 func (b *Builder) unpackSpecialAliases(rowId string, row db2.ValueMap, aliasMap *aliasMapType) {
 	var obj db2.ValueMap
@@ -871,6 +1024,13 @@ func (b *Builder) generateSelectSql() (sql string, args []any) {
 	return
 }
 
+func (b *Builder) generateUpdateSql() (sql string, args []any) {
+	g := newSelectGenerator(b)
+	sql = g.generateUpdateSql()
+	args = g.argList
+	return
+}
+
 func (b *Builder) generateDeleteSql() (sql string, args []any) {
 	g := newSelectGenerator(b)
 	sql = g.generateDeleteSql()
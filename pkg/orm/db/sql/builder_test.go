@@ -0,0 +1,315 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	db2 "github.com/goradd/goradd/pkg/orm/db"
+	"github.com/goradd/goradd/pkg/orm/op"
+	. "github.com/goradd/goradd/pkg/orm/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingDb is a DbI that records the statements executed instead of running them.
+type recordingDb struct {
+	usesAlias bool
+	sql       string
+	args      []any
+}
+
+func (d *recordingDb) Exec(_ context.Context, sql string, args ...interface{}) (sql.Result, error) {
+	d.sql = sql
+	d.args = args
+	return nil, nil
+}
+
+func (d *recordingDb) Query(_ context.Context, _ string, _ ...interface{}) (*sql.Rows, error) {
+	return nil, nil
+}
+
+func (d *recordingDb) QuoteIdentifier(v string) string {
+	return "`" + v + "`"
+}
+
+func (d *recordingDb) FormatArgument(_ int) string {
+	return "?"
+}
+
+func (d *recordingDb) DbKey() string {
+	return "db"
+}
+
+func (d *recordingDb) DeleteUsesAlias() bool {
+	return d.usesAlias
+}
+
+type projectNode struct {
+	ReferenceNodeI
+}
+
+func project() *projectNode {
+	n := &projectNode{NewTableNode("db", "project", "Project")}
+	SetParentNode(n, nil)
+	return n
+}
+
+func (n *projectNode) SelectNodes_() []*ColumnNode {
+	return []*ColumnNode{n.column("id"), n.column("status"), n.column("spent")}
+}
+
+func (n *projectNode) PrimaryKeyNode() *ColumnNode {
+	return n.column("id")
+}
+
+func (n *projectNode) EmbeddedNode_() NodeI {
+	return n.ReferenceNodeI
+}
+
+func (n *projectNode) Copy_() NodeI {
+	return &projectNode{CopyNode(n.ReferenceNodeI)}
+}
+
+func (n *projectNode) column(name string) *ColumnNode {
+	cn := NewColumnNode("db", "project", name, name, ColTypeInteger, name == "id")
+	SetParentNode(cn, n)
+	return cn
+}
+
+func (n *projectNode) manager() *projectNode {
+	cn := &projectNode{NewReferenceNode("db", "project", "manager_id", "ManagerID", "Manager", "project", "id", false, ColTypeInteger)}
+	SetParentNode(cn, n)
+	return cn
+}
+
+func TestBuilderUpdate(t *testing.T) {
+	d := &recordingDb{}
+	b := NewSqlBuilder(context.Background(), d)
+	b.Join(project(), nil)
+	b.Condition(op.Equal(project().column("status"), 1))
+	b.Update(map[NodeI]interface{}{
+		project().column("status"): 2,
+		project().column("spent"):  op.Add(project().column("spent"), 10),
+	})
+	assert.Equal(t, "UPDATE `project` AS `t_0`\nSET `spent`= (`t_0`.`spent` + ?) , `status`=?\nWHERE  (`t_0`.`status` = ?) \n", d.sql)
+	assert.Equal(t, []any{10, 2, 1}, d.args)
+
+	// Conditions on joined tables use a subquery
+	b = NewSqlBuilder(context.Background(), d)
+	b.Join(project(), nil)
+	b.Condition(op.Equal(project().manager().column("status"), 1))
+	b.Update(map[NodeI]interface{}{project().column("status"): 2})
+	assert.Equal(t, "UPDATE `project` AS `t_0`\nSET `status`=?\n"+
+		"WHERE `t_0`.`id` IN (SELECT `t_0`.`id`\nFROM\n`project` AS `t_0`\n"+
+		"LEFT JOIN `project` AS `t_1` ON `t_0`.`manager_id` = `t_1`.`id`\nWHERE  (`t_1`.`status` = ?) \n)\n", d.sql)
+
+	// unless the database can join tables in an update
+	d.usesAlias = true
+	b = NewSqlBuilder(context.Background(), d)
+	b.Join(project(), nil)
+	b.Condition(op.Equal(project().manager().column("status"), 1))
+	b.Update(map[NodeI]interface{}{project().column("status"): 2})
+	assert.Equal(t, "UPDATE `project` AS `t_0`\nLEFT JOIN `project` AS `t_1` ON `t_0`.`manager_id` = `t_1`.`id`\n"+
+		"SET `t_0`.`status`=?\nWHERE  (`t_1`.`status` = ?) \n", d.sql)
+}
+
+func TestBuilderWindow(t *testing.T) {
+	d := &recordingDb{}
+	b := NewSqlBuilder(context.Background(), d)
+	b.Select(project().column("id"))
+	b.Alias("rank", op.Over(op.Rank()).
+		PartitionBy(project().column("status")).
+		OrderBy(project().column("spent").Descending()))
+	b.Alias("total", op.Over(op.Sum(project().column("spent"))).OrderBy(project().column("id")))
+	b.buildJoinTree()
+	b.makeColumnAliases()
+	sql, _ := b.generateSelectSql()
+	assert.Equal(t, "SELECT\n`t_0`.`id` AS `c_0`,\n"+
+		"RANK() OVER (PARTITION BY `t_0`.`status` ORDER BY `t_0`.`spent` DESC)  AS `rank`,\n"+
+		"SUM(`t_0`.`spent`) OVER (ORDER BY `t_0`.`id`)  AS `total`\n"+
+		"FROM\n`project` AS `t_0`\n", sql)
+}
+
+func TestBuilderCte(t *testing.T) {
+	d := &recordingDb{}
+
+	// all the projects managed directly or indirectly by project 1
+	anchor := NewSqlBuilder(context.Background(), d)
+	anchor.Select(project().column("id"))
+	anchor.Condition(op.Equal(project().column("id"), 1))
+	tree := op.Cte("tree", anchor.Subquery())
+	recursive := NewSqlBuilder(context.Background(), d)
+	recursive.Select(project().column("id"))
+	recursive.Condition(op.Equal(project().column("manager_id"), tree.Column("id")))
+	tree.Union(recursive.Subquery())
+
+	b := NewSqlBuilder(context.Background(), d)
+	b.Join(project(), nil)
+	b.Condition(op.And(op.Equal(project().column("id"), tree.Column("id")), op.Equal(project().column("status"), 2)))
+	b.buildJoinTree()
+	b.makeColumnAliases()
+	sql, args := b.generateSelectSql()
+	assert.Equal(t, "WITH RECURSIVE `tree` (`id`) AS (\n"+
+		"SELECT\n`t_0`.`id` AS `c_0`\nFROM\n`project` AS `t_0`\nWHERE  (`t_0`.`id` = ?) \n"+
+		"UNION\n"+
+		"SELECT\n`t_0`.`id` AS `c_0`\nFROM\n`project` AS `t_0`\nCROSS JOIN `tree`\nWHERE  (`t_0`.`manager_id` = `tree`.`id`) \n"+
+		")\n"+
+		"SELECT\n`t_0`.`id` AS `c_0`,\n`t_0`.`status` AS `c_1`,\n`t_0`.`spent` AS `c_2`\n"+
+		"FROM\n`project` AS `t_0`\nCROSS JOIN `tree`\n"+
+		"WHERE  ( (`t_0`.`id` = `tree`.`id`)  AND  (`t_0`.`status` = ?) ) \n", sql)
+	assert.Equal(t, []any{1, 2}, args)
+}
+
+func TestBuilderAliasedJoins(t *testing.T) {
+	d := &recordingDb{}
+	b := NewSqlBuilder(context.Background(), d)
+	manager := func(alias string) *projectNode {
+		n := project().manager()
+		n.SetAlias(alias)
+		return n
+	}
+	b.Join(project(), nil)
+	b.Join(manager("open"), op.Equal(manager("open").column("status"), 1))
+	b.Join(manager("closed"), op.Equal(manager("closed").column("status"), 2))
+	b.Select(project().column("id"), manager("open").column("spent"), manager("closed").column("spent"))
+	b.buildJoinTree()
+	b.makeColumnAliases()
+	sql, args := b.generateSelectSql()
+	assert.Equal(t, "SELECT\n`t_0`.`id` AS `c_0`,\n`t_1`.`spent` AS `c_1`,\n`t_2`.`spent` AS `c_2`,\n"+
+		"`t_1`.`id` AS `c_3`,\n`t_0`.`manager_id` AS `c_4`,\n`t_2`.`id` AS `c_5`\n"+
+		"FROM\n`project` AS `t_0`\n"+
+		"LEFT JOIN `project` AS `t_1` ON `t_0`.`manager_id` = `t_1`.`id` AND  (`t_1`.`status` = ?) \n"+
+		"LEFT JOIN `project` AS `t_2` ON `t_0`.`manager_id` = `t_2`.`id` AND  (`t_2`.`status` = ?) \n", sql)
+	assert.Equal(t, []any{1, 2}, args)
+
+	rows := []map[string]interface{}{
+		{"c_0": 1, "c_1": 10, "c_2": 30, "c_3": 2, "c_4": 2, "c_5": 2},
+	}
+	result := b.unpackResult(rows)
+	joins := result[0][JoinAliasResults].(db2.ValueMap)
+	assert.Equal(t, 10, joins["Manager.open"].(map[string]interface{})["spent"])
+	assert.Equal(t, 30, joins["Manager.closed"].(map[string]interface{})["spent"])
+	assert.NotContains(t, result[0], "Manager")
+}
+
+// modelDb is a recordingDb with a model of its tables.
+type modelDb struct {
+	recordingDb
+	model *db2.Model
+}
+
+func (d *modelDb) Model() *db2.Model {
+	return d.model
+}
+
+func TestBuilderTenant(t *testing.T) {
+	desc := db2.DatabaseDescription{
+		Tables: []db2.TableDescription{
+			{
+				Name:    "project",
+				Options: map[string]interface{}{db2.TenantOption: "tenant_id"},
+				Columns: []db2.ColumnDescription{
+					{Name: "id", GoType: "int", IsId: true, IsPk: true},
+					{Name: "tenant_id", GoType: "int"},
+				},
+			},
+		},
+	}
+	d := &modelDb{model: db2.NewModel("db", "", "_id", "_enum", true, desc)}
+	assert.True(t, d.model.Table("project").TenantColumn().IsTenant)
+
+	ctx := db2.WithTenant(context.Background(), 7)
+	b := NewSqlBuilder(ctx, d)
+	b.Join(project().manager(), nil)
+	b.Condition(op.Equal(project().column("status"), 1))
+	b.buildJoinTree()
+	b.makeColumnAliases()
+	sql, args := b.generateSelectSql()
+	assert.Contains(t, sql, "LEFT JOIN `project` AS `t_1` ON `t_0`.`manager_id` = `t_1`.`id` AND `t_1`.`tenant_id` = ?\n")
+	assert.Contains(t, sql, "WHERE `t_0`.`tenant_id` = ? AND ( (`t_0`.`status` = ?) )\n")
+	assert.Equal(t, []any{7, 7, 1}, args)
+
+	b = NewSqlBuilder(ctx, d)
+	b.Join(project(), nil)
+	b.Delete()
+	assert.Equal(t, "DELETE\nFROM\n`project` AS `t_0`\nWHERE `t_0`.`tenant_id` = ?\n", d.sql)
+	assert.Equal(t, []any{7}, d.args)
+
+	b = NewSqlBuilder(db2.WithoutTenant(context.Background()), d)
+	b.Join(project(), nil)
+	b.Delete()
+	assert.Equal(t, "DELETE\nFROM\n`project` AS `t_0`\n", d.sql)
+
+	assert.Panics(t, func() {
+		b = NewSqlBuilder(context.Background(), d)
+		b.Join(project(), nil)
+		b.Delete()
+	}, "a query without a tenant in the context panics")
+}
+
+// searchDb is a recordingDb that can do full text searches.
+type searchDb struct {
+	recordingDb
+}
+
+func (d *searchDb) FullTextSql(score bool, _ string, _ []string, columnSql []string, textSql string) string {
+	if score {
+		return "SCORE(" + strings.Join(columnSql, ",") + "," + textSql + ")"
+	}
+	return "MATCH(" + strings.Join(columnSql, ",") + "," + textSql + ")"
+}
+
+func TestBuilderSearch(t *testing.T) {
+	d := &searchDb{}
+	b := NewSqlBuilder(context.Background(), d)
+	b.Join(project(), nil)
+	b.Select(project().column("id"))
+
+	// the query built by a generated Search function
+	score := op.MatchScore("text", project().column("name")).Descending()
+	b.Condition(op.Match("text", project().column("name")))
+	b.Alias("score", score)
+	b.OrderBy(score)
+	b.buildJoinTree()
+	b.makeColumnAliases()
+	sql, args := b.generateSelectSql()
+	assert.Equal(t, "SELECT\n`t_0`.`id` AS `c_0`,\nSCORE(`t_0`.`name`,?) AS `score`\n"+
+		"FROM\n`project` AS `t_0`\nWHERE MATCH(`t_0`.`name`,?)\nORDER BY `score` DESC\n", sql)
+	assert.Equal(t, []any{"text", "text"}, args)
+}
+
+func TestBuilderSoftDelete(t *testing.T) {
+	desc := db2.DatabaseDescription{
+		Tables: []db2.TableDescription{
+			{
+				Name: "project",
+				Columns: []db2.ColumnDescription{
+					{Name: "id", GoType: "int", IsId: true, IsPk: true},
+					{Name: "deleted_at", GoType: "time.Time", IsNullable: true},
+				},
+			},
+		},
+	}
+	d := &modelDb{model: db2.NewModel("db", "", "_id", "_enum", true, desc)}
+	require.NotNil(t, d.model.Table("project").SoftDeleteColumn())
+
+	// deleted records are left out of the table being queried and the tables joined to it
+	b := NewSqlBuilder(context.Background(), d)
+	b.Join(project().manager(), nil)
+	b.Condition(op.Equal(project().column("status"), 1))
+	b.buildJoinTree()
+	b.makeColumnAliases()
+	sql, _ := b.generateSelectSql()
+	assert.Contains(t, sql, "LEFT JOIN `project` AS `t_1` ON `t_0`.`manager_id` = `t_1`.`id` AND `t_1`.`deleted_at` IS NULL\n")
+	assert.Contains(t, sql, "WHERE `t_0`.`deleted_at` IS NULL AND ( (`t_0`.`status` = ?) )\n")
+
+	b = NewSqlBuilder(context.Background(), d)
+	b.Join(project().manager(), nil)
+	b.WithDeleted()
+	b.buildJoinTree()
+	b.makeColumnAliases()
+	sql, _ = b.generateSelectSql()
+	assert.NotContains(t, sql, "deleted_at")
+}
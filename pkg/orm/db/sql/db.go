@@ -12,7 +12,8 @@ import (
 	"github.com/goradd/goradd/pkg/goradd"
 	"github.com/goradd/goradd/pkg/log"
 	"github.com/goradd/goradd/pkg/orm/db"
-	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -40,62 +41,211 @@ type ProfileEntry struct {
 	EndTime   time.Time
 	Typ       string
 	Sql       string
+	// Slow is true if the query took longer than the slow query threshold.
+	Slow bool
+	// Explain is the query plan of a slow query, if explaining slow queries is turned on.
+	Explain string
+	// Repeats is the number of times the same sql was run in the context up to and including this query.
+	Repeats int
 }
 
 // sqlContext is what is stored in the current context to keep track of queries. You must save a copy of this in the
 // current context with the sqlContext key before calling database functions in order to use transactions or
 // database profiling, or anything else the context is required for. The framework does this for you, but you will need
 // to do this yourself if using the orm without the framework.
+//
+// Since the context carries the transaction, goroutines that are given the context will run their queries
+// in the same transaction. The mutex protects the transaction state from those goroutines, and stmtMu
+// makes sure they send their statements to the transaction one at a time.
 type sqlContext struct {
-	tx       *sql.Tx
-	txCount  int // Keeps track of when to close a transaction
-	profiles []ProfileEntry
+	sync.Mutex
+	tx          *sql.Tx
+	txCount     int // Keeps track of when to close a transaction, and which savepoint is current
+	profiles    []ProfileEntry
+	queryCounts map[string]int // Counts identical queries to detect N+1 problems
+
+	stmtMu   sync.Mutex
+	openRows []*sql.Rows // The rows of queries in the transaction that may not be closed yet
+
+	commitHooks []commitHook // Called after the transaction commits
+}
+
+// commitHook is a function to call after a transaction commits, and the level of the nested transaction that
+// added it, so that it can be dropped if that nested transaction is rolled back.
+type commitHook struct {
+	level int
+	f     func()
+}
+
+// currentTx returns the transaction in progress, or nil if there is none.
+func (c *sqlContext) currentTx() *sql.Tx {
+	if c == nil {
+		return nil
+	}
+	c.Lock()
+	defer c.Unlock()
+	return c.tx
+}
+
+// afterCommit arranges for f to be called after the transaction in progress commits, and returns true.
+// f is not called if the transaction, or the nested transaction that is current, is rolled back.
+// If no transaction is in progress, it returns false and f is not called.
+func (c *sqlContext) afterCommit(f func()) bool {
+	if c == nil {
+		return false
+	}
+	c.Lock()
+	defer c.Unlock()
+	if c.tx == nil {
+		return false
+	}
+	c.commitHooks = append(c.commitHooks, commitHook{c.txCount, f})
+	return true
+}
+
+// dropCommitHooks removes the commit hooks added at the given level of nested transaction or deeper.
+// c must be locked.
+func (c *sqlContext) dropCommitHooks(level int) {
+	hooks := c.commitHooks[:0]
+	for _, h := range c.commitHooks {
+		if h.level < level {
+			hooks = append(hooks, h)
+		}
+	}
+	c.commitHooks = hooks
+}
+
+// lockTx returns the transaction in progress, locked so that only the caller can send it a statement.
+// Call the returned function to unlock it. If there is no transaction, it returns nil and does not lock.
+func (c *sqlContext) lockTx() (*sql.Tx, func()) {
+	tx := c.currentTx()
+	if tx == nil {
+		return nil, nil
+	}
+	c.stmtMu.Lock()
+	c.checkOpenRows()
+	return tx, c.stmtMu.Unlock
+}
+
+// checkOpenRows panics if the rows of a query in the transaction are still open.
+// A transaction uses one database connection, and database/sql cannot send another statement to the connection
+// until the rows are closed. This happens if goroutines share a transaction, or a statement is sent
+// while stepping through a cursor. c.stmtMu must be locked.
+func (c *sqlContext) checkOpenRows() {
+	open := c.openRows[:0]
+	for _, r := range c.openRows {
+		if _, err := r.Columns(); err == nil { // Columns returns an error once the rows are closed
+			open = append(open, r)
+		}
+	}
+	c.openRows = open
+	if len(open) > 0 {
+		c.stmtMu.Unlock()
+		panic("a statement was sent to a transaction before the rows of an earlier query were closed. " +
+			"Only use a transaction from one goroutine at a time, and close cursors before sending other queries")
+	}
+}
+
+// addProfile records a profile entry.
+func (c *sqlContext) addProfile(p ProfileEntry) {
+	c.Lock()
+	c.profiles = append(c.profiles, p)
+	c.Unlock()
+}
+
+// savepointName returns the name of the savepoint used for the nested transaction at the given level.
+func savepointName(txid db.TransactionID) string {
+	return fmt.Sprintf("goradd_sp%d", txid)
 }
 
 // DbHelper is a mixin for SQL database drivers. It implements common code needed by all SQL database drivers.
 type DbHelper struct {
-	dbKey     string  // key of the database as used in the global database map
-	db        *sql.DB // Internal copy of a Go database/sql object
-	profiling bool
+	dbKey       string    // key of the database as used in the global database map
+	db          *sql.DB   // Internal copy of a Go database/sql object
+	replicas    []*sql.DB // Read replicas of the primary database
+	nextReplica uint32    // Used to distribute reads among the replicas
+	profiling   bool
+
+	slowQueryThreshold time.Duration // Queries that take longer than this are logged
+	explainSlowQueries bool          // Whether to run EXPLAIN on slow queries
+	explainPrefix      string        // Put in front of a query to explain it
+	repeatThreshold    int           // Identical queries repeated this many times in a context are logged
 }
 
 // NewSqlDb creates a default DbHelper mixin.
 func NewSqlDb(dbKey string, db *sql.DB) DbHelper {
 	s := DbHelper{
-		dbKey: dbKey,
-		db:    db,
+		dbKey:         dbKey,
+		db:            db,
+		explainPrefix: "EXPLAIN ",
 	}
 	return s
 }
 
 // Begin starts a transaction. You should immediately defer a Rollback using the returned transaction id.
 // If you Commit before the Rollback happens, no Rollback will occur. The Begin-Commit-Rollback pattern is nestable.
+//
+// Nested transactions are implemented with savepoints, so rolling back a nested transaction only undoes the
+// work done since its Begin, and the outer transaction can continue.
 func (s *DbHelper) Begin(ctx context.Context) (txid db.TransactionID) {
+	txid, err := s.BeginE(ctx)
+	if err != nil {
+		panic(err.Error())
+	}
+	return
+}
+
+// BeginE is like Begin, but returns an error if the database could not start the transaction.
+func (s *DbHelper) BeginE(ctx context.Context) (txid db.TransactionID, err error) {
 	c := s.getContext(ctx)
 	if c == nil {
 		panic("Can't use transactions without pre-loading a context")
 	}
-	c.txCount++
-
-	if c.txCount == 1 {
-		var err error
+	c.Lock()
+	defer c.Unlock()
 
+	txid = db.TransactionID(c.txCount + 1)
+	if txid == 1 {
 		c.tx, err = s.db.Begin()
 		if err != nil {
-			_ = c.tx.Rollback()
-			c.txCount-- // transaction did not begin
-			panic(err.Error())
+			c.tx = nil
+			return 0, err // transaction did not begin
+		}
+	} else {
+		if _, err = s.txExec(ctx, c.tx, "SAVEPOINT "+savepointName(txid)); err != nil {
+			return 0, err
 		}
 	}
-	return db.TransactionID(c.txCount)
+	c.txCount++
+	return txid, nil
 }
 
 // Commit commits the transaction, and if an error occurs, will panic with the error.
 func (s *DbHelper) Commit(ctx context.Context, txid db.TransactionID) {
+	if err := s.CommitE(ctx, txid); err != nil {
+		panic(err.Error())
+	}
+}
+
+// CommitE commits the transaction, and returns any error the database reports.
+// If the commit of the outermost transaction fails, the database will have rolled back the transaction,
+// and a deferred Rollback will do nothing.
+// Committing a nested transaction releases its savepoint, and its work will be committed along with the outer transaction.
+// Mismatched calls to Begin and Commit are programming errors, and will still panic.
+func (s *DbHelper) CommitE(ctx context.Context, txid db.TransactionID) error {
 	c := s.getContext(ctx)
 	if c == nil {
 		panic("Can't use transactions without pre-loading a context")
 	}
+	var hooks []commitHook
+	defer func() {
+		// called after unlocking, so that the hooks can use the context
+		for _, h := range hooks {
+			h.f()
+		}
+	}()
+	c.Lock()
+	defer c.Unlock()
 
 	if c.txCount != int(txid) {
 		panic("Missing Rollback after previous Begin")
@@ -106,34 +256,83 @@ func (s *DbHelper) Commit(ctx context.Context, txid db.TransactionID) {
 	}
 	if c.txCount == 1 {
 		err := c.tx.Commit()
+		c.tx = nil
 		if err != nil {
-			panic(err.Error())
+			c.txCount = 0
+			c.commitHooks = nil
+			return err
+		}
+		hooks = c.commitHooks
+		c.commitHooks = nil
+	} else {
+		if _, err := s.txExec(ctx, c.tx, "RELEASE SAVEPOINT "+savepointName(txid)); err != nil {
+			// the savepoint is still current, so a deferred Rollback will roll back to it
+			return err
+		}
+		// the work of the nested transaction now belongs to the outer one
+		for i := range c.commitHooks {
+			if c.commitHooks[i].level == int(txid) {
+				c.commitHooks[i].level--
+			}
 		}
-		c.tx = nil
 	}
 	c.txCount--
+	return nil
 }
 
 // Rollback will rollback the transaction if the transaction is still pointing to the given txid. This gives the effect
 // that if you call Rollback on a transaction that has already been committed, no Rollback will happen. This makes it easier
 // to implement a transaction management scheme, because you simply always defer a Rollback after a Begin. Pass the txid
 // that you got from the Begin to the Rollback. To trigger a Rollback, simply panic.
+//
+// Rolling back a nested transaction rolls back to its savepoint, leaving the outer transaction open.
+// If the database has already aborted the whole transaction, for example because of a deadlock, the whole transaction
+// is rolled back, and the Rollbacks of the outer transactions will do nothing.
 func (s *DbHelper) Rollback(ctx context.Context, txid db.TransactionID) {
 	c := s.getContext(ctx)
 	if c == nil {
 		panic("Can't use transactions without pre-loading a context")
 	}
+	c.Lock()
+	defer c.Unlock()
 
-	if c.txCount == int(txid) {
-		err := c.tx.Rollback()
-		c.txCount = 0
-		c.tx = nil
-		if err != nil {
-			panic(err.Error())
+	if txid == 0 || c.txCount < int(txid) {
+		return // already committed or rolled back
+	}
+
+	if txid > 1 {
+		_, err := s.txExec(ctx, c.tx, "ROLLBACK TO SAVEPOINT "+savepointName(txid))
+		if err == nil {
+			c.txCount = int(txid) - 1
+			c.dropCommitHooks(int(txid))
+			return
 		}
+		log.Warning("Rolling back the whole transaction, since rolling back to a savepoint failed: " + err.Error())
+	}
+
+	err := c.tx.Rollback()
+	c.txCount = 0
+	c.tx = nil
+	c.commitHooks = nil
+	if err != nil {
+		panic(err.Error())
 	}
 }
 
+// InTransaction returns true if a transaction is in progress in the context.
+func (s *DbHelper) InTransaction(ctx context.Context) bool {
+	return s.getContext(ctx).currentTx() != nil
+}
+
+// txExec executes transaction control statements, like savepoint statements, in the given transaction.
+func (s *DbHelper) txExec(ctx context.Context, tx *sql.Tx, sql string) (sql.Result, error) {
+	c := s.getContext(ctx)
+	c.stmtMu.Lock()
+	defer c.stmtMu.Unlock()
+	log.Sql("Exec: ", sql)
+	return tx.ExecContext(ctx, sql)
+}
+
 // Exec executes the given SQL code, without returning any result rows.
 func (s *DbHelper) Exec(ctx context.Context, sql string, args ...interface{}) (r sql.Result, err error) {
 	c := s.getContext(ctx)
@@ -141,24 +340,16 @@ func (s *DbHelper) Exec(ctx context.Context, sql string, args ...interface{}) (r
 
 	var beginTime = time.Now()
 
-	if c != nil && c.tx != nil {
-		r, err = c.tx.ExecContext(ctx, sql, args...)
+	if tx, unlock := c.lockTx(); tx != nil {
+		r, err = tx.ExecContext(ctx, sql, args...)
+		unlock()
 	} else {
 		r, err = s.db.ExecContext(ctx, sql, args...)
 	}
 
 	var endTime = time.Now()
 
-	if c != nil && s.profiling {
-		if args != nil {
-			for _, arg := range args {
-				sql = strings.TrimSpace(sql)
-				sql += fmt.Sprintf(",\n%#v", arg)
-			}
-		}
-		c.profiles = append(c.profiles, ProfileEntry{DbKey: s.dbKey, BeginTime: beginTime, EndTime: endTime, Typ: "Exec", Sql: sql})
-	}
-
+	s.recordQuery(ctx, c, "Exec", sql, args, beginTime, endTime, err == nil)
 	return
 }
 
@@ -186,26 +377,48 @@ func (s *DbHelper) Prepare(ctx context.Context, sql string) (r *sql.Stmt, err er
 
 // Query executes the given sql, and returns a row result set.
 func (s *DbHelper) Query(ctx context.Context, sql string, args ...interface{}) (r *sql.Rows, err error) {
+	return s.query(ctx, s.db, sql, args)
+}
+
+// AddReplica adds a read replica of the primary database. Queries made with ReplicaQuery will be
+// distributed among the replicas. Only call this during app startup.
+func (s *DbHelper) AddReplica(replica *sql.DB) {
+	s.replicas = append(s.replicas, replica)
+}
+
+// ReplicaQuery is like Query, but sends the query to one of the read replicas, if there are any.
+// The query is sent to the primary database instead if a transaction is in progress, or if the context
+// was prepared with db.ReadYourWrites.
+//
+// Replicas may lag behind the primary, so only use this for queries that can tolerate slightly stale data.
+// The query builder uses it for Load, LoadCursor and Count.
+func (s *DbHelper) ReplicaQuery(ctx context.Context, sql string, args ...interface{}) (r *sql.Rows, err error) {
+	if len(s.replicas) == 0 ||
+		db.IsReadingYourWrites(ctx) ||
+		s.InTransaction(ctx) {
+		return s.query(ctx, s.db, sql, args)
+	}
+	i := atomic.AddUint32(&s.nextReplica, 1) % uint32(len(s.replicas))
+	return s.query(ctx, s.replicas[i], sql, args)
+}
+
+func (s *DbHelper) query(ctx context.Context, sdb *sql.DB, sql string, args []interface{}) (r *sql.Rows, err error) {
 	c := s.getContext(ctx)
 	log.Sql("Query: ", sql, args)
 
 	var beginTime = time.Now()
-	if c != nil && c.tx != nil {
-		r, err = c.tx.QueryContext(ctx, sql, args...)
+	if tx, unlock := c.lockTx(); tx != nil {
+		r, err = tx.QueryContext(ctx, sql, args...)
+		if err == nil {
+			c.openRows = append(c.openRows, r)
+		}
+		unlock()
 	} else {
-		r, err = s.db.QueryContext(ctx, sql, args...)
+		r, err = sdb.QueryContext(ctx, sql, args...)
 	}
 	var endTime = time.Now()
-	if c != nil && s.profiling {
-		if args != nil {
-			for _, arg := range args {
-				sql = strings.TrimSpace(sql)
-				sql += fmt.Sprintf(",\n%#v", arg)
-			}
-		}
-		c.profiles = append(c.profiles, ProfileEntry{DbKey: s.dbKey, BeginTime: beginTime, EndTime: endTime, Typ: "Query", Sql: sql})
-	}
 
+	s.recordQuery(ctx, c, "Query", sql, args, beginTime, endTime, err == nil)
 	return
 }
 
@@ -216,11 +429,20 @@ func (s *DbHelper) PutBlankContext(ctx context.Context) context.Context {
 }
 
 func (s *DbHelper) contextKey() goradd.ContextKey {
-	return goradd.ContextKey("goradd.sql-" + s.DbKey())
+	return sqlContextKey(s.DbKey())
+}
+
+func sqlContextKey(dbKey string) goradd.ContextKey {
+	return goradd.ContextKey("goradd.sql-" + dbKey)
 }
 
 func (s *DbHelper) getContext(ctx context.Context) *sqlContext {
-	i := ctx.Value(s.contextKey())
+	return getSqlContext(ctx, s.DbKey())
+}
+
+// getSqlContext returns the sqlContext of the database with the given key, or nil if there is none in ctx.
+func getSqlContext(ctx context.Context, dbKey string) *sqlContext {
+	i := ctx.Value(sqlContextKey(dbKey))
 	if i != nil {
 		if c, ok := i.(*sqlContext); ok {
 			return c
@@ -234,7 +456,7 @@ func (s *DbHelper) DbKey() string {
 	return s.dbKey
 }
 
-// SqlDb returns the underlying database/sql database object.
+// SqlDb returns the underlying database/sql database object of the primary database.
 func (s *DbHelper) SqlDb() *sql.DB {
 	return s.db
 }
@@ -244,15 +466,18 @@ func (s *DbHelper) StartProfiling() {
 	s.profiling = true
 }
 
-// GetProfiles returns currently collected profile information
-// TODO: Move profiles to a session variable so we can access ajax queries too
+// GetProfiles returns the profile information collected in the context, and clears it.
+// The DbProfileHandler of the application saves these to the session after each request so that the queries of
+// ajax requests can be seen too.
 func (s *DbHelper) GetProfiles(ctx context.Context) []ProfileEntry {
 	c := s.getContext(ctx)
 	if c == nil {
 		panic("Profiling requires a preloaded context.")
 	}
 
+	c.Lock()
 	p := c.profiles
 	c.profiles = nil
+	c.Unlock()
 	return p
 }
@@ -0,0 +1,132 @@
+package sql
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goradd/goradd/pkg/orm/db"
+	"github.com/goradd/goradd/pkg/orm/db/sql/sqltest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTxLogHelper() (*DbHelper, *sqltest.Conn, context.Context) {
+	c := &sqltest.Conn{}
+	h := NewSqlDb("test", c.DB())
+	return &h, c, h.PutBlankContext(context.Background())
+}
+
+func TestNestedTransactions(t *testing.T) {
+	h, l, ctx := newTxLogHelper()
+
+	tx1 := h.Begin(ctx)
+	tx2 := h.Begin(ctx)
+	assert.Equal(t, db.TransactionID(2), tx2)
+	h.Rollback(ctx, tx2) // rolls back only the nested transaction
+	tx3 := h.Begin(ctx)
+	assert.Equal(t, db.TransactionID(2), tx3)
+	h.Commit(ctx, tx3)
+	h.Rollback(ctx, tx3) // does nothing after a commit
+	h.Commit(ctx, tx1)
+	h.Rollback(ctx, tx1)
+
+	assert.Equal(t, []string{
+		"BEGIN",
+		"SAVEPOINT goradd_sp2",
+		"ROLLBACK TO SAVEPOINT goradd_sp2",
+		"SAVEPOINT goradd_sp2",
+		"RELEASE SAVEPOINT goradd_sp2",
+		"COMMIT",
+	}, l.Statements())
+}
+
+func TestNestedTransactionAborted(t *testing.T) {
+	h, l, ctx := newTxLogHelper()
+
+	tx1 := h.Begin(ctx)
+	tx2 := h.Begin(ctx)
+	// As if the database aborted the whole transaction after a deadlock
+	l.FailOn = "ROLLBACK TO"
+	h.Rollback(ctx, tx2)
+	h.Rollback(ctx, tx1)
+
+	assert.Equal(t, []string{
+		"BEGIN",
+		"SAVEPOINT goradd_sp2",
+		"ROLLBACK",
+	}, l.Statements())
+
+	assert.Panics(t, func() { h.Commit(ctx, tx1) })
+}
+
+func TestTransactionStatements(t *testing.T) {
+	h, l, ctx := newTxLogHelper()
+	txid := h.Begin(ctx)
+
+	// goroutines sharing the transaction send their statements one at a time
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := h.Exec(ctx, "UPDATE a")
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, l.Statements(), 11)
+
+	// but cannot send a statement while the rows of a query are open
+	rows, err := h.Query(ctx, "SELECT 1")
+	assert.NoError(t, err)
+	assert.Panics(t, func() { _, _ = h.Exec(ctx, "UPDATE b") })
+	assert.Panics(t, func() { _, _ = h.Query(ctx, "SELECT 2") })
+	rows.Close()
+	_, err = h.Exec(ctx, "UPDATE b")
+	assert.NoError(t, err)
+
+	h.Commit(ctx, txid)
+	assert.Equal(t, []string{"SELECT 1", "UPDATE b", "COMMIT"}, l.Statements()[11:])
+}
+
+func TestReplicaQuery(t *testing.T) {
+	h, primary, ctx := newTxLogHelper()
+	replica := &sqltest.Conn{}
+	h.AddReplica(replica.DB())
+
+	rows, _ := h.ReplicaQuery(ctx, "SELECT 1")
+	rows.Close()
+	rows, _ = h.ReplicaQuery(db.ReadYourWrites(ctx), "SELECT 2")
+	rows.Close()
+	txid := h.Begin(ctx)
+	rows, _ = h.ReplicaQuery(ctx, "SELECT 3")
+	rows.Close()
+	h.Commit(ctx, txid)
+
+	assert.Equal(t, []string{"SELECT 1"}, replica.Statements())
+	assert.Equal(t, []string{"SELECT 2", "BEGIN", "SELECT 3", "COMMIT"}, primary.Statements())
+}
+
+func TestRecordQuery(t *testing.T) {
+	h, l, ctx := newTxLogHelper()
+	h.StartProfiling()
+	h.SetSlowQueryThreshold(time.Second, true)
+	h.SetRepeatedQueryThreshold(2)
+
+	c := h.getContext(ctx)
+	begin := time.Now()
+	h.recordQuery(ctx, c, "Query", "SELECT 1", nil, begin, begin.Add(2*time.Second), true)
+	h.recordQuery(ctx, c, "Query", "SELECT 1", nil, begin, begin.Add(time.Millisecond), true)
+	h.recordQuery(ctx, c, "Exec", "SAVEPOINT a", nil, begin, begin.Add(2*time.Second), true)
+
+	p := h.GetProfiles(ctx)
+	assert.Len(t, p, 3)
+	assert.True(t, p[0].Slow)
+	assert.Equal(t, 1, p[0].Repeats)
+	assert.False(t, p[1].Slow)
+	assert.Equal(t, 2, p[1].Repeats)
+	assert.True(t, p[2].Slow)
+	assert.Equal(t, []string{"EXPLAIN SELECT 1"}, l.Statements(), "only data statements are explained")
+	assert.Empty(t, h.GetProfiles(ctx))
+}
@@ -1,7 +1,9 @@
 package sql
 
 import (
+	"context"
 	"fmt"
+	db2 "github.com/goradd/goradd/pkg/orm/db"
 	. "github.com/goradd/goradd/pkg/orm/query"
 	"github.com/goradd/goradd/pkg/stringmap"
 	"strings"
@@ -11,21 +13,52 @@ type operationSqler interface {
 	OperationSql(op Operator, operandStrings []string) string
 }
 
+// fullTextSqler is implemented by databases that support full text searches.
+type fullTextSqler interface {
+	// FullTextSql returns the sql of a full text search of textSql in the given columns of table.
+	// columnSql is the sql of each column. If score is true, the sql returns the relevance of the columns
+	// to the search, and otherwise it tests whether the columns match it.
+	FullTextSql(score bool, table string, columns []string, columnSql []string, textSql string) string
+}
+
+// modeler is implemented by databases that have a description of their tables.
+type modeler interface {
+	Model() *db2.Model
+}
+
 type deleteUsesAliaser interface {
 	DeleteUsesAlias() bool
 }
 
+// columnConverter is implemented by databases that store some columns in a form that is different from
+// the value goradd uses for the column, like the native enum types of Postgres.
+type columnConverter interface {
+	// ColumnReadSql returns the sql that converts the column in columnSql to the value goradd uses.
+	ColumnReadSql(table string, column string, columnSql string) string
+	// ColumnWriteSql returns the sql that converts the goradd value in valueSql to a value that can be stored in the column.
+	ColumnWriteSql(table string, column string, valueSql string) string
+}
+
+// columnWriteSql returns the sql that stores the value in valueSql in the given column.
+func columnWriteSql(db any, table string, column string, valueSql string) string {
+	if c, ok := db.(columnConverter); ok {
+		return c.ColumnWriteSql(table, column, valueSql)
+	}
+	return valueSql
+}
+
 // selectGenerator is an aid to generating various sql statements.
 // SQL dialects are similar, but have small variations. This object
 // attempts to handle the major issues, while allowing individual
 // implementations of SQL to do their own tweaks.
 type selectGenerator struct {
 	b       *Builder
+	ctx     context.Context
 	argList []any
 }
 
 func newSelectGenerator(builder *Builder) *selectGenerator {
-	return &selectGenerator{b: builder}
+	return &selectGenerator{b: builder, ctx: builder.Ctx}
 }
 
 func (g *selectGenerator) iq(v string) string {
@@ -38,10 +71,11 @@ func (g *selectGenerator) addArg(v any) string {
 }
 
 func (g *selectGenerator) generateSelectSql() (sql string) {
+	sql = g.generateWithSql()
 	if g.b.IsDistinct {
-		sql = "SELECT DISTINCT\n"
+		sql += "SELECT DISTINCT\n"
 	} else {
-		sql = "SELECT\n"
+		sql += "SELECT\n"
 	}
 
 	sql += g.generateColumnListWithAliases()
@@ -55,6 +89,9 @@ func (g *selectGenerator) generateSelectSql() (sql string) {
 }
 
 func (g *selectGenerator) generateDeleteSql() (sql string) {
+	if len(g.b.ctes) > 0 {
+		panic("common table expressions can only be used in queries that load or count records")
+	}
 	if t, ok := g.b.db.(deleteUsesAliaser); ok && t.DeleteUsesAlias() {
 		j := g.b.RootJoinTreeItem
 		alias := g.iq(j.Alias)
@@ -71,6 +108,58 @@ func (g *selectGenerator) generateDeleteSql() (sql string) {
 	return
 }
 
+// generateUpdateSql generates an update of the root table of the query.
+// Databases that can join tables in a delete, like MySQL, can also join them in an update. Others
+// select the records to update with a subquery.
+func (g *selectGenerator) generateUpdateSql() (sql string) {
+	if len(g.b.ctes) > 0 {
+		panic("common table expressions can only be used in queries that load or count records")
+	}
+	j := g.b.RootJoinTreeItem
+	alias := g.iq(j.Alias)
+	hasJoins := len(j.ChildReferences) > 0
+	var multiTable bool
+	if t, ok := g.b.db.(deleteUsesAliaser); ok && hasJoins {
+		multiTable = t.DeleteUsesAlias()
+	}
+
+	sql = "UPDATE " + g.iq(NodeTableName(j.Node)) + " AS " + alias + "\n"
+	if multiTable {
+		for _, child := range j.ChildReferences {
+			sql += g.generateJoinSql(child)
+		}
+	}
+
+	var sets []string
+	for _, f := range g.b.updateFields {
+		col := ColumnNodeDbName(f.column)
+		s := g.iq(col)
+		if multiTable {
+			s = alias + "." + s
+		}
+		var v string
+		if n, ok := f.value.(NodeI); ok {
+			v = g.generateNodeSql(n, false)
+		} else {
+			v = g.addArg(f.value)
+		}
+		s += "=" + columnWriteSql(g.b.db, NodeTableName(j.Node), col, v)
+		sets = append(sets, s)
+	}
+	sql += "SET " + strings.Join(sets, ", ") + "\n"
+
+	if hasJoins && !multiTable {
+		pk := alias + "." + g.iq(ColumnNodeDbName(j.Node.(TableNodeI).PrimaryKeyNode()))
+		sql += "WHERE " + pk + " IN (SELECT " + pk + "\n"
+		sql += g.generateFromSql()
+		sql += g.generateWhereSql()
+		sql += ")\n"
+	} else {
+		sql += g.generateWhereSql()
+	}
+	return
+}
+
 func (g *selectGenerator) generateColumnListWithAliases() (sql string) {
 	g.b.ColumnAliases.Range(func(key string, j *JoinTreeItem) bool {
 		sql += g.generateColumnNodeSql(j.Parent.Alias, j.Node) + " AS " + g.iq(key) + ",\n"
@@ -99,7 +188,12 @@ func (g *selectGenerator) generateColumnListWithAliases() (sql string) {
 
 // Generate the column node sql.
 func (g *selectGenerator) generateColumnNodeSql(parentAlias string, node NodeI) (sql string) {
-	return g.iq(parentAlias) + "." + g.iq(ColumnNodeDbName(node.(*ColumnNode)))
+	col := ColumnNodeDbName(node.(*ColumnNode))
+	sql = g.iq(parentAlias) + "." + g.iq(col)
+	if c, ok := g.b.db.(columnConverter); ok {
+		sql = c.ColumnReadSql(NodeTableName(node), col, sql)
+	}
+	return
 }
 
 func (g *selectGenerator) generateNodeSql(n NodeI, useAlias bool) (sql string) {
@@ -137,6 +231,14 @@ func (g *selectGenerator) generateNodeSql(n NodeI, useAlias bool) (sql string) {
 
 	case *SubqueryNode:
 		sql = g.generateSubquerySql(node)
+	case *WindowNode:
+		sql = g.generateWindowSql(node, useAlias)
+	case *CteColumnNode:
+		if useAlias && node.GetAlias() != "" {
+			sql = g.iq(node.GetAlias())
+		} else {
+			sql = g.iq(CteName(CteColumnNodeCte(node))) + "." + g.iq(CteColumnNodeName(node))
+		}
 	case TableNodeI:
 		tj := g.b.GetItemFromNode(node)
 		sql = g.generateColumnNodeSql(tj.Alias, node.PrimaryKeyNode())
@@ -146,6 +248,27 @@ func (g *selectGenerator) generateNodeSql(n NodeI, useAlias bool) (sql string) {
 	return
 }
 
+// generateMatchSql generates the sql of a full text search. The first operand is the search text,
+// and the rest are the columns to search.
+func (g *selectGenerator) generateMatchSql(n *OperationNode, operands []string) string {
+	f, ok := g.b.db.(fullTextSqler)
+	if !ok {
+		panic("full text search is not implemented in this database")
+	}
+	var table string
+	var columns []string
+	for _, o := range OperationNodeOperands(n)[1:] {
+		c := o.(*ColumnNode)
+		if table == "" {
+			table = NodeTableName(c)
+		} else if NodeTableName(c) != table {
+			panic("the columns of a full text search must all be in the same table")
+		}
+		columns = append(columns, ColumnNodeDbName(c))
+	}
+	return f.FullTextSql(OperationNodeOperator(n) == OpMatchScore, table, columns, operands[1:], operands[0])
+}
+
 func (g *selectGenerator) generateOperationSql(n *OperationNode, useAlias bool) (sql string) {
 	if useAlias && n.GetAlias() != "" {
 		sql = g.iq(n.GetAlias())
@@ -159,6 +282,10 @@ func (g *selectGenerator) generateOperationSql(n *OperationNode, useAlias bool)
 		operands = append(operands, g.generateNodeSql(o, useAlias))
 	}
 
+	if operator == OpMatch || operator == OpMatchScore {
+		return g.generateMatchSql(n, operands)
+	}
+
 	if o, ok := g.b.db.(operationSqler); ok {
 		sql = o.OperationSql(OperationNodeOperator(n), operands)
 		if sql != "" {
@@ -234,6 +361,12 @@ func (g *selectGenerator) generateOperationSql(n *OperationNode, useAlias bool)
 			s2 := operands[1]
 			sql = fmt.Sprintf(`DATE_ADD(%s, INTERVAL (%s) SECOND)`, s, s2)
 		*/
+	case OpJsonExtract:
+		panic("JsonExtract is not implemented in this database")
+	case OpJsonContains:
+		panic("JsonContains is not implemented in this database")
+	case OpArrayAny, OpArrayAll, OpArrayOverlaps, OpArrayContains:
+		panic(operator.String() + " is not implemented in this database")
 	case OpXor:
 		// Some sqls do not have an XOR operator, so we manually implement the code here
 		// Override in the database implementation if XOR is implemented
@@ -253,11 +386,97 @@ func (g *selectGenerator) generateAlias(alias string) (sql string) {
 }
 
 func (g *selectGenerator) generateSubquerySql(node *SubqueryNode) (sql string) {
+	sql = g.generateSubquerySelectSql(SubqueryBuilder(node).(*Builder))
+	sql = "(" + sql + ")"
+	return
+}
+
+// generateSubquerySelectSql generates the select statement of a query that is a part of the current query.
+func (g *selectGenerator) generateSubquerySelectSql(b *Builder) (sql string) {
 	// The copy below intentionally reuses the argList and db items
 	g2 := *g
-	g2.b = SubqueryBuilder(node).(*Builder)
+	g2.b = b
 	sql = g2.generateSelectSql()
-	sql = "(" + sql + ")"
+	g.argList = g2.argList
+	return
+}
+
+// generateWindowSql generates a window function, like ROW_NUMBER() OVER (PARTITION BY ... ORDER BY ...).
+func (g *selectGenerator) generateWindowSql(n *WindowNode, useAlias bool) (sql string) {
+	if useAlias && n.GetAlias() != "" {
+		return g.iq(n.GetAlias())
+	}
+
+	var clauses []string
+	if nodes := WindowNodePartitionBys(n); len(nodes) > 0 {
+		var items []string
+		for _, o := range nodes {
+			items = append(items, g.generateNodeSql(o, false))
+		}
+		clauses = append(clauses, "PARTITION BY "+strings.Join(items, ","))
+	}
+	if nodes := WindowNodeOrderBys(n); len(nodes) > 0 {
+		var items []string
+		for _, o := range nodes {
+			s := g.generateNodeSql(o, false)
+			if sorter, ok := o.(NodeSorter); ok {
+				if NodeSorterSortDesc(sorter) {
+					s += " DESC"
+				}
+			}
+			items = append(items, s)
+		}
+		clauses = append(clauses, "ORDER BY "+strings.Join(items, ","))
+	}
+	sql = strings.TrimSpace(g.generateNodeSql(WindowNodeFunction(n), false))
+	sql += " OVER (" + strings.Join(clauses, " ") + ") "
+	return
+}
+
+// generateWithSql generates the WITH clause that defines the common table expressions used by the query.
+func (g *selectGenerator) generateWithSql() (sql string) {
+	if len(g.b.ctes) == 0 {
+		return
+	}
+
+	var recursive bool
+	var defs []string
+	for _, c := range g.b.ctes {
+		builders := CteBuilders(c)
+		anchor := builders[0].(*Builder)
+
+		var columns []string
+		anchor.ColumnAliases.Range(func(_ string, j *JoinTreeItem) bool {
+			columns = append(columns, g.iq(ColumnNodeDbName(j.Node.(*ColumnNode))))
+			return true
+		})
+		if anchor.AliasNodes != nil {
+			for _, key := range anchor.AliasNodes.Keys() {
+				columns = append(columns, g.iq(key))
+			}
+		}
+
+		s := g.iq(CteName(c)) + " (" + strings.Join(columns, ",") + ") AS (\n"
+		s += g.generateSubquerySelectSql(anchor)
+		if CteIsRecursive(c) {
+			recursive = true
+			if CteUnionAll(c) {
+				s += "UNION ALL\n"
+			} else {
+				s += "UNION\n"
+			}
+			s += g.generateSubquerySelectSql(builders[1].(*Builder))
+		}
+		s += ")"
+		defs = append(defs, s)
+	}
+
+	if recursive {
+		sql = "WITH RECURSIVE "
+	} else {
+		sql = "WITH "
+	}
+	sql += strings.Join(defs, ",\n") + "\n"
 	return
 }
 
@@ -270,6 +489,9 @@ func (g *selectGenerator) generateFromSql() (sql string) {
 	for _, child := range j.ChildReferences {
 		sql += g.generateJoinSql(child)
 	}
+	for _, c := range g.b.cteRefs {
+		sql += "CROSS JOIN " + g.iq(CteName(c)) + "\n"
+	}
 	return
 }
 
@@ -287,6 +509,9 @@ func (g *selectGenerator) generateJoinSql(j *JoinTreeItem) (sql string) {
 		sql += g.iq(ReferenceNodeRefTable(node)) + " AS " +
 			g.iq(j.Alias) + " ON " + g.iq(j.Parent.Alias) + "." +
 			g.iq(ReferenceNodeDbColumnName(node)) + " = " + g.iq(j.Alias) + "." + g.iq(ReferenceNodeRefColumn(node))
+		if s := g.generateFilterSql(j); s != "" {
+			sql += " AND " + s
+		}
 		if j.JoinCondition != nil {
 			s := g.generateNodeSql(j.JoinCondition, false)
 			sql += " AND " + s
@@ -300,6 +525,9 @@ func (g *selectGenerator) generateJoinSql(j *JoinTreeItem) (sql string) {
 		sql += g.iq(ReverseReferenceNodeRefTable(node)) + " AS " +
 			g.iq(j.Alias) + " ON " + g.iq(j.Parent.Alias) + "." +
 			g.iq(ReverseReferenceNodeKeyColumnName(node)) + " = " + g.iq(j.Alias) + "." + g.iq(ReverseReferenceNodeRefColumn(node))
+		if s := g.generateFilterSql(j); s != "" {
+			sql += " AND " + s
+		}
 		if j.JoinCondition != nil {
 			s := g.generateNodeSql(j.JoinCondition, false)
 			sql += " AND " + s
@@ -318,6 +546,9 @@ func (g *selectGenerator) generateJoinSql(j *JoinTreeItem) (sql string) {
 		sql += "LEFT JOIN " + g.iq(ManyManyNodeRefTable(node)) + " AS " + g.iq(j.Alias) +
 			" ON " + g.iq(j.Alias+"a") + "." + g.iq(ManyManyNodeRefColumn(node)) +
 			" = " + g.iq(j.Alias) + "." + g.iq(ManyManyNodeRefPk(node))
+		if s := g.generateFilterSql(j); s != "" {
+			sql += " AND " + s
+		}
 
 		if j.JoinCondition != nil {
 			s := g.generateNodeSql(j.JoinCondition, false)
@@ -335,15 +566,81 @@ func (g *selectGenerator) generateJoinSql(j *JoinTreeItem) (sql string) {
 }
 
 func (g *selectGenerator) generateWhereSql() (sql string) {
+	t := g.generateFilterSql(g.b.RootJoinTreeItem)
 	if g.b.ConditionNode != nil {
 		sql = "WHERE "
 		var s string
 		s = g.generateNodeSql(g.b.ConditionNode, false)
-		sql += s + "\n"
+		if t != "" {
+			sql += t + " AND (" + s + ")\n"
+		} else {
+			sql += s + "\n"
+		}
+	} else if t != "" {
+		sql = "WHERE " + t + "\n"
 	}
 	return
 }
 
+// generateFilterSql returns the conditions that the model of the database puts on the table of the join tree item,
+// which limit it to the records of the tenant in the context, and leave out records that are marked as deleted.
+func (g *selectGenerator) generateFilterSql(j *JoinTreeItem) string {
+	var conditions []string
+	if s := g.generateTenantSql(j); s != "" {
+		conditions = append(conditions, s)
+	}
+	if s := g.generateSoftDeleteSql(j); s != "" {
+		conditions = append(conditions, s)
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+// generateSoftDeleteSql returns the condition that leaves out the records of the table of the join tree item that are
+// marked as deleted, or an empty string if the table does not have a soft delete column, or the query includes
+// deleted records.
+func (g *selectGenerator) generateSoftDeleteSql(j *JoinTreeItem) string {
+	if g.b.IsWithDeleted {
+		return ""
+	}
+	m, ok := g.b.db.(modeler)
+	if !ok {
+		return ""
+	}
+	t := m.Model().Table(NodeTableName(j.Node))
+	if t == nil {
+		return ""
+	}
+	col := t.SoftDeleteColumn()
+	if col == nil {
+		return ""
+	}
+	return g.iq(j.Alias) + "." + g.iq(col.DbName) + " IS NULL"
+}
+
+// generateTenantSql returns the condition that limits the table of the join tree item to the records of the tenant
+// in the context, or an empty string if the table does not have a tenant column, or the context is
+// prepared with db.WithoutTenant.
+func (g *selectGenerator) generateTenantSql(j *JoinTreeItem) string {
+	m, ok := g.b.db.(modeler)
+	if !ok {
+		return ""
+	}
+	tableName := NodeTableName(j.Node)
+	t := m.Model().Table(tableName)
+	if t == nil {
+		return ""
+	}
+	col := t.TenantColumn()
+	if col == nil || db2.IsWithoutTenant(g.ctx) {
+		return ""
+	}
+	tenant := db2.Tenant(g.ctx)
+	if tenant == nil {
+		panic(fmt.Sprintf("the %s table requires a tenant. Use db.WithTenant to set the tenant of the query, or db.WithoutTenant to query all tenants", tableName))
+	}
+	return g.iq(j.Alias) + "." + g.iq(col.DbName) + " = " + g.addArg(tenant)
+}
+
 func (g *selectGenerator) generateGroupBySql() (sql string) {
 	if g.b.GroupBys != nil && len(g.b.GroupBys) > 0 {
 		sql = "GROUP BY "
@@ -404,6 +701,12 @@ func (g *selectGenerator) generateOrderBySql() (sql string) {
 
 // GenerateUpdate is a helper function for database implementations to generate an update statement.
 func GenerateUpdate(db DbI, table string, fields map[string]any, pkName string, pkValue any) (sql string, args []any) {
+	return GenerateLockedUpdate(db, table, fields, pkName, pkValue, "", nil)
+}
+
+// GenerateLockedUpdate generates an update statement that will only change the record if the optimistic
+// lock column named lockName still has the value lockValue. If lockName is empty, it is the same as GenerateUpdate.
+func GenerateLockedUpdate(db DbI, table string, fields map[string]any, pkName string, pkValue any, lockName string, lockValue any) (sql string, args []any) {
 	if len(fields) == 0 {
 		panic("No fields to set")
 	}
@@ -416,7 +719,7 @@ func GenerateUpdate(db DbI, table string, fields map[string]any, pkName string,
 	// statement by making sure the same fields show up in the same order.
 	stringmap.Range(fields, func(k string, v any) bool {
 		args = append(args, v)
-		s := fmt.Sprintf("%s=%s", db.QuoteIdentifier(k), db.FormatArgument(len(args)))
+		s := fmt.Sprintf("%s=%s", db.QuoteIdentifier(k), columnWriteSql(db, table, k, db.FormatArgument(len(args))))
 		sets = append(sets, s)
 		return true
 	})
@@ -425,6 +728,11 @@ func GenerateUpdate(db DbI, table string, fields map[string]any, pkName string,
 	args = append(args, pkValue)
 	sql += "\nWHERE " + db.QuoteIdentifier(pkName) +
 		fmt.Sprintf(" = %s", db.FormatArgument(len(args)))
+	if lockName != "" {
+		args = append(args, lockValue)
+		sql += " AND " + db.QuoteIdentifier(lockName) +
+			fmt.Sprintf(" = %s", db.FormatArgument(len(args)))
+	}
 
 	return
 }
@@ -443,7 +751,7 @@ func GenerateInsert(db DbI, table string, fields map[string]any) (sql string, ar
 	stringmap.Range(fields, func(k string, v any) bool {
 		keys = append(keys, db.QuoteIdentifier(k))
 		args = append(args, v)
-		values = append(values, db.FormatArgument(len(args)))
+		values = append(values, columnWriteSql(db, table, k, db.FormatArgument(len(args))))
 		return true
 	})
 
@@ -0,0 +1,622 @@
+package sql
+
+import (
+	"fmt"
+	"github.com/goradd/goradd/pkg/orm/db"
+	"github.com/goradd/goradd/pkg/stringmap"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// MigrationDialect supplies the database specific SQL used by GenerateMigration.
+// Each SQL database driver that supports migrations implements one.
+type MigrationDialect interface {
+	// QuoteIdentifier surrounds the given table, column, index or constraint name with quote characters.
+	QuoteIdentifier(v string) string
+	// LiteralSql returns the value as a SQL literal that can be embedded in a statement.
+	LiteralSql(v interface{}) string
+	// ColumnSql returns the parts of the definition of a column that are compared to detect changes.
+	ColumnSql(col db.ColumnDescription) ColumnSql
+	// CreateTableSql returns the statements that create a table, including its primary key and comments,
+	// but not its indexes or foreign keys.
+	CreateTableSql(t db.TableDescription) []string
+	// AddColumnSql returns the statements that add a column to an existing table.
+	AddColumnSql(table string, col db.ColumnDescription) []string
+	// AlterColumnSql returns the statements that change a column from one definition to another.
+	AlterColumnSql(table string, from, to db.ColumnDescription) []string
+	// AlterPrimaryKeySql returns the statements that change the primary key of a table. Either list of columns may be empty.
+	AlterPrimaryKeySql(table string, from, to []string) []string
+	// TableCommentSql returns the statements that set the comment on a table.
+	TableCommentSql(table string, comment string) []string
+	// DropForeignKeySql returns the statements that drop the named foreign key constraint.
+	DropForeignKeySql(table string, name string) []string
+	// DropIndexSql returns the statements that drop the named index.
+	DropIndexSql(table string, name string) []string
+}
+
+// NativeEnumDialect is implemented by the MigrationDialect of databases that have native enum types, like Postgres.
+// Enum tables that are native enum types are ignored when migrating databases that do not implement it.
+type NativeEnumDialect interface {
+	// CreateEnumTypeSql returns the statements that create an enum type with the given labels, in order.
+	CreateEnumTypeSql(name string, labels []string) []string
+	// AddEnumValueSql returns the statements that add a label to the end of an enum type.
+	AddEnumValueSql(name string, label string) []string
+	// DropEnumTypeSql returns the statements that drop an enum type.
+	DropEnumTypeSql(name string) []string
+}
+
+// FullTextIndexDialect is implemented by the MigrationDialect of databases that support full text search indexes.
+// Full text indexes are not created in databases that do not implement it.
+type FullTextIndexDialect interface {
+	// CreateFullTextIndexSql returns the statements that create the full text index named name on the given table.
+	CreateFullTextIndexSql(table string, name string, idx db.IndexDescription) []string
+}
+
+// ColumnSql is the SQL that defines a column, broken into the parts that can be changed separately.
+type ColumnSql struct {
+	// Type is the native type of the column
+	Type string
+	// NotNull is true if the column cannot hold a NULL
+	NotNull bool
+	// Default is the literal default value, or an empty string if the column has no default
+	Default string
+	// AutoIncrement is true if the database generates the values of the column
+	AutoIncrement bool
+	// Comment is the comment on the column, including its options
+	Comment string
+}
+
+// migrationTable is a table being compared by GenerateMigration.
+type migrationTable struct {
+	db.TableDescription
+	isAssn bool
+}
+
+type columnKey struct {
+	table  string
+	column string
+}
+
+// GenerateMigration compares two database descriptions and returns the ordered list of SQL statements
+// that will change a database with the structure described by from so that it has the structure described by to.
+//
+// Typically, from is the description of a live database, and to is read from a schema file. Tables and columns are
+// matched by name, so renaming a table or column will result in it being dropped and created again, losing its data.
+// Association tables are generated from the many-many descriptions, and the data of enum tables is kept in sync
+// with EnumData. Changes to the structure of an association table will drop and recreate it.
+//
+// Statements are ordered so that foreign keys and indexes are dropped before the columns and tables they depend on,
+// and created after them.
+//
+// Native enum types are created before the tables that use them, and dropped after. Values are only ever
+// added to the end of an existing native enum type, since databases generally cannot remove them.
+func GenerateMigration(d MigrationDialect, from, to db.DatabaseDescription) (stmts []string) {
+	fromTables := migrationTables(from)
+	toTables := migrationTables(to)
+	fromEnums := nativeEnums(from)
+	toEnums := nativeEnums(to)
+	enumDialect, _ := d.(NativeEnumDialect)
+
+	// Association tables cannot be altered in place, since the describers do not retain the names of their constraints.
+	for _, name := range stringmap.SortedKeys(fromTables) {
+		ft := fromTables[name]
+		if tt, ok := toTables[name]; ok && ft.isAssn && !sameAssnTable(d, ft, tt) {
+			delete(fromTables, name)
+			stmts = append(stmts, "DROP TABLE "+d.QuoteIdentifier(name))
+		}
+	}
+
+	fromNames := stringmap.SortedKeys(fromTables)
+	toNames := stringmap.SortedKeys(toTables)
+
+	// Drop foreign keys that are going away, or whose columns are changing
+	droppedFks := make(map[columnKey]bool)
+	for _, name := range fromNames {
+		ft := fromTables[name]
+		tt := toTables[name]
+		if tt == nil && ft.isAssn {
+			continue // nothing refers to association tables, so dropping the table will take care of them
+		}
+		for _, fc := range ft.Columns {
+			if fc.ForeignKey == nil {
+				continue
+			}
+			var tc *db.ColumnDescription
+			if tt != nil {
+				tc = tt.column(fc.Name)
+			}
+			if tc == nil || tc.ForeignKey == nil ||
+				!sameForeignKey(fromTables, fc.ForeignKey, toTables, tc.ForeignKey) ||
+				d.ColumnSql(fc).Type != d.ColumnSql(*tc).Type ||
+				referenceChanged(d, fromTables, fc.ForeignKey, toTables) {
+				stmts = append(stmts, d.DropForeignKeySql(name, fkConstraintName(name, fc))...)
+				droppedFks[columnKey{name, fc.Name}] = true
+			}
+		}
+	}
+
+	// Drop indexes that are going away
+	for _, name := range fromNames {
+		ft := fromTables[name]
+		tt := toTables[name]
+		if tt == nil {
+			continue
+		}
+		toIndexes := tt.indexes()
+		for _, idx := range ft.indexes() {
+			if findIndex(toIndexes, idx) >= 0 || isForeignKeyIndex(tt, idx) {
+				continue
+			}
+			stmts = append(stmts, d.DropIndexSql(name, indexName(name, idx))...)
+		}
+	}
+
+	// Drop tables that are going away. Association tables go first, since they refer to the other tables.
+	for _, assn := range []bool{true, false} {
+		for _, name := range fromNames {
+			if _, ok := toTables[name]; !ok && fromTables[name].isAssn == assn {
+				stmts = append(stmts, "DROP TABLE "+d.QuoteIdentifier(name))
+			}
+		}
+	}
+
+	// Create new native enum types, and add new values to existing ones
+	if enumDialect != nil {
+		for _, name := range stringmap.SortedKeys(toEnums) {
+			if fromLabels, ok := fromEnums[name]; !ok {
+				stmts = append(stmts, enumDialect.CreateEnumTypeSql(name, toEnums[name])...)
+			} else {
+				for _, label := range toEnums[name] {
+					if !slices.Contains(fromLabels, label) {
+						stmts = append(stmts, enumDialect.AddEnumValueSql(name, label)...)
+					}
+				}
+			}
+		}
+	}
+
+	// Create new tables
+	for _, name := range toNames {
+		if _, ok := fromTables[name]; !ok {
+			stmts = append(stmts, d.CreateTableSql(toTables[name].TableDescription)...)
+		}
+	}
+
+	// Alter tables that are changing
+	for _, name := range toNames {
+		ft := fromTables[name]
+		tt := toTables[name]
+		if ft == nil {
+			continue
+		}
+		for _, tc := range tt.Columns {
+			if fc := ft.column(tc.Name); fc == nil {
+				stmts = append(stmts, d.AddColumnSql(name, tc)...)
+			} else if d.ColumnSql(*fc) != d.ColumnSql(tc) {
+				stmts = append(stmts, d.AlterColumnSql(name, *fc, tc)...)
+			}
+		}
+		if fromPk, toPk := ft.pkNames(), tt.pkNames(); strings.Join(fromPk, ",") != strings.Join(toPk, ",") {
+			stmts = append(stmts, d.AlterPrimaryKeySql(name, fromPk, toPk)...)
+		}
+		for _, fc := range ft.Columns {
+			if tt.column(fc.Name) == nil {
+				stmts = append(stmts, "ALTER TABLE "+d.QuoteIdentifier(name)+" DROP COLUMN "+d.QuoteIdentifier(fc.Name))
+			}
+		}
+		if fromComment, toComment := CommentWithOptions(ft.Comment, ft.Options), CommentWithOptions(tt.Comment, tt.Options); fromComment != toComment {
+			stmts = append(stmts, d.TableCommentSql(name, toComment)...)
+		}
+	}
+
+	// Drop native enum types that are going away, now that nothing uses them
+	if enumDialect != nil {
+		for _, name := range stringmap.SortedKeys(fromEnums) {
+			if _, ok := toEnums[name]; !ok {
+				stmts = append(stmts, enumDialect.DropEnumTypeSql(name)...)
+			}
+		}
+	}
+
+	// Synchronize the data in enum tables
+	for _, name := range toNames {
+		tt := toTables[name]
+		if tt.EnumData == nil {
+			continue
+		}
+		var fromData []map[string]interface{}
+		if ft, ok := fromTables[name]; ok {
+			fromData = ft.EnumData
+		}
+		stmts = append(stmts, enumDataSql(d, tt.TableDescription, fromData)...)
+	}
+
+	// Create new indexes
+	for _, name := range toNames {
+		tt := toTables[name]
+		var fromIndexes []db.IndexDescription
+		if ft, ok := fromTables[name]; ok {
+			fromIndexes = ft.indexes()
+		}
+		for _, idx := range tt.indexes() {
+			if findIndex(fromIndexes, idx) >= 0 {
+				continue
+			}
+			if idx.IsFullText {
+				if ftDialect, ok := d.(FullTextIndexDialect); ok {
+					stmts = append(stmts, ftDialect.CreateFullTextIndexSql(name, indexName(name, idx), idx)...)
+				}
+				continue
+			}
+			s := "CREATE "
+			if idx.IsUnique {
+				s += "UNIQUE "
+			}
+			s += "INDEX " + d.QuoteIdentifier(indexName(name, idx)) + " ON " + d.QuoteIdentifier(name) + " (" + quoteList(d, idx.ColumnNames) + ")"
+			stmts = append(stmts, s)
+		}
+	}
+
+	// Create new foreign keys
+	for _, name := range toNames {
+		ft := fromTables[name]
+		tt := toTables[name]
+		for _, tc := range tt.Columns {
+			if tc.ForeignKey == nil {
+				continue
+			}
+			if ft != nil && !droppedFks[columnKey{name, tc.Name}] {
+				if fc := ft.column(tc.Name); fc != nil && fc.ForeignKey != nil {
+					continue // unchanged
+				}
+			}
+			stmts = append(stmts, foreignKeySql(d, toTables, name, tc))
+		}
+	}
+	return
+}
+
+// migrationTables returns the tables of the description, including the association tables, keyed by name.
+// Native enum types are not tables, so they are left out, and the columns that use them do not refer to them with foreign keys.
+// The defaults of those columns are converted to labels.
+func migrationTables(desc db.DatabaseDescription) map[string]*migrationTable {
+	desc = db.WithHistoryTables(desc)
+	tables := make(map[string]*migrationTable, len(desc.Tables)+len(desc.MM))
+	enums := nativeEnums(desc)
+	for _, t := range desc.Tables {
+		if t.IsNativeEnum {
+			continue
+		}
+		t.Columns = append([]db.ColumnDescription(nil), t.Columns...)
+		for i, c := range t.Columns {
+			if c.ForeignKey == nil {
+				continue
+			}
+			if c.ForeignKey.ReferencedDatabase != "" {
+				// the database cannot enforce a reference to a table in another database
+				t.Columns[i].ForeignKey = nil
+				continue
+			}
+			if labels, ok := enums[c.ForeignKey.ReferencedTable]; ok {
+				t.Columns[i].ForeignKey = nil
+				// the default of the column is the label of the value rather than its id
+				if id, ok2 := c.DefaultValue.(int); ok2 && id > 0 && id <= len(labels) {
+					t.Columns[i].DefaultValue = labels[id-1]
+				}
+			}
+		}
+		tables[t.Name] = &migrationTable{TableDescription: t}
+	}
+	for _, mm := range desc.MM {
+		t1 := tables[mm.Table1]
+		t2 := tables[mm.Table2]
+		if t1 == nil || t2 == nil || t1.isAssn || t2.isAssn || len(t1.pkNames()) == 0 || len(t2.pkNames()) == 0 {
+			continue
+		}
+		tables[mm.AssnTableName] = &migrationTable{
+			TableDescription: db.TableDescription{
+				Name: mm.AssnTableName,
+				Columns: []db.ColumnDescription{
+					assnColumn(mm.Column1, t1, mm.GoName1, mm.GoPlural1),
+					assnColumn(mm.Column2, t2, mm.GoName2, mm.GoPlural2),
+				},
+				Indexes:             []db.IndexDescription{{ColumnNames: []string{mm.Column2}}},
+				SupportsForeignKeys: mm.SupportsForeignKeys,
+			},
+			isAssn: true,
+		}
+	}
+	return tables
+}
+
+// nativeEnums returns the labels of the native enum types of the description, in order, keyed by the name of the type.
+func nativeEnums(desc db.DatabaseDescription) map[string][]string {
+	enums := make(map[string][]string)
+	for _, t := range desc.Tables {
+		if !t.IsNativeEnum || len(t.Columns) < 2 {
+			continue
+		}
+		rows := append([]map[string]interface{}(nil), t.EnumData...)
+		key := t.Columns[0].Name
+		sort.SliceStable(rows, func(i, j int) bool {
+			a, _ := rows[i][key].(int)
+			b, _ := rows[j][key].(int)
+			return a < b
+		})
+		labels := []string{}
+		for _, row := range rows {
+			label, _ := row[t.Columns[1].Name].(string)
+			labels = append(labels, label)
+		}
+		enums[t.Name] = labels
+	}
+	return enums
+}
+
+// assnColumn returns the description of a column in an association table that points to the given table.
+func assnColumn(name string, ref *migrationTable, goName string, goPlural string) db.ColumnDescription {
+	pk := ref.Columns[0]
+	c := db.ColumnDescription{
+		Name:          name,
+		NativeType:    pk.NativeType,
+		GoType:        pk.GoType,
+		MaxCharLength: pk.MaxCharLength,
+		IsPk:          true,
+		ForeignKey: &db.ForeignKeyDescription{
+			ReferencedTable:  ref.Name,
+			ReferencedColumn: pk.Name,
+			UpdateAction:     db.FKActionCascade,
+			DeleteAction:     db.FKActionCascade,
+		},
+	}
+	if pk.IsId && c.GoType == "string" {
+		c.GoType = "int" // ids are reported as strings, but are stored as integers
+	}
+	if goName != "" || goPlural != "" {
+		c.Options = make(map[string]interface{})
+		if goName != "" {
+			c.Options[db.GoNameOption] = goName
+		}
+		if goPlural != "" {
+			c.Options[db.GoPluralOption] = goPlural
+		}
+	}
+	return c
+}
+
+func sameAssnTable(d MigrationDialect, from, to *migrationTable) bool {
+	if !to.isAssn || len(from.Columns) != len(to.Columns) {
+		return false
+	}
+	for i := range from.Columns {
+		fc := from.Columns[i]
+		tc := to.Columns[i]
+		if fc.Name != tc.Name ||
+			fc.ForeignKey.ReferencedTable != tc.ForeignKey.ReferencedTable ||
+			d.ColumnSql(fc).Type != d.ColumnSql(tc).Type {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *migrationTable) column(name string) *db.ColumnDescription {
+	for i := range t.Columns {
+		if t.Columns[i].Name == name {
+			return &t.Columns[i]
+		}
+	}
+	return nil
+}
+
+func (t *migrationTable) pkNames() (names []string) {
+	for _, c := range t.Columns {
+		if c.IsPk {
+			names = append(names, c.Name)
+		}
+	}
+	return
+}
+
+// indexes returns the indexes of the table, adding an index for each column that is marked as unique
+// but does not already have a unique index.
+func (t *migrationTable) indexes() []db.IndexDescription {
+	indexes := t.Indexes
+	for _, c := range t.Columns {
+		if c.IsUnique && !c.IsPk {
+			idx := db.IndexDescription{IsUnique: true, ColumnNames: []string{c.Name}}
+			if findIndex(indexes, idx) < 0 {
+				indexes = append(indexes, idx)
+			}
+		}
+	}
+	return indexes
+}
+
+// findIndex returns the position of an index in indexes with the same columns, uniqueness and kind as idx, or -1 if none is found.
+func findIndex(indexes []db.IndexDescription, idx db.IndexDescription) int {
+	for i, idx2 := range indexes {
+		if idx2.IsUnique == idx.IsUnique &&
+			idx2.IsFullText == idx.IsFullText &&
+			sameColumnNames(idx2.ColumnNames, idx.ColumnNames) {
+			return i
+		}
+	}
+	return -1
+}
+
+// isForeignKeyIndex returns true if the index was created by the database to support a foreign key in the table.
+// MySQL does this automatically, naming the index after the constraint.
+func isForeignKeyIndex(t *migrationTable, idx db.IndexDescription) bool {
+	if idx.IsUnique || len(idx.ColumnNames) != 1 {
+		return false
+	}
+	c := t.column(idx.ColumnNames[0])
+	return c != nil && c.ForeignKey != nil && idx.Name == fkConstraintName(t.Name, *c)
+}
+
+func sameColumnNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a2 := append([]string(nil), a...)
+	b2 := append([]string(nil), b...)
+	sort.Strings(a2)
+	sort.Strings(b2)
+	return strings.Join(a2, ",") == strings.Join(b2, ",")
+}
+
+// referencedColumn returns the name of the column the foreign key points to, which defaults to the primary key.
+func referencedColumn(tables map[string]*migrationTable, fk *db.ForeignKeyDescription) string {
+	if fk.ReferencedColumn != "" {
+		return fk.ReferencedColumn
+	}
+	if t, ok := tables[fk.ReferencedTable]; ok {
+		if pks := t.pkNames(); len(pks) > 0 {
+			return pks[0]
+		}
+	}
+	return ""
+}
+
+func sameForeignKey(fromTables map[string]*migrationTable, fk1 *db.ForeignKeyDescription,
+	toTables map[string]*migrationTable, fk2 *db.ForeignKeyDescription) bool {
+	return fk1.ReferencedTable == fk2.ReferencedTable &&
+		referencedColumn(fromTables, fk1) == referencedColumn(toTables, fk2) &&
+		sameFKAction(fk1.UpdateAction, fk2.UpdateAction) &&
+		sameFKAction(fk1.DeleteAction, fk2.DeleteAction)
+}
+
+// sameFKAction compares two actions. Databases report the default action of NO ACTION as RESTRICT, so we treat them the same.
+func sameFKAction(a1, a2 db.FKAction) bool {
+	if a1 == db.FKActionNone {
+		a1 = db.FKActionRestrict
+	}
+	if a2 == db.FKActionNone {
+		a2 = db.FKActionRestrict
+	}
+	return a1 == a2
+}
+
+// referenceChanged returns true if the type of the column that fk points to is changing, which
+// requires the foreign key to be dropped and created again.
+func referenceChanged(d MigrationDialect, fromTables map[string]*migrationTable, fk *db.ForeignKeyDescription, toTables map[string]*migrationTable) bool {
+	ft := fromTables[fk.ReferencedTable]
+	tt := toTables[fk.ReferencedTable]
+	if ft == nil || tt == nil {
+		return true
+	}
+	name := referencedColumn(fromTables, fk)
+	fc := ft.column(name)
+	tc := tt.column(name)
+	return fc == nil || tc == nil || d.ColumnSql(*fc).Type != d.ColumnSql(*tc).Type
+}
+
+func foreignKeySql(d MigrationDialect, tables map[string]*migrationTable, table string, col db.ColumnDescription) string {
+	fk := col.ForeignKey
+	s := "ALTER TABLE " + d.QuoteIdentifier(table) +
+		" ADD CONSTRAINT " + d.QuoteIdentifier(fkConstraintName(table, col)) +
+		" FOREIGN KEY (" + d.QuoteIdentifier(col.Name) + ")" +
+		" REFERENCES " + d.QuoteIdentifier(fk.ReferencedTable) + " (" + d.QuoteIdentifier(referencedColumn(tables, fk)) + ")"
+	if a := fkActionSql(fk.DeleteAction); a != "" {
+		s += " ON DELETE " + a
+	}
+	if a := fkActionSql(fk.UpdateAction); a != "" {
+		s += " ON UPDATE " + a
+	}
+	return s
+}
+
+func fkActionSql(a db.FKAction) string {
+	switch a {
+	case db.FKActionSetNull:
+		return "SET NULL"
+	case db.FKActionSetDefault:
+		return "SET DEFAULT"
+	case db.FKActionCascade:
+		return "CASCADE"
+	case db.FKActionRestrict:
+		return "RESTRICT"
+	default:
+		return ""
+	}
+}
+
+// fkConstraintName returns the name of the constraint for the foreign key on the given column.
+func fkConstraintName(table string, col db.ColumnDescription) string {
+	if col.ForeignKey.ConstraintName != "" {
+		return col.ForeignKey.ConstraintName
+	}
+	return unqualifiedName(table) + "_" + col.Name + "_fkey"
+}
+
+// indexName returns the name of the given index.
+func indexName(table string, idx db.IndexDescription) string {
+	if idx.Name != "" {
+		return idx.Name
+	}
+	if idx.IsFullText {
+		return unqualifiedName(table) + "_" + strings.Join(idx.ColumnNames, "_") + "_text_idx"
+	}
+	return unqualifiedName(table) + "_" + strings.Join(idx.ColumnNames, "_") + "_idx"
+}
+
+// unqualifiedName returns the name without its schema.
+func unqualifiedName(name string) string {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+func quoteList(d MigrationDialect, names []string) string {
+	var quoted []string
+	for _, n := range names {
+		quoted = append(quoted, d.QuoteIdentifier(n))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// enumDataSql returns the statements that change the data in an enum table from fromData to the table's EnumData.
+func enumDataSql(d MigrationDialect, t db.TableDescription, fromData []map[string]interface{}) (stmts []string) {
+	keyName := t.Columns[0].Name
+	fromRows := make(map[string]map[string]interface{}, len(fromData))
+	for _, row := range fromData {
+		fromRows[d.LiteralSql(row[keyName])] = row
+	}
+	toRows := make(map[string]bool, len(t.EnumData))
+	for _, row := range t.EnumData {
+		toRows[d.LiteralSql(row[keyName])] = true
+	}
+
+	for _, row := range fromData {
+		key := d.LiteralSql(row[keyName])
+		if !toRows[key] {
+			stmts = append(stmts, fmt.Sprintf("DELETE FROM %s WHERE %s = %s", d.QuoteIdentifier(t.Name), d.QuoteIdentifier(keyName), key))
+		}
+	}
+
+	for _, row := range t.EnumData {
+		key := d.LiteralSql(row[keyName])
+		if fromRow, ok := fromRows[key]; ok {
+			var sets []string
+			for _, c := range t.Columns[1:] {
+				if v := d.LiteralSql(row[c.Name]); v != d.LiteralSql(fromRow[c.Name]) {
+					sets = append(sets, d.QuoteIdentifier(c.Name)+" = "+v)
+				}
+			}
+			if sets != nil {
+				stmts = append(stmts, fmt.Sprintf("UPDATE %s SET %s WHERE %s = %s", d.QuoteIdentifier(t.Name), strings.Join(sets, ", "), d.QuoteIdentifier(keyName), key))
+			}
+		} else {
+			var names, values []string
+			for _, c := range t.Columns {
+				names = append(names, d.QuoteIdentifier(c.Name))
+				values = append(values, d.LiteralSql(row[c.Name]))
+			}
+			stmts = append(stmts, fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", d.QuoteIdentifier(t.Name), strings.Join(names, ", "), strings.Join(values, ", ")))
+		}
+	}
+	return
+}
@@ -0,0 +1,29 @@
+package mysql
+
+import (
+	"testing"
+
+	sql2 "github.com/goradd/goradd/pkg/orm/db/sql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateBatchInsert(t *testing.T) {
+	rows := []map[string]any{
+		{"name": "a", "age": 1},
+		{"name": "b"},
+		{"age": 3, "name": "c"},
+		{"name": "d", "age": 4},
+	}
+	groups := sql2.GroupBatchRows(rows, 4)
+	assert.Equal(t, []sql2.BatchGroup{
+		{Columns: []string{"age", "name"}, Rows: []int{0, 2}},
+		{Columns: []string{"name"}, Rows: []int{1}},
+		{Columns: []string{"age", "name"}, Rows: []int{3}},
+	}, groups)
+
+	sql, args := sql2.GenerateBatchInsert(&DB{}, "person", groups[0], rows)
+	assert.Equal(t, "INSERT INTO `person`(`age`,`name`)\nVALUES (?,?),\n(?,?)\n", sql)
+	assert.Equal(t, []any{1, "a", 3, "c"}, args)
+
+	assert.Equal(t, []string{"name"}, sql2.UpsertUpdateColumns(groups[0], []string{"age"}))
+}
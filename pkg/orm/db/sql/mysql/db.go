@@ -108,6 +108,28 @@ func NewDB(dbKey string, connectionString string, config *mysql.Config) *DB {
 	return &m
 }
 
+// AddReadReplica opens a connection to a read replica of the database, and adds it to the replicas that
+// queries from the query builder are sent to. The connectionString and config are treated as in NewDB.
+// Only call this during app startup.
+func (m *DB) AddReadReplica(connectionString string, config *mysql.Config) {
+	if connectionString == "" && config == nil {
+		panic("must specify how to connect to the database")
+	}
+	if connectionString == "" {
+		connectionString = config.FormatDSN()
+	}
+
+	db3, err := sqldb.Open("mysql", connectionString)
+	if err != nil {
+		panic("Could not open database replica: " + err.Error())
+	}
+	err = db3.Ping()
+	if err != nil {
+		panic("Could not ping database replica of " + m.DbKey() + ":" + err.Error())
+	}
+	m.AddReplica(db3)
+}
+
 // OverrideConfigSettings will use a map read in from a json file to modify
 // the given config settings
 func OverrideConfigSettings(config *mysql.Config, jsonContent map[string]interface{}) {
@@ -200,10 +222,30 @@ func (m *DB) OperationSql(op Operator, operandStrings []string) (sql string) {
 	case OpXor:
 		sOp := " " + op.String() + " "
 		sql = " (" + strings.Join(operandStrings, sOp) + ") "
+	case OpJsonExtract:
+		sql = fmt.Sprintf(`JSON_UNQUOTE(JSON_EXTRACT(%s, %s))`, operandStrings[0], jsonPath(operandStrings[1:]))
+	case OpJsonContains:
+		sql = fmt.Sprintf(`JSON_CONTAINS(%s, %s)`, operandStrings[0], operandStrings[1])
 	}
 	return
 }
 
+// jsonPath returns sql that builds a JSON path from the sql of the given keys. Keys are quoted so that
+// they can contain any character.
+func jsonPath(keys []string) string {
+	parts := []string{"'$'"}
+	for _, k := range keys {
+		parts = append(parts, "'.'", "JSON_QUOTE("+k+")")
+	}
+	return "CONCAT(" + strings.Join(parts, ", ") + ")"
+}
+
+// FullTextSql searches the columns in natural language mode. The score is the relevance MySQL
+// gives to the match. The columns must exactly match the columns of a FULLTEXT index.
+func (m *DB) FullTextSql(_ bool, _ string, _ []string, columnSql []string, textSql string) string {
+	return fmt.Sprintf(`MATCH (%s) AGAINST (%s IN NATURAL LANGUAGE MODE)`, strings.Join(columnSql, ", "), textSql)
+}
+
 // Update sets specific fields of a record that already exists in the database to the given data.
 func (m *DB) Update(ctx context.Context,
 	table string,
@@ -211,37 +253,74 @@ func (m *DB) Update(ctx context.Context,
 	pkName string,
 	pkValue any) {
 
-	sql, args := sql2.GenerateUpdate(m, table, fields, pkName, pkValue)
-	_, e := m.Exec(ctx, sql, args...)
-	if e != nil {
-		panic(e.Error())
+	if err := m.UpdateE(ctx, table, fields, pkName, pkValue, "", nil); err != nil {
+		panic(err.Error())
 	}
 }
 
+// UpdateE is like Update, but returns an error instead of panicking.
+// If lockName is not empty, the record is only updated if the lockName column still has lockValue,
+// and a db.OptimisticLockError is returned if not.
+func (m *DB) UpdateE(ctx context.Context,
+	table string,
+	fields map[string]any,
+	pkName string,
+	pkValue any,
+	lockName string,
+	lockValue any) error {
+
+	sql, args := sql2.GenerateLockedUpdate(m, table, fields, pkName, pkValue, lockName, lockValue)
+	r, err := m.Exec(ctx, sql, args...)
+	if err != nil {
+		return convertError(table, err)
+	}
+	if lockName != "" {
+		if n, err := r.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return db.OptimisticLockError{Table: table, Pk: pkValue}
+		}
+	}
+	return nil
+}
+
 // Insert inserts the given data as a new record in the database.
 // It returns the record id of the new record.
 func (m *DB) Insert(ctx context.Context, table string, fields map[string]interface{}) string {
-	sql, args := sql2.GenerateInsert(m, table, fields)
-	if r, err := m.Exec(ctx, sql, args...); err != nil {
+	id, err := m.InsertE(ctx, table, fields)
+	if err != nil {
 		panic(err.Error())
-	} else {
-		if id, err2 := r.LastInsertId(); err2 != nil {
-			panic(err2.Error())
-			return ""
-		} else {
-			return fmt.Sprint(id)
-		}
 	}
+	return id
+}
+
+// InsertE is like Insert, but returns an error instead of panicking.
+func (m *DB) InsertE(ctx context.Context, table string, fields map[string]interface{}) (string, error) {
+	sql, args := sql2.GenerateInsert(m, table, fields)
+	r, err := m.Exec(ctx, sql, args...)
+	if err != nil {
+		return "", convertError(table, err)
+	}
+	id, err := r.LastInsertId()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprint(id), nil
 }
 
 // Delete deletes the indicated record from the database.
 func (m *DB) Delete(ctx context.Context, table string, pkName string, pkValue interface{}) {
+	if err := m.DeleteE(ctx, table, pkName, pkValue); err != nil {
+		panic(err.Error())
+	}
+}
+
+// DeleteE is like Delete, but returns an error instead of panicking.
+func (m *DB) DeleteE(ctx context.Context, table string, pkName string, pkValue interface{}) error {
 	var sql = "DELETE FROM " + iq(table) + "\n"
 	sql += "WHERE " + iq(pkName) + " = ?"
-	_, e := m.Exec(ctx, sql, pkValue)
-	if e != nil {
-		panic(e.Error())
-	}
+	_, err := m.Exec(ctx, sql, pkValue)
+	return convertError(table, err)
 }
 
 // Associate sets up the many-many association pointing from the given table and column to another table and column.
@@ -262,3 +341,78 @@ func (m *DB) Associate(ctx context.Context,
 	sql2.Associate(ctx, m, table, column, pk, relatedColumn, relatedPks)
 
 }
+
+// AssociateE is like Associate, but returns an error instead of panicking.
+func (m *DB) AssociateE(ctx context.Context,
+	table string,
+	column string,
+	pk interface{},
+	_ string,
+	relatedColumn string,
+	relatedPks interface{}) error {
+
+	return convertError(table, sql2.AssociateE(ctx, m, table, column, pk, relatedColumn, relatedPks))
+}
+
+// CommitE commits the transaction, and returns any error the database reports.
+func (m *DB) CommitE(ctx context.Context, txid db.TransactionID) error {
+	return convertError("", m.DbHelper.CommitE(ctx, txid))
+}
+
+// maxBatchArgs is the most arguments MySQL allows in one statement.
+const maxBatchArgs = 65535
+
+// InsertBatch inserts the rows using multi-row insert statements.
+// MySQL reports the auto-generated id of the first row of each statement, and the ids of the following rows
+// are consecutive unless the auto_increment_increment setting has been changed.
+func (m *DB) InsertBatch(ctx context.Context, table string, rows []map[string]interface{}) ([]string, error) {
+	ids := make([]string, len(rows))
+	for _, g := range sql2.GroupBatchRows(rows, maxBatchArgs) {
+		sql, args := sql2.GenerateBatchInsert(m, table, g, rows)
+		r, err := m.Exec(ctx, sql, args...)
+		if err != nil {
+			return nil, convertError(table, err)
+		}
+		firstId, err := r.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+		if firstId != 0 {
+			for i, ri := range g.Rows {
+				ids[ri] = fmt.Sprint(firstId + int64(i))
+			}
+		}
+	}
+	return ids, nil
+}
+
+// Upsert inserts the rows using multi-row insert statements, and updates the existing records that
+// conflict with them. MySQL detects conflicts using every unique index, so conflictColumns are only used
+// to decide which values should not be updated. MySQL cannot skip the update of a record, so when scopeColumns
+// are given, each column keeps its value unless the record has the same values in scopeColumns as the row.
+func (m *DB) Upsert(ctx context.Context, table string, rows []map[string]interface{}, conflictColumns []string, scopeColumns []string) error {
+	var scopes []string
+	for _, col := range scopeColumns {
+		scopes = append(scopes, iq(col)+"<=>VALUES("+iq(col)+")")
+	}
+	for _, g := range sql2.GroupBatchRows(rows, maxBatchArgs) {
+		sql, args := sql2.GenerateBatchInsert(m, table, g, rows)
+		var sets []string
+		for _, col := range sql2.UpsertUpdateColumns(g, conflictColumns) {
+			if scopes == nil {
+				sets = append(sets, iq(col)+"=VALUES("+iq(col)+")")
+			} else {
+				sets = append(sets, iq(col)+"=IF("+strings.Join(scopes, " AND ")+",VALUES("+iq(col)+"),"+iq(col)+")")
+			}
+		}
+		if sets == nil {
+			// Nothing to update, so just ignore the duplicates
+			sets = []string{iq(g.Columns[0]) + "=" + iq(g.Columns[0])}
+		}
+		sql += "ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", ")
+		if _, err := m.Exec(ctx, sql, args...); err != nil {
+			return convertError(table, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/goradd/goradd/pkg/orm/db"
+	sql2 "github.com/goradd/goradd/pkg/orm/db/sql"
+	"github.com/goradd/goradd/pkg/orm/db/sql/sqltest"
+	. "github.com/goradd/goradd/pkg/orm/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJsonOperationSql(t *testing.T) {
+	m := &DB{}
+	assert.Equal(t, "JSON_UNQUOTE(JSON_EXTRACT(`t`.`settings`, CONCAT('$', '.', JSON_QUOTE(?), '.', JSON_QUOTE(?))))",
+		m.OperationSql(OpJsonExtract, []string{"`t`.`settings`", "?", "?"}))
+	assert.Equal(t, "JSON_CONTAINS(`t`.`settings`, ?)",
+		m.OperationSql(OpJsonContains, []string{"`t`.`settings`", "?"}))
+
+	assert.Equal(t, "json", columnType(db.ColumnDescription{NativeType: "json", GoType: "json.RawMessage"}))
+	assert.Equal(t, "json", columnType(db.ColumnDescription{GoType: "json.RawMessage"}))
+}
+
+func TestFullTextSql(t *testing.T) {
+	m := &DB{}
+	assert.Equal(t, "MATCH (`t`.`name`, `t`.`description`) AGAINST (? IN NATURAL LANGUAGE MODE)",
+		m.FullTextSql(false, "project", []string{"name", "description"}, []string{"`t`.`name`", "`t`.`description`"}, "?"))
+
+	from := db.DatabaseDescription{Tables: []db.TableDescription{{
+		Name:    "project",
+		Columns: []db.ColumnDescription{{Name: "id", GoType: "int", IsId: true, IsPk: true}, {Name: "name", GoType: "string", MaxCharLength: 100}},
+	}}}
+	to := db.DatabaseDescription{Tables: []db.TableDescription{from.Tables[0]}}
+	to.Tables[0].Indexes = []db.IndexDescription{{IsFullText: true, ColumnNames: []string{"name"}}}
+	assert.Equal(t, []string{"CREATE FULLTEXT INDEX `project_name_text_idx` ON `project` (`name`)"}, GenerateMigration(from, to))
+}
+
+func TestUpsertScope(t *testing.T) {
+	c := &sqltest.Conn{}
+	m := &DB{DbHelper: sql2.NewSqlDb("test", c.DB())}
+	err := m.Upsert(context.Background(), "item", []map[string]interface{}{
+		{"id": 1, "tenant_id": 1, "name": "a"},
+	}, []string{"id"}, []string{"tenant_id"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"INSERT INTO `item`(`id`,`name`,`tenant_id`)\nVALUES (?,?,?)\n" +
+		"ON DUPLICATE KEY UPDATE `name`=IF(`tenant_id`<=>VALUES(`tenant_id`),VALUES(`name`),`name`), " +
+		"`tenant_id`=IF(`tenant_id`<=>VALUES(`tenant_id`),VALUES(`tenant_id`),`tenant_id`)"}, c.Statements())
+}
@@ -0,0 +1,25 @@
+package mysql
+
+import (
+	"errors"
+	"github.com/go-sql-driver/mysql"
+	"github.com/goradd/goradd/pkg/orm/db"
+)
+
+// convertError converts the MySQL errors that an application might want to respond to into the
+// matching db error types. Other errors are returned unchanged.
+func convertError(table string, err error) error {
+	var me *mysql.MySQLError
+	if err == nil || !errors.As(err, &me) {
+		return err
+	}
+	switch me.Number {
+	case 1062, 1586: // ER_DUP_ENTRY, ER_DUP_ENTRY_WITH_KEY_NAME
+		return db.UniqueViolationError{Table: table, Err: err}
+	case 1216, 1217, 1451, 1452: // ER_NO_REFERENCED_ROW, ER_ROW_IS_REFERENCED and their _2 versions
+		return db.ForeignKeyViolationError{Table: table, Err: err}
+	case 1205, 1213: // ER_LOCK_WAIT_TIMEOUT, ER_LOCK_DEADLOCK
+		return db.DeadlockError{Table: table, Err: err}
+	}
+	return err
+}
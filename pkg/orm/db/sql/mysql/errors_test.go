@@ -0,0 +1,34 @@
+package mysql
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/goradd/goradd/pkg/orm/db"
+	sql2 "github.com/goradd/goradd/pkg/orm/db/sql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertError(t *testing.T) {
+	dup := &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'a' for key 'name'"}
+	err := convertError("person", fmt.Errorf("exec: %w", dup))
+	var ue db.UniqueViolationError
+	assert.True(t, errors.As(err, &ue))
+	assert.Equal(t, "person", ue.Table)
+	assert.ErrorIs(t, err, dup)
+
+	assert.ErrorAs(t, convertError("person", &mysql.MySQLError{Number: 1451}), &db.ForeignKeyViolationError{})
+	assert.ErrorAs(t, convertError("", &mysql.MySQLError{Number: 1213}), &db.DeadlockError{})
+
+	other := errors.New("bad connection")
+	assert.Equal(t, other, convertError("person", other))
+	assert.NoError(t, convertError("person", nil))
+}
+
+func TestGenerateLockedUpdate(t *testing.T) {
+	sql, args := sql2.GenerateLockedUpdate(&DB{}, "person", map[string]any{"name": "a", "version": 3}, "id", 1, "version", 2)
+	assert.Equal(t, "UPDATE `person`\nSET `name`=?, `version`=?\nWHERE `id` = ? AND `version` = ?", sql)
+	assert.Equal(t, []any{"a", 3, 1, 2}, args)
+}
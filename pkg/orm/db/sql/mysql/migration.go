@@ -0,0 +1,270 @@
+package mysql
+
+import (
+	"encoding/hex"
+	"fmt"
+	"github.com/goradd/goradd/pkg/orm/db"
+	sql2 "github.com/goradd/goradd/pkg/orm/db/sql"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GenerateMigration returns the SQL statements that will change a MySQL database with the structure
+// described by from so that it has the structure described by to.
+// See sql.GenerateMigration for details.
+func GenerateMigration(from, to db.DatabaseDescription) []string {
+	return sql2.GenerateMigration(migrationDialect{}, from, to)
+}
+
+// MigrationSql returns the SQL statements that will change the structure of the database so that it matches desired,
+// which is usually read from a schema file using db.ReadDescriptionFile.
+func (m *DB) MigrationSql(desired db.DatabaseDescription, options Options) []string {
+	return GenerateMigration(m.Describe(options), desired)
+}
+
+// migrationDialect generates MySQL specific DDL for sql.GenerateMigration.
+type migrationDialect struct{}
+
+func (d migrationDialect) QuoteIdentifier(v string) string {
+	return iq(v)
+}
+
+func (d migrationDialect) LiteralSql(v interface{}) string {
+	switch v2 := v.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return quoteString(v2)
+	case []byte:
+		return "X'" + hex.EncodeToString(v2) + "'"
+	case bool:
+		if v2 {
+			return "1"
+		}
+		return "0"
+	case float32:
+		return strconv.FormatFloat(float64(v2), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v2, 'g', -1, 64)
+	case time.Time:
+		return quoteString(v2.UTC().Format("2006-01-02 15:04:05.999999"))
+	default:
+		return fmt.Sprint(v2)
+	}
+}
+
+func (d migrationDialect) ColumnSql(col db.ColumnDescription) sql2.ColumnSql {
+	c := sql2.ColumnSql{
+		Type:          columnType(col),
+		NotNull:       !col.IsNullable,
+		AutoIncrement: col.IsId,
+		Comment:       sql2.CommentWithOptions(col.Comment, col.Options),
+	}
+	if !col.IsId && col.DefaultValue != nil {
+		c.Default = d.defaultSql(col)
+	}
+	return c
+}
+
+func (d migrationDialect) defaultSql(col db.ColumnDescription) string {
+	switch v := col.DefaultValue.(type) {
+	case string:
+		if col.GoType == "time.Time" && v == "now" {
+			return "CURRENT_TIMESTAMP"
+		}
+	case time.Time:
+		switch col.SubType {
+		case "date":
+			return quoteString(v.Format("2006-01-02"))
+		case "time":
+			return quoteString(v.Format("15:04:05.999999"))
+		}
+	}
+	return d.LiteralSql(col.DefaultValue)
+}
+
+// columnDefinition returns the sql that defines the column in a CREATE TABLE or ALTER TABLE statement.
+func (d migrationDialect) columnDefinition(col db.ColumnDescription) string {
+	c := d.ColumnSql(col)
+	s := iq(col.Name) + " " + c.Type
+	if c.NotNull {
+		s += " NOT NULL"
+	} else {
+		s += " NULL"
+	}
+	if c.Default != "" {
+		s += " DEFAULT " + c.Default
+	}
+	if c.AutoIncrement {
+		s += " AUTO_INCREMENT"
+	}
+	if c.Comment != "" {
+		s += " COMMENT " + quoteString(c.Comment)
+	}
+	return s
+}
+
+func (d migrationDialect) CreateTableSql(t db.TableDescription) []string {
+	var lines []string
+	var pks []string
+	for _, col := range t.Columns {
+		lines = append(lines, "  "+d.columnDefinition(col))
+		if col.IsPk {
+			pks = append(pks, iq(col.Name))
+		}
+	}
+	if pks != nil {
+		lines = append(lines, "  PRIMARY KEY ("+strings.Join(pks, ", ")+")")
+	}
+	s := "CREATE TABLE " + iq(t.Name) + " (\n" + strings.Join(lines, ",\n") + "\n) ENGINE=InnoDB"
+	if comment := sql2.CommentWithOptions(t.Comment, t.Options); comment != "" {
+		s += " COMMENT=" + quoteString(comment)
+	}
+	return []string{s}
+}
+
+func (d migrationDialect) AddColumnSql(table string, col db.ColumnDescription) []string {
+	return []string{"ALTER TABLE " + iq(table) + " ADD COLUMN " + d.columnDefinition(col)}
+}
+
+func (d migrationDialect) AlterColumnSql(table string, _, to db.ColumnDescription) []string {
+	return []string{"ALTER TABLE " + iq(table) + " MODIFY COLUMN " + d.columnDefinition(to)}
+}
+
+func (d migrationDialect) AlterPrimaryKeySql(table string, from, to []string) []string {
+	var clauses []string
+	if len(from) > 0 {
+		clauses = append(clauses, "DROP PRIMARY KEY")
+	}
+	if len(to) > 0 {
+		var pks []string
+		for _, pk := range to {
+			pks = append(pks, iq(pk))
+		}
+		clauses = append(clauses, "ADD PRIMARY KEY ("+strings.Join(pks, ", ")+")")
+	}
+	return []string{"ALTER TABLE " + iq(table) + " " + strings.Join(clauses, ", ")}
+}
+
+func (d migrationDialect) TableCommentSql(table string, comment string) []string {
+	return []string{"ALTER TABLE " + iq(table) + " COMMENT = " + quoteString(comment)}
+}
+
+func (d migrationDialect) DropForeignKeySql(table string, name string) []string {
+	return []string{"ALTER TABLE " + iq(table) + " DROP FOREIGN KEY " + iq(name)}
+}
+
+func (d migrationDialect) DropIndexSql(table string, name string) []string {
+	return []string{"DROP INDEX " + iq(name) + " ON " + iq(table)}
+}
+
+func (d migrationDialect) CreateFullTextIndexSql(table string, name string, idx db.IndexDescription) []string {
+	var cols []string
+	for _, c := range idx.ColumnNames {
+		cols = append(cols, iq(c))
+	}
+	return []string{"CREATE FULLTEXT INDEX " + iq(name) + " ON " + iq(table) + " (" + strings.Join(cols, ", ") + ")"}
+}
+
+// columnType returns the MySQL type of the column. The native type is used if it is a MySQL type,
+// and otherwise a type is chosen based on the Go type.
+// Native types whose details are not fully described, like ENUM and SET, use defaults.
+// DECIMAL native types are described with their precision and scale, and are used as-is.
+func columnType(col db.ColumnDescription) string {
+	if strings.HasPrefix(col.NativeType, "decimal(") {
+		return col.NativeType
+	}
+	unsigned := ""
+	if col.GoType == "uint" || col.GoType == "uint64" {
+		unsigned = " unsigned"
+	}
+	switch col.NativeType {
+	case "tinyint":
+		if col.GoType == "bool" {
+			return "tinyint(1)"
+		}
+		return col.NativeType + unsigned
+	case "smallint", "mediumint", "int", "bigint":
+		return col.NativeType + unsigned
+	case "float", "double", "date", "time", "datetime", "timestamp", "year",
+		"tinytext", "text", "mediumtext", "longtext",
+		"tinyblob", "blob", "mediumblob", "longblob", "json", "uuid":
+		return col.NativeType
+	case "varchar", "char":
+		if col.MaxCharLength > 0 {
+			return fmt.Sprintf("%s(%d)", col.NativeType, col.MaxCharLength)
+		}
+	}
+
+	switch col.GoType {
+	case "int":
+		return "int"
+	case "uint":
+		return "int unsigned"
+	case "int64":
+		return "bigint"
+	case "uint64":
+		return "bigint unsigned"
+	case "float32":
+		return "float"
+	case "float64":
+		return "double"
+	case "bool":
+		return "tinyint(1)"
+	case "[]byte":
+		switch {
+		case col.MaxCharLength == 0:
+			return "blob"
+		case col.MaxCharLength <= 255:
+			return "tinyblob"
+		case col.MaxCharLength <= 65535:
+			return "blob"
+		case col.MaxCharLength <= 16777215:
+			return "mediumblob"
+		default:
+			return "longblob"
+		}
+	case "json.RawMessage":
+		return "json"
+	case "decimal.Decimal":
+		return "decimal(65,30)"
+	case "uuid.UUID":
+		return "char(36)"
+	case "time.Time":
+		switch col.SubType {
+		case "date":
+			return "date"
+		case "time":
+			return "time"
+		case "timestamp":
+			return "timestamp"
+		default:
+			return "datetime"
+		}
+	}
+
+	// strings
+	if col.IsId {
+		return "int" // ids are reported as strings, but are stored as integers
+	}
+	switch {
+	case col.MaxCharLength == 0:
+		return "text"
+	case col.MaxCharLength <= 16383: // the most utf8mb4 characters that fit in a row
+		return fmt.Sprintf("varchar(%d)", col.MaxCharLength)
+	case col.MaxCharLength <= 65535:
+		return "text"
+	case col.MaxCharLength <= 16777215:
+		return "mediumtext"
+	default:
+		return "longtext"
+	}
+}
+
+// quoteString returns s as a quoted MySQL string literal.
+func quoteString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `'`, `''`)
+	return "'" + s + "'"
+}
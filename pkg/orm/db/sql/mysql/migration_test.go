@@ -0,0 +1,126 @@
+package mysql
+
+import (
+	"testing"
+
+	"github.com/goradd/goradd/pkg/orm/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func migrationTestDescription() db.DatabaseDescription {
+	return db.DatabaseDescription{
+		Tables: []db.TableDescription{
+			{
+				Name: "person",
+				Columns: []db.ColumnDescription{
+					{Name: "id", NativeType: "int", GoType: "uint", IsId: true, IsPk: true},
+					{Name: "name", NativeType: "varchar", GoType: "string", MaxCharLength: 50, IsNullable: true},
+					{Name: "type_id", NativeType: "int", GoType: "int", IsNullable: true,
+						ForeignKey: &db.ForeignKeyDescription{ReferencedTable: "person_type_enum", ReferencedColumn: "id",
+							DeleteAction: db.FKActionSetNull, ConstraintName: "person_type_fk"}},
+				},
+				Indexes: []db.IndexDescription{{Name: "person_name", ColumnNames: []string{"name"}}},
+			},
+			{
+				Name: "person_type_enum",
+				Columns: []db.ColumnDescription{
+					{Name: "id", NativeType: "int", GoType: "int", IsPk: true},
+					{Name: "name", NativeType: "varchar", GoType: "string", MaxCharLength: 50},
+				},
+				EnumData: []map[string]interface{}{
+					{"id": 1, "name": "Manager"},
+					{"id": 2, "name": "Worker"},
+				},
+			},
+		},
+	}
+}
+
+func TestGenerateMigration(t *testing.T) {
+	from := migrationTestDescription()
+	assert.Empty(t, GenerateMigration(from, from))
+
+	to := migrationTestDescription()
+	person := &to.Tables[0]
+	person.Columns[1].MaxCharLength = 100
+	person.Columns[1].Comment = "The person's name"
+	person.Columns[2].ForeignKey.DeleteAction = db.FKActionCascade
+	person.Columns = append(person.Columns, db.ColumnDescription{Name: "active", GoType: "bool", DefaultValue: true})
+	person.Indexes = []db.IndexDescription{{IsUnique: true, ColumnNames: []string{"name"}}}
+	to.Tables[1].EnumData = []map[string]interface{}{
+		{"id": 1, "name": "Boss"},
+		{"id": 3, "name": "Contractor"},
+	}
+	to.Tables = append(to.Tables, db.TableDescription{
+		Name: "project",
+		Columns: []db.ColumnDescription{
+			{Name: "id", GoType: "string", IsId: true, IsPk: true},
+			{Name: "manager_id", GoType: "uint", ForeignKey: &db.ForeignKeyDescription{ReferencedTable: "person"}},
+			{Name: "start", GoType: "time.Time", SubType: "timestamp", DefaultValue: "now"},
+		},
+	})
+	to.MM = []db.ManyManyDescription{
+		{Table1: "project", Column1: "project_id", Table2: "person", Column2: "person_id", AssnTableName: "team_member_assn", GoName2: "Member"},
+	}
+
+	assert.Equal(t, []string{
+		"ALTER TABLE `person` DROP FOREIGN KEY `person_type_fk`",
+		"DROP INDEX `person_name` ON `person`",
+		"CREATE TABLE `project` (\n" +
+			"  `id` int NOT NULL AUTO_INCREMENT,\n" +
+			"  `manager_id` int unsigned NOT NULL,\n" +
+			"  `start` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,\n" +
+			"  PRIMARY KEY (`id`)\n" +
+			") ENGINE=InnoDB",
+		"CREATE TABLE `team_member_assn` (\n" +
+			"  `project_id` int NOT NULL,\n" +
+			"  `person_id` int unsigned NOT NULL COMMENT '{\"goName\":\"Member\"}',\n" +
+			"  PRIMARY KEY (`project_id`, `person_id`)\n" +
+			") ENGINE=InnoDB",
+		"ALTER TABLE `person` MODIFY COLUMN `name` varchar(100) NULL COMMENT 'The person''s name'",
+		"ALTER TABLE `person` ADD COLUMN `active` tinyint(1) NOT NULL DEFAULT 1",
+		"DELETE FROM `person_type_enum` WHERE `id` = 2",
+		"UPDATE `person_type_enum` SET `name` = 'Boss' WHERE `id` = 1",
+		"INSERT INTO `person_type_enum` (`id`, `name`) VALUES (3, 'Contractor')",
+		"CREATE UNIQUE INDEX `person_name_idx` ON `person` (`name`)",
+		"CREATE INDEX `team_member_assn_person_id_idx` ON `team_member_assn` (`person_id`)",
+		"ALTER TABLE `person` ADD CONSTRAINT `person_type_fk` FOREIGN KEY (`type_id`) REFERENCES `person_type_enum` (`id`) ON DELETE CASCADE",
+		"ALTER TABLE `project` ADD CONSTRAINT `project_manager_id_fkey` FOREIGN KEY (`manager_id`) REFERENCES `person` (`id`)",
+		"ALTER TABLE `team_member_assn` ADD CONSTRAINT `team_member_assn_project_id_fkey` FOREIGN KEY (`project_id`) REFERENCES `project` (`id`) ON DELETE CASCADE ON UPDATE CASCADE",
+		"ALTER TABLE `team_member_assn` ADD CONSTRAINT `team_member_assn_person_id_fkey` FOREIGN KEY (`person_id`) REFERENCES `person` (`id`) ON DELETE CASCADE ON UPDATE CASCADE",
+	}, GenerateMigration(from, to))
+
+	// going back drops what was added
+	assert.Equal(t, []string{
+		"ALTER TABLE `person` DROP FOREIGN KEY `person_type_fk`",
+		"ALTER TABLE `project` DROP FOREIGN KEY `project_manager_id_fkey`",
+		"DROP INDEX `person_name_idx` ON `person`",
+		"DROP TABLE `team_member_assn`",
+		"DROP TABLE `project`",
+		"ALTER TABLE `person` MODIFY COLUMN `name` varchar(50) NULL",
+		"ALTER TABLE `person` DROP COLUMN `active`",
+		"DELETE FROM `person_type_enum` WHERE `id` = 3",
+		"UPDATE `person_type_enum` SET `name` = 'Manager' WHERE `id` = 1",
+		"INSERT INTO `person_type_enum` (`id`, `name`) VALUES (2, 'Worker')",
+		"CREATE INDEX `person_name` ON `person` (`name`)",
+		"ALTER TABLE `person` ADD CONSTRAINT `person_type_fk` FOREIGN KEY (`type_id`) REFERENCES `person_type_enum` (`id`) ON DELETE SET NULL",
+	}, GenerateMigration(to, from))
+}
+
+func TestGenerateMigrationDecimal(t *testing.T) {
+	from := db.DatabaseDescription{Tables: []db.TableDescription{{
+		Name: "invoice",
+		Columns: []db.ColumnDescription{
+			{Name: "id", NativeType: "int", GoType: "string", IsId: true, IsPk: true},
+			{Name: "total", NativeType: "decimal(12,2)", GoType: "decimal.Decimal", MaxCharLength: 15},
+		},
+	}}}
+	to := db.DatabaseDescription{Tables: []db.TableDescription{from.Tables[0]}}
+	to.Tables[0].Columns = append([]db.ColumnDescription{}, from.Tables[0].Columns...)
+	to.Tables[0].Columns[1].IsNullable = true
+
+	assert.Equal(t, []string{
+		"ALTER TABLE `invoice` MODIFY COLUMN `total` decimal(12,2) NULL",
+	}, GenerateMigration(from, to))
+	assert.Equal(t, "decimal(65,30)", columnType(db.ColumnDescription{GoType: "decimal.Decimal"}))
+}
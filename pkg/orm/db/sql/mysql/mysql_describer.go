@@ -49,6 +49,7 @@ type mysqlIndex struct {
 	nonUnique  bool
 	tableName  string
 	columnName string
+	indexType  string
 }
 
 type mysqlForeignKey struct {
@@ -86,13 +87,35 @@ func NewMysql2 (dbKey string , options DbOptions, config *mysql.Config) (*DB, er
 }*/
 
 func (m *DB) Analyze(options Options) {
+	m.AnalyzeDescription(m.Describe(options), options)
+}
+
+// Describe reads the structure of the database and returns it as a description.
+// Pass the result to db.WriteDescriptionFile to save it as a schema file.
+func (m *DB) Describe(options Options) db.DatabaseDescription {
 	rawTables := m.getRawTables()
-	description := m.descriptionFromRawTables(rawTables, options)
+	return m.descriptionFromRawTables(rawTables, options)
+}
+
+// AnalyzeDescription builds the model used for code generation from the given description
+// rather than from the structure of the database.
+func (m *DB) AnalyzeDescription(desc db.DatabaseDescription, options Options) {
 	m.model = db.NewModel(m.DbKey(),
 		m.databaseName,
 		options.ForeignKeySuffix,
 		options.EnumTableSuffix,
-		false, description)
+		false, desc)
+}
+
+// AnalyzeFile builds the model used for code generation from the schema file at path.
+// See db.ReadDescriptionFile for the format of the file. It panics if the file cannot be read
+// or is not valid.
+func (m *DB) AnalyzeFile(path string, options Options) {
+	desc, err := db.ReadDescriptionFile(path)
+	if err != nil {
+		panic(err.Error())
+	}
+	m.AnalyzeDescription(desc, options)
 }
 
 func (m *DB) getRawTables() map[string]mysqlTable {
@@ -253,7 +276,8 @@ func (m *DB) getIndexes() (indexes map[string][]mysqlIndex, err error) {
 	index_name,
 	non_unique,
 	table_name,
-	column_name
+	column_name,
+	index_type
 	FROM
 	information_schema.statistics
 	WHERE
@@ -268,7 +292,7 @@ func (m *DB) getIndexes() (indexes map[string][]mysqlIndex, err error) {
 
 	for rows.Next() {
 		index = mysqlIndex{}
-		err = rows.Scan(&index.name, &index.nonUnique, &index.tableName, &index.columnName)
+		err = rows.Scan(&index.name, &index.nonUnique, &index.tableName, &index.columnName, &index.indexType)
 		if err != nil {
 			log.Fatal(err)
 			return nil, err
@@ -486,6 +510,9 @@ func (m *DB) processTypeInfo(tableName string, column mysqlColumn, cd *db.Column
 		cd.GoType = ColTypeBytes.GoType()
 		cd.MaxCharLength = math.MaxUint32
 
+	case "json":
+		cd.GoType = ColTypeJSON.GoType()
+
 	case "text":
 		cd.GoType = ColTypeString.GoType()
 		cd.MaxCharLength = 65535
@@ -500,11 +527,12 @@ func (m *DB) processTypeInfo(tableName string, column mysqlColumn, cd *db.Column
 		cd.MaxCharLength = math.MaxUint32
 
 	case "decimal":
-		// No native equivalent in Go.
-		// See the shopspring/decimal package for possible support.
-		// You will need to shepherd numbers into and out of string format to move data to the database.
-		cd.GoType = ColTypeString.GoType()
+		cd.GoType = ColTypeDecimal.GoType()
 		cd.MaxCharLength = uint64(dataLen) + 3
+		cd.NativeType = column.columnType // keep the precision and scale so that migrations do not change them
+
+	case "uuid": // MariaDB
+		cd.GoType = ColTypeUUID.GoType()
 
 	case "year":
 		cd.GoType = ColTypeInteger.GoType()
@@ -569,7 +597,12 @@ func (m *DB) getTableDescription(t mysqlTable) db.TableDescription {
 			i.ColumnNames = append(i.ColumnNames, idx.columnName)
 			sort.Strings(i.ColumnNames) // make sure this list stays in a predictable order each time
 		} else {
-			i = &db.IndexDescription{IsUnique: !idx.nonUnique, ColumnNames: []string{idx.columnName}}
+			i = &db.IndexDescription{
+				Name:        idx.name,
+				IsUnique:    !idx.nonUnique,
+				ColumnNames: []string{idx.columnName},
+				IsFullText:  idx.indexType == "FULLTEXT",
+			}
 			indexes[idx.name] = i
 		}
 	}
@@ -678,6 +711,7 @@ func (m *DB) getColumnDescription(table mysqlTable, column mysqlColumn, isPk boo
 			ReferencedColumn: fk.referencedColumnName.String,
 			UpdateAction:     fkRuleToAction(fk.updateRule),
 			DeleteAction:     fkRuleToAction(fk.deleteRule),
+			ConstraintName:   fk.constraintName,
 		}
 	}
 
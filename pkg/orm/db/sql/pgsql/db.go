@@ -62,6 +62,29 @@ func NewDB(dbKey string,
 	return &m
 }
 
+// AddReadReplica opens a connection to a read replica of the database, and adds it to the replicas that
+// queries from the query builder are sent to. The connectionString and config are treated as in NewDB.
+// Only call this during app startup.
+func (m *DB) AddReadReplica(connectionString string, config *pgx.ConnConfig) {
+	if connectionString == "" && config == nil {
+		panic("must specify how to connect to the database")
+	}
+
+	if connectionString == "" {
+		connectionString = stdlib.RegisterConnConfig(config)
+	}
+
+	db3, err := sqldb.Open("pgx", connectionString)
+	if err != nil {
+		panic("Could not open database replica: " + err.Error())
+	}
+	err = db3.Ping()
+	if err != nil {
+		panic("Could not ping database replica of " + m.DbKey() + ":" + err.Error())
+	}
+	m.AddReplica(db3)
+}
+
 // OverrideConfigSettings will use a map read in from a json file to modify
 // the given config settings
 func OverrideConfigSettings(config *pgx.ConnConfig, jsonContent map[string]interface{}) {
@@ -127,10 +150,113 @@ func (m *DB) OperationSql(op Operator, operandStrings []string) (sql string) {
 		s := operandStrings[0]
 		s2 := operandStrings[1]
 		return fmt.Sprintf(`(%s + make_interval(seconds => %s))`, s, s2)
+	case OpJsonExtract:
+		if len(operandStrings) == 1 {
+			return fmt.Sprintf(`(%s::jsonb #>> '{}')`, operandStrings[0])
+		}
+		keys := operandStrings[1:]
+		for i, k := range keys {
+			keys[i] = k + "::text"
+		}
+		return fmt.Sprintf(`jsonb_extract_path_text(%s::jsonb, %s)`, operandStrings[0], strings.Join(keys, ", "))
+	case OpJsonContains:
+		return fmt.Sprintf(`(%s::jsonb @> %s::jsonb)`, operandStrings[0], operandStrings[1])
+	case OpArrayAny:
+		return fmt.Sprintf(`(%s = ANY(%s))`, operandStrings[1], operandStrings[0])
+	case OpArrayAll:
+		return fmt.Sprintf(`(%s = ALL(%s))`, operandStrings[1], operandStrings[0])
+	case OpArrayOverlaps:
+		return fmt.Sprintf(`(%s && %s)`, operandStrings[0], operandStrings[1])
+	case OpArrayContains:
+		return fmt.Sprintf(`(%s @> %s)`, operandStrings[0], operandStrings[1])
 	}
 	return
 }
 
+// ColumnReadSql converts columns that hold a native enum type to the position of the value in the type,
+// which is the id of the value in the corresponding goradd enum type.
+func (m *DB) ColumnReadSql(table string, column string, columnSql string) string {
+	if m.enumType(table, column) == "" {
+		return columnSql
+	}
+	return fmt.Sprintf(`array_position(enum_range(%[1]s), %[1]s)`, columnSql)
+}
+
+// ColumnWriteSql converts the id of an enum value to the value of the native enum type, for columns that
+// hold a native enum type.
+func (m *DB) ColumnWriteSql(table string, column string, valueSql string) string {
+	if typ := m.enumType(table, column); typ != "" {
+		return fmt.Sprintf(`(enum_range(NULL::%s))[%s]`, iq(typ), valueSql)
+	}
+	return valueSql
+}
+
+// enumType returns the name of the native enum type held by the given column, or an empty string
+// if the column does not hold a native enum type.
+func (m *DB) enumType(table string, column string) string {
+	if m.model == nil {
+		return ""
+	}
+	t := m.model.Table(table)
+	if t == nil {
+		return ""
+	}
+	c := t.GetColumn(column)
+	if c == nil || !c.IsEnum() {
+		return ""
+	}
+	if et := m.model.EnumTable(c.ForeignKey.ReferencedTable); et != nil && et.IsNativeEnum {
+		return et.DbName
+	}
+	return ""
+}
+
+// FullTextSql compares the to_tsvector of the columns with the plainto_tsquery of the search text, and
+// scores matches with ts_rank. If the columns have a full text index, its search configuration and column order are
+// used so that Postgres can use the index. Otherwise, the default search configuration of the database is used.
+func (m *DB) FullTextSql(score bool, table string, columns []string, columnSql []string, textSql string) string {
+	config, columnSql := m.fullTextIndexConfig(table, columns, columnSql)
+	doc := tsvectorSql(config, columnSql)
+	query := "plainto_tsquery(" + textSql + ")"
+	if config != "" {
+		query = "plainto_tsquery(" + quoteString(config) + ", " + textSql + ")"
+	}
+	if score {
+		return fmt.Sprintf(`ts_rank(%s, %s)`, doc, query)
+	}
+	return fmt.Sprintf(`(%s @@ %s)`, doc, query)
+}
+
+// fullTextIndexConfig looks for a full text index on the given columns of the table. If one is found, it returns
+// the search configuration of the index, and the sql of the columns in the order of the index.
+func (m *DB) fullTextIndexConfig(table string, columns []string, columnSql []string) (string, []string) {
+	if m.model == nil {
+		return "", columnSql
+	}
+	t := m.model.Table(table)
+	if t == nil {
+		return "", columnSql
+	}
+	for _, idx := range t.Indexes {
+		if !idx.IsFullText || len(idx.Columns) != len(columns) {
+			continue
+		}
+		ordered := make([]string, 0, len(columns))
+		for _, c := range idx.Columns {
+			for i, name := range columns {
+				if name == c.DbName {
+					ordered = append(ordered, columnSql[i])
+					break
+				}
+			}
+		}
+		if len(ordered) == len(columns) {
+			return searchConfig(idx.SearchConfig), ordered
+		}
+	}
+	return "", columnSql
+}
+
 // Update sets specific fields of a record that already exists in the database to the given data.
 func (m *DB) Update(ctx context.Context,
 	table string,
@@ -138,44 +264,82 @@ func (m *DB) Update(ctx context.Context,
 	pkName string,
 	pkValue any) {
 
-	sql, args := sql2.GenerateUpdate(m, table, fields, pkName, pkValue)
-	_, e := m.Exec(ctx, sql, args...)
-	if e != nil {
-		panic(e.Error())
+	if err := m.UpdateE(ctx, table, fields, pkName, pkValue, "", nil); err != nil {
+		panic(err.Error())
 	}
 }
 
+// UpdateE is like Update, but returns an error instead of panicking.
+// If lockName is not empty, the record is only updated if the lockName column still has lockValue,
+// and a db.OptimisticLockError is returned if not.
+func (m *DB) UpdateE(ctx context.Context,
+	table string,
+	fields map[string]any,
+	pkName string,
+	pkValue any,
+	lockName string,
+	lockValue any) error {
+
+	sql, args := sql2.GenerateLockedUpdate(m, table, fields, pkName, pkValue, lockName, lockValue)
+	r, err := m.Exec(ctx, sql, args...)
+	if err != nil {
+		return convertError(table, err)
+	}
+	if lockName != "" {
+		if n, err := r.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return db.OptimisticLockError{Table: table, Pk: pkValue}
+		}
+	}
+	return nil
+}
+
 // Insert inserts the given data as a new record in the database.
 // It returns the record id of the new record.
 func (m *DB) Insert(ctx context.Context, table string, fields map[string]interface{}) string {
+	id, err := m.InsertE(ctx, table, fields)
+	if err != nil {
+		panic(err.Error())
+	}
+	return id
+}
+
+// InsertE is like Insert, but returns an error instead of panicking.
+func (m *DB) InsertE(ctx context.Context, table string, fields map[string]interface{}) (string, error) {
 	sql, args := sql2.GenerateInsert(m, table, fields)
 	sql += " RETURNING "
 	sql += m.Model().Table(table).PrimaryKeyColumn().DbName
-	if rows, err := m.Query(ctx, sql, args...); err != nil {
-		panic(err.Error())
-	} else {
-		var id string
-		defer rows.Close()
-		for rows.Next() {
-			err = rows.Scan(&id)
-		}
-		if err != nil {
-			panic(err.Error())
-			return ""
-		} else {
-			return id
+	rows, err := m.Query(ctx, sql, args...)
+	if err != nil {
+		return "", convertError(table, err)
+	}
+	defer rows.Close()
+	var id string
+	for rows.Next() {
+		if err = rows.Scan(&id); err != nil {
+			return "", err
 		}
 	}
+	if err = rows.Err(); err != nil {
+		return "", convertError(table, err)
+	}
+	return id, nil
 }
 
 // Delete deletes the indicated record from the database.
 func (m *DB) Delete(ctx context.Context, table string, pkName string, pkValue interface{}) {
+	if err := m.DeleteE(ctx, table, pkName, pkValue); err != nil {
+		panic(err.Error())
+	}
+}
+
+// DeleteE is like Delete, but returns an error instead of panicking.
+func (m *DB) DeleteE(ctx context.Context, table string, pkName string, pkValue interface{}) error {
 	var sql = "DELETE FROM " + iq(table) + "\n"
 	sql += "WHERE " + iq(pkName) + " = $1"
-	_, e := m.Exec(ctx, sql, pkValue)
-	if e != nil {
-		panic(e.Error())
-	}
+	_, err := m.Exec(ctx, sql, pkValue)
+	return convertError(table, err)
 }
 
 // Associate sets up the many-many association pointing from the given table and column to another table and column.
@@ -195,3 +359,145 @@ func (m *DB) Associate(ctx context.Context,
 
 	sql2.Associate(ctx, m, table, column, pk, relatedColumn, relatedPks)
 }
+
+// AssociateE is like Associate, but returns an error instead of panicking.
+func (m *DB) AssociateE(ctx context.Context,
+	table string,
+	column string,
+	pk interface{},
+	_ string,
+	relatedColumn string,
+	relatedPks interface{}) error {
+
+	return convertError(table, sql2.AssociateE(ctx, m, table, column, pk, relatedColumn, relatedPks))
+}
+
+// CommitE commits the transaction, and returns any error the database reports.
+func (m *DB) CommitE(ctx context.Context, txid db.TransactionID) error {
+	return convertError("", m.DbHelper.CommitE(ctx, txid))
+}
+
+// maxBatchArgs is the most arguments Postgres allows in one statement.
+const maxBatchArgs = 65535
+
+// InsertBatch inserts the rows using multi-row insert statements.
+//
+// Postgres does not promise to return the ids of a multi-row insert in the order of the rows, so if the table
+// has an auto-generated primary key, the ids are first taken from the key's sequence and then inserted
+// along with the rows. The primary key must be a serial or identity column.
+func (m *DB) InsertBatch(ctx context.Context, table string, rows []map[string]interface{}) ([]string, error) {
+	ids := make([]string, len(rows))
+	var pkName string
+	if t := m.Model().Table(table); t != nil && t.PrimaryKeyColumn() != nil && t.PrimaryKeyColumn().IsId {
+		pkName = t.PrimaryKeyColumn().DbName
+	}
+	if pkName != "" {
+		var err error
+		if rows, err = m.addIds(ctx, table, pkName, rows, ids); err != nil {
+			return nil, err
+		}
+	}
+	for _, g := range sql2.GroupBatchRows(rows, maxBatchArgs) {
+		sql, args := sql2.GenerateBatchInsert(m, table, g, rows)
+		if pkName != "" {
+			// identity columns declared as GENERATED ALWAYS otherwise reject the ids
+			sql = strings.Replace(sql, "\nVALUES ", "\nOVERRIDING SYSTEM VALUE VALUES ", 1)
+		}
+		if _, err := m.Exec(ctx, sql, args...); err != nil {
+			return nil, convertError(table, err)
+		}
+	}
+	return ids, nil
+}
+
+// addIds returns a copy of rows in which each row has a value for the pkName column, and puts those values in ids.
+// Rows that do not have a value get the next value of the column's sequence.
+func (m *DB) addIds(ctx context.Context, table string, pkName string, rows []map[string]interface{}, ids []string) ([]map[string]interface{}, error) {
+	var count int
+	for _, row := range rows {
+		if _, ok := row[pkName]; !ok {
+			count++
+		}
+	}
+	var newIds []int64
+	if count > 0 {
+		r, err := m.Query(ctx, "SELECT nextval(pg_get_serial_sequence($1, $2)) FROM generate_series(1, $3)", iq(table), pkName, count)
+		if err != nil {
+			return nil, convertError(table, err)
+		}
+		defer r.Close()
+		for r.Next() {
+			var id int64
+			if err = r.Scan(&id); err != nil {
+				return nil, err
+			}
+			newIds = append(newIds, id)
+		}
+		if err = r.Err(); err != nil {
+			return nil, convertError(table, err)
+		}
+		if len(newIds) != count {
+			return nil, fmt.Errorf("could not get %d ids for table %s", count, table)
+		}
+	}
+
+	return withIds(rows, pkName, newIds, ids), nil
+}
+
+// withIds returns a copy of rows in which the rows that have no value for the pkName column are given the next
+// of newIds, and puts the primary key of each row in ids.
+func withIds(rows []map[string]interface{}, pkName string, newIds []int64, ids []string) []map[string]interface{} {
+	rows2 := make([]map[string]interface{}, len(rows))
+	for i, row := range rows {
+		if v, ok := row[pkName]; ok {
+			ids[i] = fmt.Sprint(v)
+			rows2[i] = row
+			continue
+		}
+		row2 := make(map[string]interface{}, len(row)+1)
+		for k, v := range row {
+			row2[k] = v
+		}
+		row2[pkName] = newIds[0]
+		ids[i] = fmt.Sprint(newIds[0])
+		newIds = newIds[1:]
+		rows2[i] = row2
+	}
+	return rows2
+}
+
+// Upsert inserts the rows using multi-row insert statements, and updates the existing records that
+// conflict with them on conflictColumns, unless their values in scopeColumns are different.
+func (m *DB) Upsert(ctx context.Context, table string, rows []map[string]interface{}, conflictColumns []string, scopeColumns []string) error {
+	if len(conflictColumns) == 0 {
+		panic("Upsert requires the conflict columns")
+	}
+	var conflicts []string
+	for _, col := range conflictColumns {
+		conflicts = append(conflicts, iq(col))
+	}
+	var scopes []string
+	for _, col := range scopeColumns {
+		scopes = append(scopes, iq(table)+"."+iq(col)+" IS NOT DISTINCT FROM excluded."+iq(col))
+	}
+	for _, g := range sql2.GroupBatchRows(rows, maxBatchArgs) {
+		sql, args := sql2.GenerateBatchInsert(m, table, g, rows)
+		sql += "ON CONFLICT (" + strings.Join(conflicts, ",") + ") "
+		if cols := sql2.UpsertUpdateColumns(g, conflictColumns); cols == nil {
+			sql += "DO NOTHING"
+		} else {
+			var sets []string
+			for _, col := range cols {
+				sets = append(sets, iq(col)+"=excluded."+iq(col))
+			}
+			sql += "DO UPDATE SET " + strings.Join(sets, ", ")
+			if scopes != nil {
+				sql += " WHERE " + strings.Join(scopes, " AND ")
+			}
+		}
+		if _, err := m.Exec(ctx, sql, args...); err != nil {
+			return convertError(table, err)
+		}
+	}
+	return nil
+}
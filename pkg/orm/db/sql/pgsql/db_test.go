@@ -0,0 +1,139 @@
+package pgsql
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/goradd/goradd/pkg/orm/db"
+	sql2 "github.com/goradd/goradd/pkg/orm/db/sql"
+	"github.com/goradd/goradd/pkg/orm/db/sql/sqltest"
+	. "github.com/goradd/goradd/pkg/orm/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArrayOperationSql(t *testing.T) {
+	m := &DB{}
+	assert.Equal(t, `($1 = ANY("t"."tags"))`, m.OperationSql(OpArrayAny, []string{`"t"."tags"`, "$1"}))
+	assert.Equal(t, `($1 = ALL("t"."tags"))`, m.OperationSql(OpArrayAll, []string{`"t"."tags"`, "$1"}))
+	assert.Equal(t, `("t"."tags" && $1)`, m.OperationSql(OpArrayOverlaps, []string{`"t"."tags"`, "$1"}))
+	assert.Equal(t, `("t"."tags" @> $1)`, m.OperationSql(OpArrayContains, []string{`"t"."tags"`, "$1"}))
+}
+
+func TestNativeEnumColumnSql(t *testing.T) {
+	desc := db.DatabaseDescription{
+		Tables: []db.TableDescription{
+			getEnumTypeDescription("public.mood", []string{"sad", "happy"}),
+			{
+				Name: "public.person",
+				Columns: []db.ColumnDescription{
+					{Name: "id", GoType: "string", IsId: true, IsPk: true},
+					{Name: "name", GoType: "string"},
+					{Name: "mood", NativeType: "public.mood", GoType: "int", SubType: "enum",
+						ForeignKey: &db.ForeignKeyDescription{ReferencedTable: "public.mood", ReferencedColumn: "id"}},
+				},
+			},
+		},
+	}
+	m := &DB{model: db.NewModel("test", "test", "_id", "_enum", true, desc)}
+
+	assert.Equal(t, `array_position(enum_range("t"."mood"), "t"."mood")`, m.ColumnReadSql("public.person", "mood", `"t"."mood"`))
+	assert.Equal(t, `(enum_range(NULL::"public"."mood"))[$1]`, m.ColumnWriteSql("public.person", "mood", "$1"))
+	assert.Equal(t, `"t"."name"`, m.ColumnReadSql("public.person", "name", `"t"."name"`))
+	assert.Equal(t, "$1", m.ColumnWriteSql("public.person", "name", "$1"))
+}
+
+func TestFullTextSql(t *testing.T) {
+	desc := db.DatabaseDescription{
+		Tables: []db.TableDescription{
+			{
+				Name: "public.project",
+				Columns: []db.ColumnDescription{
+					{Name: "id", GoType: "string", IsId: true, IsPk: true},
+					{Name: "name", GoType: "string"},
+					{Name: "description", GoType: "string"},
+				},
+				Indexes: []db.IndexDescription{
+					{Name: "project_text_idx", IsFullText: true, SearchConfig: "simple", ColumnNames: []string{"name", "description"}},
+				},
+			},
+		},
+	}
+	m := &DB{model: db.NewModel("test", "test", "_id", "_enum", true, desc)}
+
+	// columns are put in index order so that the index is used
+	assert.Equal(t,
+		`(to_tsvector('simple', coalesce("t"."name", '') || ' ' || coalesce("t"."description", '')) @@ plainto_tsquery('simple', $1))`,
+		m.FullTextSql(false, "public.project", []string{"description", "name"}, []string{`"t"."description"`, `"t"."name"`}, "$1"))
+	assert.Equal(t,
+		`ts_rank(to_tsvector("t"."name"), plainto_tsquery($1))`,
+		m.FullTextSql(true, "public.project", []string{"name"}, []string{`"t"."name"`}, "$1"))
+
+	assert.Equal(t,
+		[]string{`CREATE INDEX "project_name_text_idx" ON "public"."project" USING GIN (to_tsvector('english', "name"))`},
+		migrationDialect{}.CreateFullTextIndexSql("public.project", "project_name_text_idx", db.IndexDescription{IsFullText: true, ColumnNames: []string{"name"}}))
+
+	idx := fullTextIndexDescription(pgTable{columns: []pgColumn{{name: "id"}, {name: "name"}, {name: "description"}}}, pgIndex{
+		name:       "project_text_idx",
+		definition: `CREATE INDEX project_text_idx ON public.project USING gin (to_tsvector('simple'::regconfig, (((COALESCE(name, ''::character varying))::text || ' '::text) || COALESCE(description, ''::text))))`,
+	})
+	assert.Equal(t, &db.IndexDescription{Name: "project_text_idx", IsFullText: true, SearchConfig: "simple", ColumnNames: []string{"name", "description"}}, idx)
+}
+
+func TestWithIds(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"name": "a"},
+		{"name": "b", "id": 5},
+		{"name": "c"},
+	}
+	ids := make([]string, len(rows))
+	rows2 := withIds(rows, "id", []int64{11, 12}, ids)
+	assert.Equal(t, []string{"11", "5", "12"}, ids)
+	assert.Equal(t, []map[string]interface{}{
+		{"name": "a", "id": int64(11)},
+		{"name": "b", "id": 5},
+		{"name": "c", "id": int64(12)},
+	}, rows2)
+	assert.Equal(t, map[string]interface{}{"name": "a"}, rows[0], "the rows passed in are not changed")
+}
+
+func TestInsertBatch(t *testing.T) {
+	desc := db.DatabaseDescription{
+		Tables: []db.TableDescription{
+			{
+				Name: "public.person",
+				Columns: []db.ColumnDescription{
+					{Name: "id", GoType: "string", IsId: true, IsPk: true},
+					{Name: "name", GoType: "string"},
+				},
+			},
+		},
+	}
+	c := &sqltest.Conn{Rows: func(_ string, args []driver.Value) ([]string, [][]driver.Value) {
+		var values [][]driver.Value
+		for i := int64(1); i <= args[2].(int64); i++ {
+			values = append(values, []driver.Value{10 + i})
+		}
+		return []string{"nextval"}, values
+	}}
+	m := &DB{
+		DbHelper: sql2.NewSqlDb("test", c.DB()),
+		model:    db.NewModel("test", "test", "_id", "_enum", true, desc),
+	}
+	ids, err := m.InsertBatch(context.Background(), "public.person", []map[string]interface{}{
+		{"name": "a"},
+		{"name": "b", "id": 5},
+		{"name": "c"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"11", "5", "12"}, ids)
+
+	assert.Equal(t, []string{
+		"SELECT nextval(pg_get_serial_sequence($1, $2)) FROM generate_series(1, $3)",
+		"INSERT INTO \"public\".\"person\"(\"id\",\"name\")\nOVERRIDING SYSTEM VALUE VALUES ($1,$2),\n($3,$4),\n($5,$6)\n",
+	}, c.Statements())
+	args := c.Args()
+	assert.Equal(t, []driver.Value{`"public"."person"`, "id", int64(2)}, args[0])
+	assert.Equal(t, []driver.Value{int64(11), "a", int64(5), "b", int64(12), "c"}, args[1])
+}
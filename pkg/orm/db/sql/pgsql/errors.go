@@ -0,0 +1,25 @@
+package pgsql
+
+import (
+	"errors"
+	"github.com/goradd/goradd/pkg/orm/db"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// convertError converts the Postgres errors that an application might want to respond to into the
+// matching db error types. Other errors are returned unchanged.
+func convertError(table string, err error) error {
+	var pe *pgconn.PgError
+	if err == nil || !errors.As(err, &pe) {
+		return err
+	}
+	switch pe.Code {
+	case "23505": // unique_violation
+		return db.UniqueViolationError{Table: table, Err: err}
+	case "23503": // foreign_key_violation
+		return db.ForeignKeyViolationError{Table: table, Err: err}
+	case "40001", "40P01", "55P03": // serialization_failure, deadlock_detected, lock_not_available
+		return db.DeadlockError{Table: table, Err: err}
+	}
+	return err
+}
@@ -0,0 +1,358 @@
+package pgsql
+
+import (
+	"encoding/hex"
+	"fmt"
+	"github.com/goradd/goradd/pkg/orm/db"
+	sql2 "github.com/goradd/goradd/pkg/orm/db/sql"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GenerateMigration returns the SQL statements that will change a Postgres database with the structure
+// described by from so that it has the structure described by to.
+// See sql.GenerateMigration for details.
+func GenerateMigration(from, to db.DatabaseDescription) []string {
+	return sql2.GenerateMigration(migrationDialect{}, from, to)
+}
+
+// MigrationSql returns the SQL statements that will change the structure of the database so that it matches desired,
+// which is usually read from a schema file using db.ReadDescriptionFile.
+func (m *DB) MigrationSql(desired db.DatabaseDescription, options Options) []string {
+	return GenerateMigration(m.Describe(options), desired)
+}
+
+// migrationDialect generates Postgres specific DDL for sql.GenerateMigration.
+type migrationDialect struct{}
+
+func (d migrationDialect) QuoteIdentifier(v string) string {
+	return iq(v)
+}
+
+func (d migrationDialect) LiteralSql(v interface{}) string {
+	switch v2 := v.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return quoteString(v2)
+	case []byte:
+		return `'\x` + hex.EncodeToString(v2) + `'`
+	case bool:
+		if v2 {
+			return "TRUE"
+		}
+		return "FALSE"
+	case float32:
+		return strconv.FormatFloat(float64(v2), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v2, 'g', -1, 64)
+	case time.Time:
+		return quoteString(v2.UTC().Format("2006-01-02 15:04:05.999999Z07:00"))
+	default:
+		return fmt.Sprint(v2)
+	}
+}
+
+func (d migrationDialect) ColumnSql(col db.ColumnDescription) sql2.ColumnSql {
+	c := sql2.ColumnSql{
+		Type:          columnType(col),
+		NotNull:       !col.IsNullable,
+		AutoIncrement: col.IsId,
+		Comment:       sql2.CommentWithOptions(col.Comment, col.Options),
+	}
+	if !col.IsId && col.DefaultValue != nil {
+		c.Default = d.defaultSql(col)
+	}
+	return c
+}
+
+func (d migrationDialect) defaultSql(col db.ColumnDescription) string {
+	switch v := col.DefaultValue.(type) {
+	case string:
+		if col.GoType == "time.Time" && v == "now" {
+			return "CURRENT_TIMESTAMP"
+		}
+	case time.Time:
+		switch col.SubType {
+		case "date":
+			return quoteString(v.Format("2006-01-02"))
+		case "time":
+			return quoteString(v.Format("15:04:05.999999"))
+		}
+	}
+	return d.LiteralSql(col.DefaultValue)
+}
+
+// columnDefinition returns the sql that defines the column in a CREATE TABLE or ALTER TABLE statement.
+func (d migrationDialect) columnDefinition(col db.ColumnDescription) string {
+	c := d.ColumnSql(col)
+	s := iq(col.Name) + " " + c.Type
+	if c.NotNull {
+		s += " NOT NULL"
+	}
+	if c.Default != "" {
+		s += " DEFAULT " + c.Default
+	}
+	if c.AutoIncrement {
+		s += " GENERATED BY DEFAULT AS IDENTITY"
+	}
+	return s
+}
+
+func (d migrationDialect) CreateTableSql(t db.TableDescription) []string {
+	var lines []string
+	var pks []string
+	for _, col := range t.Columns {
+		lines = append(lines, "  "+d.columnDefinition(col))
+		if col.IsPk {
+			pks = append(pks, iq(col.Name))
+		}
+	}
+	if pks != nil {
+		lines = append(lines, "  PRIMARY KEY ("+strings.Join(pks, ", ")+")")
+	}
+	stmts := []string{"CREATE TABLE " + iq(t.Name) + " (\n" + strings.Join(lines, ",\n") + "\n)"}
+	if comment := sql2.CommentWithOptions(t.Comment, t.Options); comment != "" {
+		stmts = append(stmts, d.TableCommentSql(t.Name, comment)...)
+	}
+	for _, col := range t.Columns {
+		if comment := sql2.CommentWithOptions(col.Comment, col.Options); comment != "" {
+			stmts = append(stmts, columnCommentSql(t.Name, col.Name, comment))
+		}
+	}
+	return stmts
+}
+
+func (d migrationDialect) AddColumnSql(table string, col db.ColumnDescription) []string {
+	stmts := []string{"ALTER TABLE " + iq(table) + " ADD COLUMN " + d.columnDefinition(col)}
+	if comment := sql2.CommentWithOptions(col.Comment, col.Options); comment != "" {
+		stmts = append(stmts, columnCommentSql(table, col.Name, comment))
+	}
+	return stmts
+}
+
+func (d migrationDialect) AlterColumnSql(table string, from, to db.ColumnDescription) (stmts []string) {
+	f := d.ColumnSql(from)
+	t := d.ColumnSql(to)
+	alter := "ALTER COLUMN " + iq(to.Name) + " "
+	var clauses []string
+
+	if f.Type != t.Type {
+		clauses = append(clauses, alter+"TYPE "+t.Type+" USING "+iq(to.Name)+"::"+t.Type)
+	}
+	if f.NotNull != t.NotNull {
+		if t.NotNull {
+			clauses = append(clauses, alter+"SET NOT NULL")
+		} else {
+			clauses = append(clauses, alter+"DROP NOT NULL")
+		}
+	}
+	if f.AutoIncrement && !t.AutoIncrement {
+		// serial columns use a default, and identity columns do not
+		clauses = append(clauses, alter+"DROP IDENTITY IF EXISTS", alter+"DROP DEFAULT")
+	}
+	if f.Default != t.Default || (f.AutoIncrement && !t.AutoIncrement) {
+		if t.Default != "" {
+			clauses = append(clauses, alter+"SET DEFAULT "+t.Default)
+		} else if !f.AutoIncrement {
+			clauses = append(clauses, alter+"DROP DEFAULT")
+		}
+	}
+	if !f.AutoIncrement && t.AutoIncrement {
+		clauses = append(clauses, alter+"ADD GENERATED BY DEFAULT AS IDENTITY")
+	}
+	if clauses != nil {
+		stmts = append(stmts, "ALTER TABLE "+iq(table)+" "+strings.Join(clauses, ", "))
+	}
+	if f.Comment != t.Comment {
+		stmts = append(stmts, columnCommentSql(table, to.Name, t.Comment))
+	}
+	return
+}
+
+func (d migrationDialect) AlterPrimaryKeySql(table string, from, to []string) []string {
+	var clauses []string
+	if len(from) > 0 {
+		// This is the name Postgres gives primary keys by default
+		clauses = append(clauses, "DROP CONSTRAINT "+iq(unqualifiedName(table)+"_pkey"))
+	}
+	if len(to) > 0 {
+		var pks []string
+		for _, pk := range to {
+			pks = append(pks, iq(pk))
+		}
+		clauses = append(clauses, "ADD PRIMARY KEY ("+strings.Join(pks, ", ")+")")
+	}
+	return []string{"ALTER TABLE " + iq(table) + " " + strings.Join(clauses, ", ")}
+}
+
+func (d migrationDialect) TableCommentSql(table string, comment string) []string {
+	return []string{"COMMENT ON TABLE " + iq(table) + " IS " + commentLiteral(comment)}
+}
+
+func (d migrationDialect) DropForeignKeySql(table string, name string) []string {
+	return []string{"ALTER TABLE " + iq(table) + " DROP CONSTRAINT " + iq(name)}
+}
+
+func (d migrationDialect) CreateEnumTypeSql(name string, labels []string) []string {
+	var values []string
+	for _, label := range labels {
+		values = append(values, quoteString(label))
+	}
+	return []string{"CREATE TYPE " + iq(name) + " AS ENUM (" + strings.Join(values, ", ") + ")"}
+}
+
+func (d migrationDialect) AddEnumValueSql(name string, label string) []string {
+	return []string{"ALTER TYPE " + iq(name) + " ADD VALUE " + quoteString(label)}
+}
+
+func (d migrationDialect) DropEnumTypeSql(name string) []string {
+	return []string{"DROP TYPE " + iq(name)}
+}
+
+func (d migrationDialect) DropIndexSql(table string, name string) []string {
+	// Unique indexes might belong to a unique constraint, in which case dropping the constraint drops the index.
+	return []string{
+		"ALTER TABLE " + iq(table) + " DROP CONSTRAINT IF EXISTS " + iq(name),
+		"DROP INDEX IF EXISTS " + iq(schemaName(table)+name),
+	}
+}
+
+func (d migrationDialect) CreateFullTextIndexSql(table string, name string, idx db.IndexDescription) []string {
+	var cols []string
+	for _, c := range idx.ColumnNames {
+		cols = append(cols, iq(c))
+	}
+	return []string{"CREATE INDEX " + iq(name) + " ON " + iq(table) + " USING GIN (" + tsvectorSql(searchConfig(idx.SearchConfig), cols) + ")"}
+}
+
+// defaultSearchConfig is the text search configuration of full text indexes that do not specify one.
+// Postgres requires a configuration in the index, so that the index does not depend on the settings of the database.
+const defaultSearchConfig = "english"
+
+func searchConfig(config string) string {
+	if config == "" {
+		return defaultSearchConfig
+	}
+	return config
+}
+
+// tsvectorSql returns the sql that converts the text in the given columns to a tsvector. Multiple columns
+// are joined with spaces, treating NULLs as empty strings. If config is empty, the default text search
+// configuration of the database is used.
+func tsvectorSql(config string, columnSql []string) string {
+	expr := columnSql[0]
+	if len(columnSql) > 1 {
+		var parts []string
+		for _, c := range columnSql {
+			parts = append(parts, "coalesce("+c+", '')")
+		}
+		expr = strings.Join(parts, " || ' ' || ")
+	}
+	if config == "" {
+		return "to_tsvector(" + expr + ")"
+	}
+	return "to_tsvector(" + quoteString(config) + ", " + expr + ")"
+}
+
+func columnCommentSql(table string, column string, comment string) string {
+	return "COMMENT ON COLUMN " + iq(table) + "." + iq(column) + " IS " + commentLiteral(comment)
+}
+
+func commentLiteral(comment string) string {
+	if comment == "" {
+		return "NULL"
+	}
+	return quoteString(comment)
+}
+
+// columnType returns the Postgres type of the column. The native type is used if it is a Postgres type,
+// and otherwise a type is chosen based on the Go type.
+func columnType(col db.ColumnDescription) string {
+	if col.SubType == "enum" {
+		return iq(col.NativeType)
+	}
+
+	switch col.NativeType {
+	case "integer", "int":
+		return "integer"
+	case "smallint", "bigint", "real", "double precision", "boolean", "date", "bytea", "text", "numeric", "json", "jsonb", "uuid",
+		"time without time zone", "time with time zone", "timestamp without time zone", "timestamp with time zone",
+		"smallint[]", "integer[]", "bigint[]", "text[]", "character varying[]":
+		return col.NativeType
+	case "character varying", "character":
+		if col.MaxCharLength > 0 {
+			return fmt.Sprintf("%s(%d)", col.NativeType, col.MaxCharLength)
+		}
+		return col.NativeType
+	}
+
+	switch col.GoType {
+	case "int":
+		return "integer"
+	case "uint", "int64", "uint64":
+		return "bigint"
+	case "float32":
+		return "real"
+	case "float64":
+		return "double precision"
+	case "bool":
+		return "boolean"
+	case "[]byte":
+		return "bytea"
+	case "json.RawMessage":
+		return "jsonb"
+	case "decimal.Decimal":
+		return "numeric"
+	case "uuid.UUID":
+		return "uuid"
+	case "[]string":
+		return "text[]"
+	case "[]int":
+		return "integer[]"
+	case "[]int64":
+		return "bigint[]"
+	case "time.Time":
+		switch col.SubType {
+		case "date":
+			return "date"
+		case "time":
+			return "time without time zone"
+		case "timestamp":
+			return "timestamp with time zone"
+		default:
+			return "timestamp without time zone"
+		}
+	}
+
+	// strings
+	if col.IsId {
+		return "integer" // ids are reported as strings, but are stored as integers
+	}
+	if col.MaxCharLength == 0 || col.MaxCharLength > 10485760 {
+		return "text"
+	}
+	return fmt.Sprintf("character varying(%d)", col.MaxCharLength)
+}
+
+// quoteString returns s as a quoted Postgres string literal.
+func quoteString(s string) string {
+	return "'" + strings.ReplaceAll(s, `'`, `''`) + "'"
+}
+
+// unqualifiedName returns the name without its schema.
+func unqualifiedName(name string) string {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// schemaName returns the schema of the name followed by a period, or an empty string if the name has no schema.
+func schemaName(name string) string {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		return name[:i+1]
+	}
+	return ""
+}
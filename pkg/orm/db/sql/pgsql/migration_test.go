@@ -0,0 +1,80 @@
+package pgsql
+
+import (
+	"testing"
+
+	"github.com/goradd/goradd/pkg/orm/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateMigration(t *testing.T) {
+	from := db.DatabaseDescription{
+		Tables: []db.TableDescription{
+			{
+				Name: "public.person",
+				Columns: []db.ColumnDescription{
+					{Name: "id", NativeType: "integer", GoType: "int", IsId: true, IsPk: true, DefaultValue: 0},
+					{Name: "name", NativeType: "character varying", GoType: "string", MaxCharLength: 50},
+					{Name: "age", NativeType: "integer", GoType: "int", IsNullable: true},
+				},
+				Indexes: []db.IndexDescription{{Name: "person_name_key", IsUnique: true, ColumnNames: []string{"name"}}},
+			},
+		},
+	}
+	assert.Empty(t, GenerateMigration(from, from))
+
+	to := db.DatabaseDescription{
+		Tables: []db.TableDescription{
+			{
+				Name:    "public.person",
+				Comment: "People",
+				Columns: []db.ColumnDescription{
+					{Name: "id", GoType: "string", IsId: true, IsPk: true},
+					{Name: "name", GoType: "string", MaxCharLength: 50},
+					{Name: "age", GoType: "int64", DefaultValue: int64(21)},
+				},
+			},
+		},
+	}
+	assert.Equal(t, []string{
+		`ALTER TABLE "public"."person" DROP CONSTRAINT IF EXISTS "person_name_key"`,
+		`DROP INDEX IF EXISTS "public"."person_name_key"`,
+		`ALTER TABLE "public"."person" ALTER COLUMN "age" TYPE bigint USING "age"::bigint, ALTER COLUMN "age" SET NOT NULL, ALTER COLUMN "age" SET DEFAULT 21`,
+		`COMMENT ON TABLE "public"."person" IS 'People'`,
+	}, GenerateMigration(from, to))
+}
+
+func TestGenerateMigrationNativeEnum(t *testing.T) {
+	mood := func(labels ...string) db.TableDescription {
+		return getEnumTypeDescription("public.mood", labels)
+	}
+	person := db.TableDescription{
+		Name: "public.person",
+		Columns: []db.ColumnDescription{
+			{Name: "id", GoType: "string", IsId: true, IsPk: true},
+			{Name: "mood", NativeType: "public.mood", GoType: "int", SubType: "enum", DefaultValue: 2,
+				ForeignKey: &db.ForeignKeyDescription{ReferencedTable: "public.mood", ReferencedColumn: "id"}},
+			{Name: "tags", GoType: "[]string", IsNullable: true},
+		},
+	}
+	to := db.DatabaseDescription{Tables: []db.TableDescription{mood("sad", "happy"), person}}
+	assert.Equal(t, []string{
+		`CREATE TYPE "public"."mood" AS ENUM ('sad', 'happy')`,
+		"CREATE TABLE \"public\".\"person\" (\n" +
+			"  \"id\" integer NOT NULL GENERATED BY DEFAULT AS IDENTITY,\n" +
+			"  \"mood\" \"public\".\"mood\" NOT NULL DEFAULT 'happy',\n" +
+			"  \"tags\" text[],\n" +
+			"  PRIMARY KEY (\"id\")\n)",
+	}, GenerateMigration(db.DatabaseDescription{}, to))
+
+	from := to
+	to = db.DatabaseDescription{Tables: []db.TableDescription{mood("sad", "happy", "angry"), person}}
+	assert.Equal(t, []string{
+		`ALTER TYPE "public"."mood" ADD VALUE 'angry'`,
+	}, GenerateMigration(from, to))
+
+	assert.Equal(t, []string{
+		`DROP TABLE "public"."person"`,
+		`DROP TYPE "public"."mood"`,
+	}, GenerateMigration(to, db.DatabaseDescription{}))
+}
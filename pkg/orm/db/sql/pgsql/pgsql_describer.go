@@ -13,6 +13,8 @@ import (
 	"github.com/goradd/maps"
 	"log"
 	"math"
+	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -41,6 +43,9 @@ type pgColumn struct {
 	charLen         int
 	characterMaxLen sql.NullInt64
 	isIdentity      string
+	udtSchema       string
+	udtName         string
+	enumLabels      []string // the labels of a native enum type, in order
 	comment         string
 	options         map[string]interface{}
 }
@@ -53,6 +58,8 @@ type pgIndex struct {
 	tableName   string
 	tableSchema string
 	columnName  string
+	method      string // the access method of the index, like btree or gin
+	definition  string // the CREATE INDEX statement that would create the index
 }
 
 type pgForeignKey struct {
@@ -68,12 +75,35 @@ type pgForeignKey struct {
 }
 
 func (m *DB) Analyze(options Options) {
-	rawTables := m.getRawTables(options)
-	description := m.descriptionFromRawTables(rawTables, options)
-	m.model = db.NewModel(m.DbKey(), m.databaseName, options.ForeignKeySuffix, options.EnumTableSuffix, !options.UseQualifiedNames, description)
+	m.AnalyzeDescription(m.Describe(options), options)
 }
 
-func (m *DB) getRawTables(options Options) map[string]pgTable {
+// Describe reads the structure of the database and returns it as a description.
+// Pass the result to db.WriteDescriptionFile to save it as a schema file.
+func (m *DB) Describe(options Options) db.DatabaseDescription {
+	enumTypes := m.getEnumTypes(options.Schemas)
+	rawTables := m.getRawTables(options, enumTypes)
+	return m.descriptionFromRawTables(rawTables, enumTypes, options)
+}
+
+// AnalyzeDescription builds the model used for code generation from the given description
+// rather than from the structure of the database.
+func (m *DB) AnalyzeDescription(desc db.DatabaseDescription, options Options) {
+	m.model = db.NewModel(m.DbKey(), m.databaseName, options.ForeignKeySuffix, options.EnumTableSuffix, !options.UseQualifiedNames, desc)
+}
+
+// AnalyzeFile builds the model used for code generation from the schema file at path.
+// See db.ReadDescriptionFile for the format of the file. It panics if the file cannot be read
+// or is not valid.
+func (m *DB) AnalyzeFile(path string, options Options) {
+	desc, err := db.ReadDescriptionFile(path)
+	if err != nil {
+		panic(err.Error())
+	}
+	m.AnalyzeDescription(desc, options)
+}
+
+func (m *DB) getRawTables(options Options, enumTypes map[string][]string) map[string]pgTable {
 	var tableMap = make(map[string]pgTable)
 
 	tables, schemas2 := m.getTables(options.Schemas)
@@ -107,6 +137,11 @@ func (m *DB) getRawTables(options Options) map[string]pgTable {
 		if err2 != nil {
 			return nil
 		}
+		for i, col := range columns {
+			if col.dataType == "USER-DEFINED" {
+				columns[i].enumLabels = enumTypes[col.udtSchema+"."+col.udtName]
+			}
+		}
 
 		table.indexes = indexes[tableIndex]
 		table.columns = columns
@@ -174,6 +209,50 @@ func (m *DB) getTables(schemas []string) ([]pgTable, []string) {
 	return tables, schemaMap.Values()
 }
 
+// getEnumTypes returns the labels of the native enum types, in order, keyed by the name of the type
+// qualified by its schema.
+func (m *DB) getEnumTypes(schemas []string) map[string][]string {
+	var schema, typeName, label string
+	enumTypes := make(map[string][]string)
+
+	stmt := `
+	SELECT
+	n.nspname,
+	t.typname,
+	e.enumlabel
+	FROM
+	pg_catalog.pg_type t
+	JOIN pg_catalog.pg_enum e ON e.enumtypid = t.oid
+	JOIN pg_catalog.pg_namespace n ON n.oid = t.typnamespace
+	WHERE`
+
+	if schemas != nil {
+		stmt += fmt.Sprintf(` n.nspname IN ('%s')`, strings.Join(schemas, `','`))
+	} else {
+		stmt += ` n.nspname NOT IN ('pg_catalog', 'information_schema')`
+	}
+	stmt += `
+	ORDER BY
+	n.nspname, t.typname, e.enumsortorder`
+
+	rows, err := m.SqlDb().Query(stmt)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err = rows.Scan(&schema, &typeName, &label); err != nil {
+			log.Fatal(err)
+		}
+		name := schema + "." + typeName
+		enumTypes[name] = append(enumTypes[name], label)
+	}
+	if err = rows.Err(); err != nil {
+		log.Fatal(err)
+	}
+	return enumTypes
+}
+
 func (m *DB) getColumns(table string, schema string) (columns []pgColumn, err error) {
 
 	s := fmt.Sprintf(`
@@ -184,6 +263,8 @@ func (m *DB) getColumns(table string, schema string) (columns []pgColumn, err er
 	c.data_type,
 	c.character_maximum_length,
 	c.is_identity,
+	c.udt_schema,
+	c.udt_name,
 	pgd.description
 FROM
 	information_schema.columns as c
@@ -213,7 +294,7 @@ ORDER BY
 	for rows.Next() {
 		col = pgColumn{}
 		var descr sql.NullString
-		err = rows.Scan(&col.name, &col.defaultValue, &col.isNullable, &col.dataType, &col.characterMaxLen, &col.isIdentity, &descr)
+		err = rows.Scan(&col.name, &col.defaultValue, &col.isNullable, &col.dataType, &col.characterMaxLen, &col.isIdentity, &col.udtSchema, &col.udtName, &descr)
 		if err != nil {
 			log.Fatal(err)
 			return nil, err
@@ -246,9 +327,12 @@ func (m *DB) getIndexes(schemas []string) (indexes map[string][]pgIndex, err2 er
        tnsp.nspname as table_schema,
 	   pgi.indisunique,
 	   pgi.indisprimary,
-	   a.attname as column_name
+	   a.attname as column_name,
+	   am.amname as method,
+	   pg_get_indexdef(pgi.indexrelid) as definition
 from pg_index pgi
   join pg_class idx on idx.oid = pgi.indexrelid
+  join pg_am am on am.oid = idx.relam
   join pg_namespace insp on insp.oid = idx.relnamespace
   join pg_class tbl on tbl.oid = pgi.indrelid
   join pg_namespace tnsp on tnsp.oid = tbl.relnamespace
@@ -267,7 +351,7 @@ where
 
 	for rows.Next() {
 		index = pgIndex{}
-		err = rows.Scan(&index.name, &index.schema, &index.tableName, &index.tableSchema, &index.unique, &index.primary, &index.columnName)
+		err = rows.Scan(&index.name, &index.schema, &index.tableName, &index.tableSchema, &index.unique, &index.primary, &index.columnName, &index.method, &index.definition)
 		if err != nil {
 			log.Fatal(err)
 			return nil, err
@@ -429,16 +513,58 @@ func (m *DB) processTypeInfo(tableName string, column pgColumn, cd *db.ColumnDes
 		cd.GoType = ColTypeString.GoType()
 		cd.MaxCharLength = 65535
 
+	case "json", "jsonb":
+		cd.GoType = ColTypeJSON.GoType()
+
 	case "numeric":
-		// No native equivalent in Go.
-		// See the shopspring/decimal package for support.
-		// You will need to shepherd numbers into and out of string format to move data to the database.
-		cd.GoType = ColTypeString.GoType()
+		cd.GoType = ColTypeDecimal.GoType()
 		cd.MaxCharLength = uint64(column.characterMaxLen.Int64) + 3
 
+	case "uuid":
+		cd.GoType = ColTypeUUID.GoType()
+
 	case "year":
 		cd.GoType = ColTypeInteger.GoType()
 
+	case "ARRAY":
+		cd.NativeType = arrayType(column.udtName)
+		switch column.udtName {
+		case "_int2", "_int4":
+			cd.GoType = ColTypeIntegerSlice.GoType()
+		case "_int8":
+			cd.GoType = ColTypeInteger64Slice.GoType()
+		case "_text", "_varchar", "_bpchar":
+			cd.GoType = ColTypeStringSlice.GoType()
+		default:
+			log2.Warning("Column " + tableName + ":" + column.name + " is an array of " + column.udtName[1:] + ", which is not supported. It will be treated as a string.")
+			cd.GoType = ColTypeString.GoType()
+			cd.NativeType = column.dataType
+		}
+		return // array defaults are expressions
+
+	case "USER-DEFINED":
+		if column.enumLabels != nil {
+			// A native enum type is imported as an enum table, and the column refers to it.
+			typeName := column.udtSchema + "." + column.udtName
+			cd.GoType = ColTypeInteger.GoType()
+			cd.NativeType = typeName
+			cd.SubType = "enum"
+			cd.ForeignKey = &db.ForeignKeyDescription{
+				ReferencedTable:  typeName,
+				ReferencedColumn: "id",
+			}
+			if column.defaultValue.Valid {
+				label := strings.Trim(strings.Split(column.defaultValue.String, "::")[0], "'")
+				for i, l := range column.enumLabels {
+					if l == label {
+						cd.DefaultValue = i + 1
+					}
+				}
+			}
+			return
+		}
+		cd.GoType = ColTypeString.GoType()
+
 	default:
 		cd.GoType = ColTypeString.GoType()
 	}
@@ -447,10 +573,34 @@ func (m *DB) processTypeInfo(tableName string, column pgColumn, cd *db.ColumnDes
 	cd.DefaultValue = getDefaultValue(column.defaultValue, ColTypeFromGoTypeString(cd.GoType))
 }
 
-func (m *DB) descriptionFromRawTables(rawTables map[string]pgTable, options Options) db.DatabaseDescription {
+// arrayType returns the Postgres name of the array type with the given udt name, which is the name of the item type
+// preceded by an underscore.
+func arrayType(udtName string) string {
+	switch udtName {
+	case "_int2":
+		return "smallint[]"
+	case "_int4":
+		return "integer[]"
+	case "_int8":
+		return "bigint[]"
+	case "_text":
+		return "text[]"
+	case "_varchar":
+		return "character varying[]"
+	case "_bpchar":
+		return "character[]"
+	}
+	return strings.TrimPrefix(udtName, "_") + "[]"
+}
+
+func (m *DB) descriptionFromRawTables(rawTables map[string]pgTable, enumTypes map[string][]string, options Options) db.DatabaseDescription {
 
 	dd := db.DatabaseDescription{}
 
+	for _, typeName := range stringmap.SortedKeys(enumTypes) {
+		dd.Tables = append(dd.Tables, getEnumTypeDescription(typeName, enumTypes[typeName]))
+	}
+
 	keys := stringmap.SortedKeys(rawTables)
 	for _, tableName := range keys {
 		table := rawTables[tableName]
@@ -481,6 +631,35 @@ func (m *DB) descriptionFromRawTables(rawTables map[string]pgTable, options Opti
 	return dd
 }
 
+// fullTextIndexDescription returns the description of a GIN index on the to_tsvector of some columns,
+// which is what Postgres uses for full text search. The columns and search configuration are
+// found by parsing the definition of the index.
+func fullTextIndexDescription(t pgTable, idx pgIndex) *db.IndexDescription {
+	i := &db.IndexDescription{Name: idx.name, IsFullText: true}
+	def := idx.definition[strings.Index(idx.definition, "to_tsvector(")+len("to_tsvector("):]
+	if m := searchConfigRegexp.FindStringSubmatch(def); m != nil {
+		i.SearchConfig = m[1]
+	}
+	def = stringLiteralRegexp.ReplaceAllString(def, "")
+	for _, m := range identifierRegexp.FindAllStringSubmatch(def, -1) {
+		name := strings.ReplaceAll(m[1], `""`, `"`) + m[2]
+		if slices.Contains(i.ColumnNames, name) {
+			continue
+		}
+		for _, col := range t.columns {
+			if col.name == name {
+				i.ColumnNames = append(i.ColumnNames, name)
+				break
+			}
+		}
+	}
+	return i
+}
+
+var searchConfigRegexp = regexp.MustCompile(`^'([^']+)'::regconfig`)
+var stringLiteralRegexp = regexp.MustCompile(`'(?:[^']|'')*'`)
+var identifierRegexp = regexp.MustCompile(`"((?:[^"]|"")+)"|([A-Za-z_][A-Za-z0-9_$]*)`)
+
 func (m *DB) getTableDescription(t pgTable) db.TableDescription {
 	var columnDescriptions []db.ColumnDescription
 
@@ -492,13 +671,17 @@ func (m *DB) getTableDescription(t pgTable) db.TableDescription {
 	// Fill pkColumns map with the column names of all the pk columns
 	// Also file the indexes map with a list of columns for each index
 	for _, idx := range t.indexes {
-		if idx.primary {
+		if idx.method == "gin" && strings.Contains(idx.definition, "to_tsvector(") {
+			if _, ok := indexes[idx.name]; !ok {
+				indexes[idx.name] = fullTextIndexDescription(t, idx)
+			}
+		} else if idx.primary {
 			pkColumns[idx.columnName] = true
 		} else if i, ok2 := indexes[idx.name]; ok2 {
 			i.ColumnNames = append(i.ColumnNames, idx.columnName)
 			sort.Strings(i.ColumnNames) // make sure this list stays in a predictable order each time
 		} else {
-			i = &db.IndexDescription{IsUnique: idx.unique, ColumnNames: []string{idx.columnName}}
+			i = &db.IndexDescription{Name: idx.name, IsUnique: idx.unique, ColumnNames: []string{idx.columnName}}
 			indexes[idx.name] = i
 		}
 	}
@@ -600,14 +783,34 @@ ORDER BY
 	return td
 }
 
+// getEnumTypeDescription returns the description of a native enum type as an enum table.
+// The id of each value is its position in the type, starting with 1.
+func getEnumTypeDescription(typeName string, labels []string) db.TableDescription {
+	td := db.TableDescription{
+		Name: typeName,
+		Columns: []db.ColumnDescription{
+			{Name: "id", GoType: ColTypeInteger.GoType(), IsPk: true},
+			{Name: "name", GoType: ColTypeString.GoType()},
+		},
+		IsNativeEnum: true,
+	}
+	for i, label := range labels {
+		td.EnumData = append(td.EnumData, map[string]interface{}{"id": i + 1, "name": label})
+	}
+	return td
+}
+
 func (m *DB) getColumnDescription(table pgTable, column pgColumn, isPk bool, isUnique bool) db.ColumnDescription {
 	cd := db.ColumnDescription{
 		Name: column.name,
 	}
 	m.processTypeInfo(table.name, column, &cd)
 
-	// treat auto incrementing values as id values
+	// treat auto incrementing values, and uuids generated by the database, as id values
 	cd.IsId = column.isIdentity == "YES" || (column.defaultValue.Valid && strings.Contains(column.defaultValue.String, "nextval"))
+	if isPk && column.dataType == "uuid" && column.defaultValue.Valid && strings.Contains(column.defaultValue.String, "uuid") {
+		cd.IsId = true // gen_random_uuid() or uuid_generate_v4()
+	}
 	cd.IsPk = isPk
 	cd.IsNullable = column.isNullable == "YES"
 	cd.IsUnique = isUnique
@@ -626,6 +829,7 @@ func (m *DB) getColumnDescription(table pgTable, column pgColumn, isPk bool, isU
 			ReferencedColumn: fk.referencedColumnName.String,
 			UpdateAction:     fkRuleToAction(fk.updateRule),
 			DeleteAction:     fkRuleToAction(fk.deleteRule),
+			ConstraintName:   fk.constraintName,
 		}
 	}
 
@@ -739,7 +943,7 @@ func getDefaultValue(sqlVal sql.NullString, typ GoColumnType) interface{} {
 		i, _ := strconv.Atoi(v)
 		return int64(i)
 	case ColTypeTime:
-		if v == "CURRENT_TIMESTAMP" {
+		if v == "CURRENT_TIMESTAMP" || v == "now()" {
 			return "now"
 		}
 		return time2.FromSqlDateTime(v).UTC()
@@ -750,7 +954,7 @@ func getDefaultValue(sqlVal sql.NullString, typ GoColumnType) interface{} {
 		i, _ := strconv.ParseFloat(v, 64)
 		return i
 	case ColTypeBool:
-		return v == "TRUE"
+		return strings.EqualFold(v, "TRUE")
 	default:
 		return nil
 	}
@@ -0,0 +1,130 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"github.com/goradd/goradd/pkg/log"
+	"strings"
+	"time"
+)
+
+// SetSlowQueryThreshold turns on logging of queries that take longer than d to run. If explain is true,
+// the query plan of each slow query is also captured with EXPLAIN, and put in the log and the profile entry.
+// Pass zero to turn off slow query logging.
+func (s *DbHelper) SetSlowQueryThreshold(d time.Duration, explain bool) {
+	s.slowQueryThreshold = d
+	s.explainSlowQueries = explain
+}
+
+// SetRepeatedQueryThreshold turns on detection of N+1 query problems. When the same sql, ignoring its
+// arguments, is run n times while serving one request, a warning is logged. This usually means objects are being
+// loaded one at a time in a loop, and that the loop should instead Join or Expand the related objects in one query.
+// Pass zero to turn off detection.
+func (s *DbHelper) SetRepeatedQueryThreshold(n int) {
+	s.repeatThreshold = n
+}
+
+// SetExplainPrefix sets the text put in front of a query to get its query plan. The default is "EXPLAIN ".
+// It is used by database implementations whose databases use a different syntax.
+func (s *DbHelper) SetExplainPrefix(prefix string) {
+	s.explainPrefix = prefix
+}
+
+// recordQuery does the slow query logging, N+1 detection and profiling of a query that was just run.
+func (s *DbHelper) recordQuery(ctx context.Context,
+	c *sqlContext,
+	typ string,
+	sql string,
+	args []interface{},
+	beginTime time.Time,
+	endTime time.Time,
+	succeeded bool) {
+
+	var e ProfileEntry
+	if s.slowQueryThreshold > 0 && endTime.Sub(beginTime) > s.slowQueryThreshold {
+		e.Slow = true
+		msg := fmt.Sprintf("Slow query on %s took %v: %s %v", s.dbKey, endTime.Sub(beginTime), sql, args)
+		if s.explainSlowQueries && succeeded {
+			e.Explain = s.explain(ctx, sql, args)
+			msg += "\n" + e.Explain
+		}
+		log.Warning(msg)
+	}
+
+	if c != nil && s.repeatThreshold > 0 {
+		c.Lock()
+		if c.queryCounts == nil {
+			c.queryCounts = make(map[string]int)
+		}
+		c.queryCounts[sql]++
+		e.Repeats = c.queryCounts[sql]
+		c.Unlock()
+		if e.Repeats == s.repeatThreshold {
+			log.Warningf("Possible N+1 query problem on %s. The following query has run %d times in one request: %s", s.dbKey, e.Repeats, sql)
+		}
+	}
+
+	if c != nil && s.profiling {
+		if args != nil {
+			for _, arg := range args {
+				sql = strings.TrimSpace(sql)
+				sql += fmt.Sprintf(",\n%#v", arg)
+			}
+		}
+		e.DbKey = s.dbKey
+		e.BeginTime = beginTime
+		e.EndTime = endTime
+		e.Typ = typ
+		e.Sql = sql
+		c.addProfile(e)
+	}
+}
+
+// explain returns the query plan of the given query as text, with a line for each row returned by EXPLAIN,
+// and the columns separated by tabs. If EXPLAIN returns more than one column, the first line holds the column names.
+//
+// The plan is gathered outside any transaction in progress, since the connection of the transaction may still
+// be reading the results of the query.
+func (s *DbHelper) explain(ctx context.Context, sql string, args []interface{}) string {
+	verb := strings.ToUpper(strings.SplitN(strings.TrimSpace(sql), " ", 2)[0])
+	switch verb {
+	case "SELECT", "UPDATE", "DELETE", "INSERT", "WITH":
+	default:
+		return ""
+	}
+
+	rows, err := s.db.QueryContext(ctx, s.explainPrefix+sql, args...)
+	if err != nil {
+		return "EXPLAIN failed: " + err.Error()
+	}
+	defer rows.Close()
+
+	columns, _ := rows.Columns()
+	var lines []string
+	if len(columns) > 1 {
+		lines = append(lines, strings.Join(columns, "\t"))
+	}
+	values := make([]interface{}, len(columns))
+	pointers := make([]interface{}, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	for rows.Next() {
+		if err = rows.Scan(pointers...); err != nil {
+			return "EXPLAIN failed: " + err.Error()
+		}
+		var fields []string
+		for _, v := range values {
+			switch v2 := v.(type) {
+			case nil:
+				fields = append(fields, "NULL")
+			case []byte:
+				fields = append(fields, string(v2))
+			default:
+				fields = append(fields, fmt.Sprint(v2))
+			}
+		}
+		lines = append(lines, strings.Join(fields, "\t"))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,179 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"github.com/goradd/goradd/pkg/cache"
+	"github.com/goradd/goradd/pkg/orm/broadcast"
+	db2 "github.com/goradd/goradd/pkg/orm/db"
+	. "github.com/goradd/goradd/pkg/orm/query"
+	"sync"
+)
+
+// queryCache holds the results of queries that were marked with Cache.
+//
+// Rather than finding and removing the entries that use a table when the table changes, each table has a version
+// number that is incremented when a change to the table is broadcast. Entries remember the versions of the tables they
+// were loaded from, and are ignored once any of those versions changes. The versions are captured before the query
+// is run, so a change that happens while the query is running will also invalidate the result.
+type queryCache struct {
+	sync.Mutex
+	lru      *cache.LruCache
+	versions map[string]uint64
+}
+
+type queryCacheEntry struct {
+	tables   []string
+	versions []uint64
+	result   interface{}
+}
+
+var theQueryCache *queryCache
+
+// EnableQueryCache turns on the cache used by queries marked with Cache. maxItemCount is the maximum number of
+// query results to keep, and ttlSeconds is how long a result that is not being used will be kept.
+//
+// Cached results are invalidated when a change to any of the tables in the query is broadcast by the broadcast
+// package, which the generated ORM does whenever it writes to the database. Writes made some other way, like by
+// executing sql directly or by another application, will not invalidate the cache, so only use the cache for data
+// that is changed through the ORM. Call this during app startup.
+func EnableQueryCache(maxItemCount int, ttlSeconds int64) {
+	theQueryCache = &queryCache{
+		lru:      cache.NewLruCache(maxItemCount, ttlSeconds),
+		versions: make(map[string]uint64),
+	}
+	broadcast.AddChangeListener(theQueryCache.invalidate)
+}
+
+func queryCacheTableKey(dbKey string, table string) string {
+	return dbKey + "." + table
+}
+
+// invalidate is called when a table changes.
+// If the change was made in a transaction, the cached results are invalidated after the transaction commits,
+// since until then other readers still see the data from before the change, and could cache it again.
+func (c *queryCache) invalidate(ctx context.Context, dbKey string, table string) {
+	key := queryCacheTableKey(dbKey, table)
+	if getSqlContext(ctx, dbKey).afterCommit(func() { c.bump(key) }) {
+		return
+	}
+	c.bump(key)
+}
+
+// bump increments the version of the table with the given cache key.
+func (c *queryCache) bump(key string) {
+	c.Lock()
+	c.versions[key]++
+	c.Unlock()
+}
+
+func (c *queryCache) tableVersions(tables []string) (versions []uint64) {
+	c.Lock()
+	for _, t := range tables {
+		versions = append(versions, c.versions[t])
+	}
+	c.Unlock()
+	return
+}
+
+// get returns the cached result for the key, or nil if there is no valid result.
+func (c *queryCache) get(key string) interface{} {
+	i := c.lru.Get(key)
+	if i == nil {
+		return nil
+	}
+	e := i.(queryCacheEntry)
+	versions := c.tableVersions(e.tables)
+	for j, v := range versions {
+		if v != e.versions[j] {
+			return nil
+		}
+	}
+	return e.result
+}
+
+func (c *queryCache) set(key string, tables []string, versions []uint64, result interface{}) {
+	c.lru.Set(key, queryCacheEntry{tables, versions, result})
+}
+
+type dbKeyer interface {
+	DbKey() string
+}
+
+type inTransactioner interface {
+	InTransaction(ctx context.Context) bool
+}
+
+// cachedQuery returns the result of a query marked with Cache, calling load to query the database if the result
+// is not in the cache. The join tree of the builder must already be built.
+func (b *Builder) cachedQuery(sql string, args []any, load func() interface{}) interface{} {
+	k, ok := b.db.(dbKeyer)
+	if !ok || theQueryCache == nil || !b.IsCached {
+		return load()
+	}
+	if t, ok := b.db.(inTransactioner); ok && t.InTransaction(b.Ctx) {
+		// The transaction may see changes that have not been committed
+		return load()
+	}
+
+	key := k.DbKey() + "\x00" + sql + "\x00" + fmt.Sprintf("%#v", args)
+	if result := theQueryCache.get(key); result != nil {
+		return result
+	}
+	tables := b.queryTables(k.DbKey())
+	versions := theQueryCache.tableVersions(tables)
+	result := load()
+	theQueryCache.set(key, tables, versions, result)
+	return result
+}
+
+// queryTables returns the cache keys of all the tables used by the query, including the tables in subqueries
+// and common table expressions.
+func (b *Builder) queryTables(dbKey string) (tables []string) {
+	names := make(map[string]bool)
+	var addItem func(j *JoinTreeItem)
+	addItem = func(j *JoinTreeItem) {
+		names[NodeTableName(j.Node)] = true
+		if tn, ok := j.Node.(TableNodeI); ok {
+			switch node := tn.EmbeddedNode_().(type) {
+			case *ReferenceNode:
+				names[ReferenceNodeRefTable(node)] = true
+			case *ReverseReferenceNode:
+				names[ReverseReferenceNodeRefTable(node)] = true
+			case *ManyManyNode:
+				names[ManyManyNodeDbTable(node)] = true
+				names[ManyManyNodeRefTable(node)] = true
+			}
+		}
+		for _, child := range j.ChildReferences {
+			addItem(child)
+		}
+	}
+	visited := make(map[*Builder]bool) // recursive common table expressions refer to themselves
+	var addBuilder func(b *Builder)
+	addBuilder = func(b *Builder) {
+		if visited[b] {
+			return
+		}
+		visited[b] = true
+		if b.RootJoinTreeItem != nil {
+			addItem(b.RootJoinTreeItem)
+		}
+		for _, n := range db2.Nodes(b.QueryBuilder) {
+			if sq, ok := n.(*SubqueryNode); ok {
+				addBuilder(SubqueryBuilder(sq).(*Builder))
+			}
+		}
+		for _, c := range b.ctes {
+			for _, qb := range CteBuilders(c) {
+				addBuilder(qb.(*Builder))
+			}
+		}
+	}
+	addBuilder(b)
+
+	for name := range names {
+		tables = append(tables, queryCacheTableKey(dbKey, name))
+	}
+	return
+}
@@ -0,0 +1,156 @@
+package sql
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/goradd/goradd/pkg/orm/broadcast"
+	"github.com/goradd/goradd/pkg/orm/db/sql/sqltest"
+	"github.com/goradd/goradd/pkg/orm/op"
+	. "github.com/goradd/goradd/pkg/orm/query"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryCache(t *testing.T) {
+	EnableQueryCache(10, 60)
+	defer func() { theQueryCache = nil }()
+
+	ctx := context.Background()
+	var loadCount int
+	load := func() interface{} {
+		loadCount++
+		return loadCount
+	}
+	query := func(cached bool) interface{} {
+		b := NewSqlBuilder(ctx, &recordingDb{})
+		b.Join(project(), nil)
+		b.Condition(op.Equal(project().manager().column("status"), 1))
+		if cached {
+			b.Cache()
+		}
+		b.buildJoinTree()
+		return b.cachedQuery("SELECT", []any{1}, load)
+	}
+
+	assert.Equal(t, 1, query(true))
+	assert.Equal(t, 1, query(true))
+	assert.Equal(t, 2, query(false))
+
+	broadcast.Update(ctx, "db", "other", 1)
+	assert.Equal(t, 1, query(true))
+
+	broadcast.BulkChange(ctx, "db", "project")
+	assert.Equal(t, 3, query(true))
+	assert.Equal(t, 3, query(true))
+}
+
+func TestQueryCacheTransaction(t *testing.T) {
+	EnableQueryCache(10, 60)
+	defer func() { theQueryCache = nil }()
+
+	var loadCount int
+	load := func() interface{} {
+		loadCount++
+		return loadCount
+	}
+	query := func() interface{} {
+		b := NewSqlBuilder(context.Background(), &recordingDb{})
+		b.Join(project(), nil)
+		b.Cache()
+		b.buildJoinTree()
+		return b.cachedQuery("SELECT", nil, load)
+	}
+
+	h := NewSqlDb("db", (&sqltest.Conn{}).DB())
+	ctx := h.PutBlankContext(context.Background())
+	assert.Equal(t, 1, query())
+
+	// a change is only seen by other readers after the transaction commits
+	txid := h.Begin(ctx)
+	broadcast.BulkChange(ctx, "db", "project")
+	assert.Equal(t, 1, query())
+	h.Commit(ctx, txid)
+	assert.Equal(t, 2, query())
+
+	// a change that is rolled back does not invalidate anything
+	txid = h.Begin(ctx)
+	broadcast.BulkChange(ctx, "db", "project")
+	h.Rollback(ctx, txid)
+	assert.Equal(t, 2, query())
+
+	// nor does a change in a nested transaction that is rolled back
+	txid = h.Begin(ctx)
+	txid2 := h.Begin(ctx)
+	broadcast.BulkChange(ctx, "db", "project")
+	h.Rollback(ctx, txid2)
+	txid2 = h.Begin(ctx)
+	h.Commit(ctx, txid2)
+	h.Commit(ctx, txid)
+	assert.Equal(t, 2, query())
+
+	// but a change in a nested transaction that is committed does
+	txid = h.Begin(ctx)
+	txid2 = h.Begin(ctx)
+	broadcast.BulkChange(ctx, "db", "project")
+	h.Commit(ctx, txid2)
+	txid2 = h.Begin(ctx)
+	h.Rollback(ctx, txid2)
+	assert.Equal(t, 2, query())
+	h.Commit(ctx, txid)
+	assert.Equal(t, 3, query())
+}
+
+type personNode struct {
+	ReferenceNodeI
+}
+
+func person() *personNode {
+	n := &personNode{NewTableNode("db", "person", "Person")}
+	SetParentNode(n, nil)
+	return n
+}
+
+func (n *personNode) SelectNodes_() []*ColumnNode {
+	return []*ColumnNode{n.column("id"), n.column("status")}
+}
+
+func (n *personNode) PrimaryKeyNode() *ColumnNode {
+	return n.column("id")
+}
+
+func (n *personNode) EmbeddedNode_() NodeI {
+	return n.ReferenceNodeI
+}
+
+func (n *personNode) Copy_() NodeI {
+	return &personNode{CopyNode(n.ReferenceNodeI)}
+}
+
+func (n *personNode) column(name string) *ColumnNode {
+	cn := NewColumnNode("db", "person", name, name, ColTypeInteger, name == "id")
+	SetParentNode(cn, n)
+	return cn
+}
+
+func TestQueryCacheCte(t *testing.T) {
+	d := &recordingDb{}
+
+	// the projects of the managers that have a status of 1, using a Cte over the person table
+	managers := NewSqlBuilder(context.Background(), d)
+	managers.Join(person(), nil)
+	managers.Select(person().column("id"))
+	managers.Condition(op.Equal(person().column("status"), 1))
+	cte := op.Cte("managers", managers.Subquery())
+
+	b := NewSqlBuilder(context.Background(), d)
+	b.Join(project(), nil)
+	b.Condition(op.Equal(project().column("manager_id"), cte.Column("id")))
+	b.buildJoinTree()
+	b.makeColumnAliases()
+	b.generateSelectSql()
+
+	tables := b.queryTables("db")
+	sort.Strings(tables)
+	assert.Equal(t, []string{"db.person", "db.project"}, tables)
+}
@@ -1,10 +1,13 @@
 package sql
 
 import (
+	"encoding/json"
 	"fmt"
+	"github.com/google/uuid"
 	. "github.com/goradd/goradd/pkg/orm/query"
 	strings2 "github.com/goradd/goradd/pkg/strings"
 	time2 "github.com/goradd/goradd/pkg/time"
+	"github.com/shopspring/decimal"
 	"log"
 	"strconv"
 	"strings"
@@ -214,6 +217,186 @@ func (r SqlReceiver) StringI() interface{} {
 	}
 }
 
+// JsonI returns the value as an interface to a json.RawMessage value.
+// The bytes are copied, since drivers may reuse the buffer they return.
+func (r SqlReceiver) JsonI() interface{} {
+	if r.R == nil {
+		return nil
+	}
+	switch v := r.R.(type) {
+	case []byte:
+		return json.RawMessage(append([]byte(nil), v...))
+	case string:
+		return json.RawMessage(v)
+	default:
+		return json.RawMessage(fmt.Sprint(r.R))
+	}
+}
+
+// DecimalI returns the value as an interface to a decimal.Decimal value.
+func (r SqlReceiver) DecimalI() interface{} {
+	if r.R == nil {
+		return nil
+	}
+	switch v := r.R.(type) {
+	case decimal.Decimal:
+		return v
+	case []byte:
+		return decimal.RequireFromString(string(v))
+	case string:
+		return decimal.RequireFromString(v)
+	case float64: // SQLite can return numeric values as real numbers
+		return decimal.NewFromFloat(v)
+	case int64:
+		return decimal.NewFromInt(v)
+	default:
+		return decimal.RequireFromString(fmt.Sprint(r.R))
+	}
+}
+
+// UuidI returns the value as an interface to a uuid.UUID value.
+// UUIDs can be stored as text, or as 16 bytes.
+func (r SqlReceiver) UuidI() interface{} {
+	if r.R == nil {
+		return nil
+	}
+	switch v := r.R.(type) {
+	case uuid.UUID:
+		return v
+	case [16]byte:
+		return uuid.UUID(v)
+	case []byte:
+		if len(v) == 16 {
+			return uuid.Must(uuid.FromBytes(v))
+		}
+		return uuid.MustParse(string(v))
+	case string:
+		return uuid.MustParse(v)
+	default:
+		return uuid.MustParse(fmt.Sprint(r.R))
+	}
+}
+
+// StringSliceI returns the value of an array column as an interface to a []string value.
+func (r SqlReceiver) StringSliceI() interface{} {
+	if r.R == nil {
+		return nil
+	}
+	switch v := r.R.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		a := make([]string, len(v))
+		for i, item := range v {
+			if item != nil {
+				a[i] = fmt.Sprint(item)
+			}
+		}
+		return a
+	case []byte:
+		return parseArrayText(string(v))
+	case string:
+		return parseArrayText(v)
+	default:
+		log.Panicln("Unknown type returned from sql driver")
+		return nil
+	}
+}
+
+// IntSliceI returns the value of an array column as an interface to a []int value.
+func (r SqlReceiver) IntSliceI() interface{} {
+	v := r.Int64SliceI()
+	if v == nil {
+		return nil
+	}
+	a64 := v.([]int64)
+	a := make([]int, len(a64))
+	for i, n := range a64 {
+		a[i] = int(n)
+	}
+	return a
+}
+
+// Int64SliceI returns the value of an array column as an interface to a []int64 value.
+func (r SqlReceiver) Int64SliceI() interface{} {
+	if r.R == nil {
+		return nil
+	}
+	var items []string
+	switch v := r.R.(type) {
+	case []int64:
+		return v
+	case []interface{}:
+		a := make([]int64, len(v))
+		for i, item := range v {
+			a[i] = SqlReceiver{item}.Int64I().(int64)
+		}
+		return a
+	case []byte:
+		items = parseArrayText(string(v))
+	case string:
+		items = parseArrayText(v)
+	default:
+		log.Panicln("Unknown type returned from sql driver")
+		return nil
+	}
+	a := make([]int64, len(items))
+	for i, item := range items {
+		if item == "" {
+			continue // NULL
+		}
+		n, err := strconv.ParseInt(item, 10, 64)
+		if err != nil {
+			log.Panic(err)
+		}
+		a[i] = n
+	}
+	return a
+}
+
+// parseArrayText parses an array in the text format Postgres uses, like {a,"b c",NULL}, and returns its items.
+// NULL items are returned as empty strings. Only one dimensional arrays are supported.
+func parseArrayText(s string) []string {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || s[0] != '{' || s[len(s)-1] != '}' {
+		log.Panicln("Invalid array returned from sql driver: " + s)
+	}
+	s = s[1 : len(s)-1]
+	items := []string{}
+	if s == "" {
+		return items
+	}
+	var b strings.Builder
+	var quoted, inQuotes, escaped bool
+	for _, c := range s {
+		switch {
+		case escaped:
+			b.WriteRune(c)
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '"':
+			inQuotes = !inQuotes
+			quoted = true
+		case c == ',' && !inQuotes:
+			items = append(items, arrayItem(b.String(), quoted))
+			b.Reset()
+			quoted = false
+		default:
+			b.WriteRune(c)
+		}
+	}
+	items = append(items, arrayItem(b.String(), quoted))
+	return items
+}
+
+func arrayItem(s string, quoted bool) string {
+	if !quoted && strings.EqualFold(s, "NULL") {
+		return ""
+	}
+	return s
+}
+
 // FloatI returns the value as an interface to a float32 value.
 func (r SqlReceiver) FloatI() interface{} {
 	if r.R == nil {
@@ -224,6 +407,8 @@ func (r SqlReceiver) FloatI() interface{} {
 		return r.R
 	case float64:
 		return float32(r.R.(float64))
+	case int64: // SQLite can return integers from REAL columns
+		return float32(r.R.(int64))
 	case string:
 		f, err := strconv.ParseFloat(r.R.(string), 32)
 		if err != nil {
@@ -256,6 +441,8 @@ func (r SqlReceiver) DoubleI() interface{} {
 		return float64(r.R.(float32))
 	case float64:
 		return r.R
+	case int64: // SQLite can return integers from REAL columns
+		return float64(r.R.(int64))
 	case string:
 		f, err := strconv.ParseFloat(r.R.(string), 64)
 		if err != nil {
@@ -290,6 +477,9 @@ func (r SqlReceiver) TimeI() interface{} {
 	case time.Time:
 		t = v
 	case string:
+		if strings2.StartsWith(strings.ToUpper(v), "CURRENT_TIMESTAMP") {
+			return "now" // SQLite returns default values as strings
+		}
 		t = time2.FromSqlDateTime(v) // Note that this must always include timezone information if coming from a timestamp with timezone column
 	case []byte:
 		s := string(v)
@@ -304,7 +494,12 @@ func (r SqlReceiver) TimeI() interface{} {
 		if err != nil {
 			return nil
 		}
-		// TODO: SQL Lite, may return an int or float. Not sure we can support these.
+	case int64:
+		// SQLite stores times as integers in unix time
+		t = time.Unix(v, 0)
+	case float64:
+		// SQLite stores times as real numbers in Julian days
+		t = time.Unix(0, int64((v-2440587.5)*86400*float64(time.Second)))
 	default:
 		log.Panicln("Unknown type returned from sql driver")
 		return nil
@@ -336,6 +531,18 @@ func (r SqlReceiver) Unpack(typ GoColumnType) interface{} {
 		return r.DoubleI()
 	case ColTypeBool:
 		return r.BoolI()
+	case ColTypeJSON:
+		return r.JsonI()
+	case ColTypeDecimal:
+		return r.DecimalI()
+	case ColTypeUUID:
+		return r.UuidI()
+	case ColTypeStringSlice:
+		return r.StringSliceI()
+	case ColTypeIntegerSlice:
+		return r.IntSliceI()
+	case ColTypeInteger64Slice:
+		return r.Int64SliceI()
 	default:
 		return r.R
 	}
@@ -375,6 +582,25 @@ func (r SqlReceiver) UnpackDefaultValue(typ GoColumnType) interface{} {
 		return r.DoubleI()
 	case ColTypeBool:
 		return r.BoolI()
+	case ColTypeJSON:
+		// JSON defaults are expressions that cannot be represented as Go constants
+		return nil
+	case ColTypeDecimal:
+		s := r.StringI()
+		if s == nil || s.(string) == "NULL" {
+			return nil
+		}
+		d, err := decimal.NewFromString(strings.Trim(s.(string), `"'`))
+		if err != nil {
+			return nil // an expression
+		}
+		return d
+	case ColTypeUUID:
+		// UUID defaults are generally functions that generate a new value
+		return nil
+	case ColTypeStringSlice, ColTypeIntegerSlice, ColTypeInteger64Slice:
+		// Array defaults cannot be represented as Go constants
+		return nil
 	default:
 		return r.R
 	}
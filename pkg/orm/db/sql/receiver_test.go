@@ -0,0 +1,34 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	. "github.com/goradd/goradd/pkg/orm/query"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnpackDecimalAndUUID(t *testing.T) {
+	d := SqlReceiver{R: []byte("12345678901234567890.123456789")}.Unpack(ColTypeDecimal)
+	assert.Equal(t, "12345678901234567890.123456789", d.(decimal.Decimal).String())
+	d = SqlReceiver{R: "1.5"}.Unpack(ColTypeDecimal)
+	assert.True(t, decimal.RequireFromString("1.5").Equal(d.(decimal.Decimal)))
+	assert.Nil(t, SqlReceiver{}.Unpack(ColTypeDecimal))
+
+	u := uuid.New()
+	assert.Equal(t, u, SqlReceiver{R: u.String()}.Unpack(ColTypeUUID))
+	assert.Equal(t, u, SqlReceiver{R: []byte(u.String())}.Unpack(ColTypeUUID))
+	assert.Equal(t, u, SqlReceiver{R: u[:]}.Unpack(ColTypeUUID))
+	assert.Nil(t, SqlReceiver{}.Unpack(ColTypeUUID))
+}
+
+func TestUnpackArrays(t *testing.T) {
+	assert.Equal(t, []string{"a", "b c", "", "NULL", `d"e`, ""},
+		SqlReceiver{R: `{a,"b c",NULL,"NULL","d\"e",""}`}.Unpack(ColTypeStringSlice))
+	assert.Equal(t, []string{}, SqlReceiver{R: "{}"}.Unpack(ColTypeStringSlice))
+	assert.Equal(t, []int{1, -2, 3}, SqlReceiver{R: []byte("{1,-2,3}")}.Unpack(ColTypeIntegerSlice))
+	assert.Equal(t, []int64{9007199254740993, 0}, SqlReceiver{R: "{9007199254740993,NULL}"}.Unpack(ColTypeInteger64Slice))
+	assert.Nil(t, SqlReceiver{}.Unpack(ColTypeStringSlice))
+	assert.Nil(t, SqlReceiver{}.Unpack(ColTypeIntegerSlice))
+}
@@ -0,0 +1,336 @@
+package sqlite
+
+import (
+	"context"
+	sqldb "database/sql"
+	"fmt"
+	"github.com/goradd/goradd/pkg/orm/db"
+	sql2 "github.com/goradd/goradd/pkg/orm/db/sql"
+	. "github.com/goradd/goradd/pkg/orm/query"
+	"strings"
+	"time"
+)
+
+// sqliteTimeFormat is the format used to send time values to the database. SQLite has no native time type,
+// and its date and time functions expect text in this form. Times are always stored in UTC.
+const sqliteTimeFormat = "2006-01-02 15:04:05.999999"
+
+// DB is the goradd driver for SQLite databases.
+//
+// GoRADD does not depend on a particular SQLite driver, since the available drivers either require cgo
+// or are quite large. Instead, import the driver of your choice for its side effect of registering
+// itself with database/sql, and pass its registered name to NewDB. For example:
+//
+//	import _ "modernc.org/sqlite" // registers "sqlite"
+//	...
+//	db1 := sqlite.NewDB(key, "sqlite", "file:goradd.db?_pragma=foreign_keys(1)")
+//
+// or
+//
+//	import _ "github.com/mattn/go-sqlite3" // registers "sqlite3"
+//	...
+//	db1 := sqlite.NewDB(key, "sqlite3", "file:goradd.db?_foreign_keys=on")
+//
+// SQLite does not enforce foreign keys by default, and the setting is per connection, so you should
+// turn it on in the connection string using whatever method your driver provides.
+//
+// Timezones
+// SQLite has no date or time types, and stores these as text. The driver converts all time.Time values
+// to UTC text before sending them to the database, and returns all times in UTC.
+type DB struct {
+	sql2.DbHelper
+	model        *db.Model
+	databaseName string
+}
+
+// NewDB returns a new SQLite DB database object that you can add to the datastore.
+//
+// driverName is the name the SQLite driver registered with the database/sql package, and connectionString is
+// the data source name passed to that driver, which is usually the path to the database file.
+func NewDB(dbKey string,
+	driverName string,
+	connectionString string) *DB {
+	if driverName == "" || connectionString == "" {
+		panic("must specify how to connect to the database")
+	}
+
+	db3, err := sqldb.Open(driverName, connectionString)
+	if err != nil {
+		panic("Could not open database: " + err.Error())
+	}
+	err = db3.Ping()
+	if err != nil {
+		panic("Could not ping database " + dbKey + ":" + err.Error())
+	}
+
+	m := DB{
+		DbHelper:     sql2.NewSqlDb(dbKey, db3),
+		databaseName: "main", // the name SQLite gives the primary database of a connection
+	}
+	m.SetExplainPrefix("EXPLAIN QUERY PLAN ")
+	return &m
+}
+
+// NewBuilder returns a new query builder to build a query that will be processed by the database.
+func (m *DB) NewBuilder(ctx context.Context) QueryBuilderI {
+	return sql2.NewSqlBuilder(ctx, m)
+}
+
+// Model returns the database description object
+func (m *DB) Model() *db.Model {
+	return m.model
+}
+
+// iq surrounds the given value with sql identifier quotes.
+func iq(v string) string {
+	return `"` + v + `"`
+}
+
+// QuoteIdentifier surrounds the given identifier with quote characters
+// appropriate for SQLite
+func (m *DB) QuoteIdentifier(v string) string {
+	return iq(v)
+}
+
+// FormatArgument formats the given argument number for embedding in a SQL statement.
+// SQLite just uses a question mark as a placeholder.
+func (m *DB) FormatArgument(n int) string {
+	return "?"
+}
+
+// OperationSql provides SQLite specific SQL for certain operators.
+func (m *DB) OperationSql(op Operator, operandStrings []string) (sql string) {
+	switch op {
+	case OpDateAddSeconds:
+		// Modifying a datetime in the query
+		// Only works on date, datetime and timestamps. Not times.
+		s := operandStrings[0]
+		s2 := operandStrings[1]
+		sql = fmt.Sprintf(`datetime(%s, (%s) || ' seconds')`, s, s2)
+	case OpBitXor:
+		// SQLite has no bitwise exclusive or operator
+		s := operandStrings[0]
+		s2 := operandStrings[1]
+		sql = fmt.Sprintf(`((%[1]s | %[2]s) - (%[1]s & %[2]s))`, s, s2)
+	case OpJsonExtract:
+		// Keys are quoted so that they can contain any character
+		path := "'$'"
+		for _, k := range operandStrings[1:] {
+			path += " || '.' || json_quote(" + k + ")"
+		}
+		sql = fmt.Sprintf(`json_extract(%s, %s)`, operandStrings[0], path)
+	}
+	return
+}
+
+// Exec executes the given SQL, converting any time values in args to the format SQLite expects.
+func (m *DB) Exec(ctx context.Context, sql string, args ...interface{}) (r sqldb.Result, err error) {
+	return m.DbHelper.Exec(ctx, sql, convertArgs(args)...)
+}
+
+// Query executes the given SQL, converting any time values in args to the format SQLite expects.
+func (m *DB) Query(ctx context.Context, sql string, args ...interface{}) (r *sqldb.Rows, err error) {
+	return m.DbHelper.Query(ctx, sql, convertArgs(args)...)
+}
+
+// ReplicaQuery is like Query, but sends the query to a read replica if there are any, converting any time values
+// in args to the format SQLite expects.
+func (m *DB) ReplicaQuery(ctx context.Context, sql string, args ...interface{}) (r *sqldb.Rows, err error) {
+	return m.DbHelper.ReplicaQuery(ctx, sql, convertArgs(args)...)
+}
+
+// Update sets specific fields of a record that already exists in the database to the given data.
+func (m *DB) Update(ctx context.Context,
+	table string,
+	fields map[string]any,
+	pkName string,
+	pkValue any) {
+
+	if err := m.UpdateE(ctx, table, fields, pkName, pkValue, "", nil); err != nil {
+		panic(err.Error())
+	}
+}
+
+// UpdateE is like Update, but returns an error instead of panicking.
+// If lockName is not empty, the record is only updated if the lockName column still has lockValue,
+// and a db.OptimisticLockError is returned if not.
+func (m *DB) UpdateE(ctx context.Context,
+	table string,
+	fields map[string]any,
+	pkName string,
+	pkValue any,
+	lockName string,
+	lockValue any) error {
+
+	sql, args := sql2.GenerateLockedUpdate(m, table, fields, pkName, pkValue, lockName, lockValue)
+	r, err := m.Exec(ctx, sql, args...)
+	if err != nil {
+		return convertError(table, err)
+	}
+	if lockName != "" {
+		if n, err := r.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return db.OptimisticLockError{Table: table, Pk: pkValue}
+		}
+	}
+	return nil
+}
+
+// Insert inserts the given data as a new record in the database.
+// It returns the record id of the new record.
+func (m *DB) Insert(ctx context.Context, table string, fields map[string]interface{}) string {
+	id, err := m.InsertE(ctx, table, fields)
+	if err != nil {
+		panic(err.Error())
+	}
+	return id
+}
+
+// InsertE is like Insert, but returns an error instead of panicking.
+func (m *DB) InsertE(ctx context.Context, table string, fields map[string]interface{}) (string, error) {
+	sql, args := sql2.GenerateInsert(m, table, fields)
+	r, err := m.Exec(ctx, sql, args...)
+	if err != nil {
+		return "", convertError(table, err)
+	}
+	id, err := r.LastInsertId()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprint(id), nil
+}
+
+// Delete deletes the indicated record from the database.
+func (m *DB) Delete(ctx context.Context, table string, pkName string, pkValue interface{}) {
+	if err := m.DeleteE(ctx, table, pkName, pkValue); err != nil {
+		panic(err.Error())
+	}
+}
+
+// DeleteE is like Delete, but returns an error instead of panicking.
+func (m *DB) DeleteE(ctx context.Context, table string, pkName string, pkValue interface{}) error {
+	var sql = "DELETE FROM " + iq(table) + "\n"
+	sql += "WHERE " + iq(pkName) + " = ?"
+	_, err := m.Exec(ctx, sql, pkValue)
+	return convertError(table, err)
+}
+
+// Associate sets up the many-many association pointing from the given table and column to another table and column.
+// table is the name of the association table.
+// column is the name of the column in the association table that contains the pk for the record we are associating.
+// pk is the value of the primary key.
+// relatedTable is the table the association is pointing to.
+// relatedColumn is the column in the association table that points to the relatedTable's pk.
+// relatedPks are the new primary keys in the relatedTable we are associating.
+func (m *DB) Associate(ctx context.Context,
+	table string,
+	column string,
+	pk interface{},
+	_ string,
+	relatedColumn string,
+	relatedPks interface{}) {
+
+	sql2.Associate(ctx, m, table, column, pk, relatedColumn, relatedPks)
+}
+
+// AssociateE is like Associate, but returns an error instead of panicking.
+func (m *DB) AssociateE(ctx context.Context,
+	table string,
+	column string,
+	pk interface{},
+	_ string,
+	relatedColumn string,
+	relatedPks interface{}) error {
+
+	return convertError(table, sql2.AssociateE(ctx, m, table, column, pk, relatedColumn, relatedPks))
+}
+
+// CommitE commits the transaction, and returns any error the database reports.
+func (m *DB) CommitE(ctx context.Context, txid db.TransactionID) error {
+	return convertError("", m.DbHelper.CommitE(ctx, txid))
+}
+
+// maxBatchArgs is the most arguments SQLite allows in one statement, since version 3.32.
+const maxBatchArgs = 32766
+
+// InsertBatch inserts the rows using multi-row insert statements.
+//
+// SQLite only reports the id of the last row of a statement, and does not promise which ids the other rows get,
+// so if the table has an auto-generated primary key, the rows are inserted one at a time to get their ids.
+// SQLite runs in the same process as the application, so this costs little more than a multi-row insert.
+func (m *DB) InsertBatch(ctx context.Context, table string, rows []map[string]interface{}) ([]string, error) {
+	ids := make([]string, len(rows))
+	if t := m.Model().Table(table); t != nil && t.PrimaryKeyColumn() != nil && t.PrimaryKeyColumn().IsId {
+		for i, row := range rows {
+			id, err := m.InsertE(ctx, table, row)
+			if err != nil {
+				return nil, err
+			}
+			ids[i] = id
+		}
+		return ids, nil
+	}
+	for _, g := range sql2.GroupBatchRows(rows, maxBatchArgs) {
+		sql, args := sql2.GenerateBatchInsert(m, table, g, rows)
+		if _, err := m.Exec(ctx, sql, args...); err != nil {
+			return nil, convertError(table, err)
+		}
+	}
+	return ids, nil
+}
+
+// Upsert inserts the rows using multi-row insert statements, and updates the existing records that
+// conflict with them on conflictColumns, unless their values in scopeColumns are different.
+func (m *DB) Upsert(ctx context.Context, table string, rows []map[string]interface{}, conflictColumns []string, scopeColumns []string) error {
+	if len(conflictColumns) == 0 {
+		panic("Upsert requires the conflict columns")
+	}
+	var conflicts []string
+	for _, col := range conflictColumns {
+		conflicts = append(conflicts, iq(col))
+	}
+	var scopes []string
+	for _, col := range scopeColumns {
+		scopes = append(scopes, iq(table)+"."+iq(col)+" IS excluded."+iq(col))
+	}
+	for _, g := range sql2.GroupBatchRows(rows, maxBatchArgs) {
+		sql, args := sql2.GenerateBatchInsert(m, table, g, rows)
+		sql += "ON CONFLICT (" + strings.Join(conflicts, ",") + ") "
+		if cols := sql2.UpsertUpdateColumns(g, conflictColumns); cols == nil {
+			sql += "DO NOTHING"
+		} else {
+			var sets []string
+			for _, col := range cols {
+				sets = append(sets, iq(col)+"=excluded."+iq(col))
+			}
+			sql += "DO UPDATE SET " + strings.Join(sets, ", ")
+			if scopes != nil {
+				sql += " WHERE " + strings.Join(scopes, " AND ")
+			}
+		}
+		if _, err := m.Exec(ctx, sql, args...); err != nil {
+			return convertError(table, err)
+		}
+	}
+	return nil
+}
+
+// convertArgs returns args with time values replaced by their text equivalents.
+// Drivers differ on how they store time values, so we do it here to be consistent.
+func convertArgs(args []interface{}) []interface{} {
+	var converted []interface{}
+	for i, arg := range args {
+		if t, ok := arg.(time.Time); ok {
+			if converted == nil {
+				converted = make([]interface{}, len(args))
+				copy(converted, args)
+			}
+			converted[i] = t.UTC().Format(sqliteTimeFormat)
+		}
+	}
+	if converted == nil {
+		return args
+	}
+	return converted
+}
@@ -0,0 +1,180 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/goradd/goradd/pkg/orm/db"
+	sql2 "github.com/goradd/goradd/pkg/orm/db/sql"
+	"github.com/goradd/goradd/pkg/orm/op"
+	. "github.com/goradd/goradd/pkg/orm/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+// newTestDB returns a DB of a new in-memory database, made by running schema, with a model of desc.
+func newTestDB(t *testing.T, desc db.DatabaseDescription, schema string) *DB {
+	d, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	d.SetMaxOpenConns(1) // each connection to :memory: has its own database
+	t.Cleanup(func() { _ = d.Close() })
+	_, err = d.Exec(schema)
+	require.NoError(t, err)
+	return &DB{
+		DbHelper:     sql2.NewSqlDb("test", d),
+		databaseName: "main",
+		model:        db.NewModel("test", "", "_id", "_enum", true, desc),
+	}
+}
+
+func TestConvertArgs(t *testing.T) {
+	loc := time.FixedZone("test", -5*60*60)
+	t1 := time.Date(2023, 4, 5, 20, 30, 15, 123456000, loc)
+
+	args := []interface{}{1, t1, "a"}
+	converted := convertArgs(args)
+	assert.Equal(t, []interface{}{1, "2023-04-06 01:30:15.123456", "a"}, converted)
+	assert.Equal(t, t1, args[1], "the original args are not changed")
+
+	args = []interface{}{1, "a"}
+	assert.Equal(t, args, convertArgs(args))
+	assert.Nil(t, convertArgs(nil))
+}
+
+func TestInsertBatch(t *testing.T) {
+	m := newTestDB(t, db.DatabaseDescription{
+		Tables: []db.TableDescription{
+			{
+				Name: "person",
+				Columns: []db.ColumnDescription{
+					{Name: "id", GoType: "string", IsId: true, IsPk: true},
+					{Name: "name", GoType: "string"},
+				},
+			},
+			{
+				Name: "tag",
+				Columns: []db.ColumnDescription{
+					{Name: "name", GoType: "string", IsPk: true},
+				},
+			},
+		},
+	}, `CREATE TABLE person (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL);
+		CREATE TABLE tag (name TEXT PRIMARY KEY);
+		INSERT INTO person (id, name) VALUES (7, 'x');`)
+	ctx := m.PutBlankContext(context.Background())
+
+	// each row is matched to the id the database gave it
+	rows := []map[string]interface{}{{"name": "a"}, {"name": "b", "id": 20}, {"name": "c"}}
+	ids, err := m.InsertBatch(ctx, "person", rows)
+	require.NoError(t, err)
+	require.Len(t, ids, 3)
+	for i, id := range ids {
+		var name string
+		require.NoError(t, m.SqlDb().QueryRow("SELECT name FROM person WHERE id = ?", id).Scan(&name))
+		assert.Equal(t, rows[i]["name"], name)
+	}
+	assert.Equal(t, "20", ids[1])
+
+	ids, err = m.InsertBatch(ctx, "tag", []map[string]interface{}{{"name": "a"}, {"name": "b"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", ""}, ids)
+	var count int
+	require.NoError(t, m.SqlDb().QueryRow("SELECT COUNT(*) FROM tag").Scan(&count))
+	assert.Equal(t, 2, count)
+}
+
+// eventNode is the node of an event table with a time column.
+type eventNode struct {
+	ReferenceNodeI
+}
+
+func event() *eventNode {
+	n := &eventNode{NewTableNode("test", "event", "Event")}
+	SetParentNode(n, nil)
+	return n
+}
+
+func (n *eventNode) SelectNodes_() []*ColumnNode {
+	return []*ColumnNode{n.PrimaryKeyNode(), n.occurred()}
+}
+
+func (n *eventNode) PrimaryKeyNode() *ColumnNode {
+	cn := NewColumnNode("test", "event", "id", "ID", ColTypeString, true)
+	SetParentNode(cn, n)
+	return cn
+}
+
+func (n *eventNode) EmbeddedNode_() NodeI {
+	return n.ReferenceNodeI
+}
+
+func (n *eventNode) Copy_() NodeI {
+	return &eventNode{CopyNode(n.ReferenceNodeI)}
+}
+
+func (n *eventNode) occurred() *ColumnNode {
+	cn := NewColumnNode("test", "event", "occurred", "Occurred", ColTypeTime, false)
+	SetParentNode(cn, n)
+	return cn
+}
+
+func TestTimeCondition(t *testing.T) {
+	m := newTestDB(t, db.DatabaseDescription{
+		Tables: []db.TableDescription{
+			{
+				Name: "event",
+				Columns: []db.ColumnDescription{
+					{Name: "id", GoType: "string", IsId: true, IsPk: true},
+					{Name: "occurred", GoType: "time.Time"},
+				},
+			},
+		},
+	}, `CREATE TABLE event (id INTEGER PRIMARY KEY AUTOINCREMENT, occurred DATETIME NOT NULL);
+		INSERT INTO event (occurred) VALUES ('2023-04-05 18:30:10'), ('2023-04-05 18:30:20');`)
+	t1 := time.Date(2023, 4, 5, 20, 30, 15, 0, time.FixedZone("test", 2*60*60))
+
+	// the time is compared as UTC text
+	b := m.NewBuilder(m.PutBlankContext(context.Background()))
+	b.Join(event(), nil)
+	b.Condition(op.GreaterThan(event().occurred(), t1))
+	assert.Equal(t, uint(1), b.Count(false))
+}
+
+func TestUpsertScope(t *testing.T) {
+	m := newTestDB(t, db.DatabaseDescription{
+		Tables: []db.TableDescription{
+			{
+				Name: "item",
+				Columns: []db.ColumnDescription{
+					{Name: "id", GoType: "int", IsPk: true},
+					{Name: "tenant_id", GoType: "int"},
+					{Name: "name", GoType: "string"},
+				},
+			},
+		},
+	}, `CREATE TABLE item (id INTEGER PRIMARY KEY, tenant_id INTEGER NOT NULL, name TEXT NOT NULL);
+		INSERT INTO item (id, tenant_id, name) VALUES (1, 1, 'a'), (2, 2, 'b');`)
+	ctx := m.PutBlankContext(context.Background())
+
+	err := m.Upsert(ctx, "item", []map[string]interface{}{
+		{"id": 1, "tenant_id": 1, "name": "a2"},
+		{"id": 2, "tenant_id": 1, "name": "b2"},
+		{"id": 3, "tenant_id": 1, "name": "c"},
+	}, []string{"id"}, []string{"tenant_id"})
+	require.NoError(t, err)
+
+	names := make(map[int]string)
+	r, err := m.SqlDb().Query("SELECT id, name FROM item")
+	require.NoError(t, err)
+	defer r.Close()
+	for r.Next() {
+		var id int
+		var name string
+		require.NoError(t, r.Scan(&id, &name))
+		names[id] = name
+	}
+	assert.Equal(t, map[int]string{1: "a2", 2: "b", 3: "c"}, names, "the record of the other tenant is not changed")
+}
@@ -0,0 +1,28 @@
+package sqlite
+
+import (
+	"github.com/goradd/goradd/pkg/orm/db"
+	"strings"
+)
+
+// convertError converts the SQLite errors that an application might want to respond to into the
+// matching db error types. Other errors are returned unchanged.
+//
+// Since the driver is not known, errors are identified by the messages SQLite itself generates.
+func convertError(table string, err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "UNIQUE constraint failed"):
+		return db.UniqueViolationError{Table: table, Err: err}
+	case strings.Contains(msg, "FOREIGN KEY constraint failed"):
+		return db.ForeignKeyViolationError{Table: table, Err: err}
+	case strings.Contains(msg, "database is locked"),
+		strings.Contains(msg, "database table is locked"),
+		strings.Contains(msg, "SQLITE_BUSY"):
+		return db.DeadlockError{Table: table, Err: err}
+	}
+	return err
+}
@@ -0,0 +1,754 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	log2 "github.com/goradd/goradd/pkg/log"
+	"github.com/goradd/goradd/pkg/orm/db"
+	sql2 "github.com/goradd/goradd/pkg/orm/db/sql"
+	. "github.com/goradd/goradd/pkg/orm/query"
+	"github.com/goradd/goradd/pkg/stringmap"
+	strings2 "github.com/goradd/goradd/pkg/strings"
+	"log"
+	"math"
+	"sort"
+	"strings"
+)
+
+/*
+This file contains the code that parses the data structure found in a SQLite database into
+our own cross-platform internal database description object.
+
+SQLite has no place to store comments in its schema, but it does keep the original text of
+each CREATE TABLE statement. We look for SQL comments in that text to get the comments for
+tables and columns, so that the options that GoRADD reads out of comments can be used. For example:
+
+	CREATE TABLE "project" ( -- {"goName":"Proj"}
+		"id" INTEGER PRIMARY KEY AUTOINCREMENT,
+		"num" INT NOT NULL -- {"goName":"Number"}
+	);
+*/
+
+type sqliteTable struct {
+	name      string
+	createSql string
+	columns   []sqliteColumn
+	indexes   []sqliteIndex
+	fkMap     map[string]sqliteForeignKey
+	comment   string
+	options   map[string]interface{}
+}
+
+type sqliteColumn struct {
+	name         string
+	declaredType string
+	notNull      bool
+	defaultValue sql2.SqlReceiver
+	pk           int // position of the column in the primary key, or zero if not part of the primary key
+	comment      string
+	options      map[string]interface{}
+}
+
+type sqliteIndex struct {
+	name        string
+	isUnique    bool
+	origin      string // "c" for CREATE INDEX, "u" for UNIQUE constraint, "pk" for PRIMARY KEY
+	columnNames []string
+}
+
+type sqliteForeignKey struct {
+	tableName            string
+	columnName           string
+	referencedTableName  string
+	referencedColumnName sql.NullString
+	updateRule           string
+	deleteRule           string
+}
+
+// Analyze will read the structure of the database and build the model used for code generation.
+func (m *DB) Analyze(options Options) {
+	m.AnalyzeDescription(m.Describe(options), options)
+}
+
+// Describe reads the structure of the database and returns it as a description.
+// Pass the result to db.WriteDescriptionFile to save it as a schema file.
+func (m *DB) Describe(options Options) db.DatabaseDescription {
+	rawTables := m.getRawTables()
+	return m.descriptionFromRawTables(rawTables, options)
+}
+
+// AnalyzeDescription builds the model used for code generation from the given description
+// rather than from the structure of the database.
+func (m *DB) AnalyzeDescription(desc db.DatabaseDescription, options Options) {
+	m.model = db.NewModel(m.DbKey(),
+		m.databaseName,
+		options.ForeignKeySuffix,
+		options.EnumTableSuffix,
+		false, desc)
+}
+
+// AnalyzeFile builds the model used for code generation from the schema file at path.
+// See db.ReadDescriptionFile for the format of the file. It panics if the file cannot be read
+// or is not valid.
+func (m *DB) AnalyzeFile(path string, options Options) {
+	desc, err := db.ReadDescriptionFile(path)
+	if err != nil {
+		panic(err.Error())
+	}
+	m.AnalyzeDescription(desc, options)
+}
+
+func (m *DB) getRawTables() map[string]sqliteTable {
+	var tableMap = make(map[string]sqliteTable)
+
+	tables := m.getTables()
+	for _, table := range tables {
+		table.columns = m.getColumns(table)
+		table.indexes = m.getIndexes(table.name)
+
+		for _, fk := range m.getForeignKeys(table) {
+			if _, ok := table.fkMap[fk.columnName]; ok {
+				log2.Warningf("Column %s:%s multi-table foreign keys are not supported.", table.name, fk.columnName)
+				delete(table.fkMap, fk.columnName)
+			} else {
+				table.fkMap[fk.columnName] = fk
+			}
+		}
+
+		tableMap[table.name] = table
+	}
+
+	return tableMap
+}
+
+// getTables gets information for the tables
+func (m *DB) getTables() []sqliteTable {
+	var tableName, createSql string
+	var tables []sqliteTable
+
+	rows, err := m.SqlDb().Query(`
+	SELECT
+	name,
+	sql
+	FROM
+	sqlite_master
+	WHERE
+	type = 'table' AND
+	name NOT LIKE 'sqlite_%'
+	ORDER BY
+	name;
+	`)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		err = rows.Scan(&tableName, &createSql)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log2.FrameworkInfo("Importing schema for table ", tableName)
+		table := sqliteTable{
+			name:      tableName,
+			createSql: createSql,
+			comment:   tableComment(createSql),
+			columns:   []sqliteColumn{},
+			fkMap:     make(map[string]sqliteForeignKey),
+			indexes:   []sqliteIndex{},
+		}
+		if table.options, table.comment, err = sql2.ExtractOptions(table.comment); err != nil {
+			log2.Warning("Error in comment options for table " + table.name + " - " + err.Error())
+		}
+
+		tables = append(tables, table)
+	}
+	err = rows.Err()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return tables
+}
+
+func (m *DB) getColumns(table sqliteTable) (columns []sqliteColumn) {
+	rows, err := m.SqlDb().Query(fmt.Sprintf(`PRAGMA table_info(%s);`, iq(table.name)))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer rows.Close()
+
+	comments := columnComments(table.createSql)
+
+	for rows.Next() {
+		var cid int
+		col := sqliteColumn{}
+		err = rows.Scan(&cid, &col.name, &col.declaredType, &col.notNull, &col.defaultValue.R, &col.pk)
+		if err != nil {
+			log.Fatal(err)
+		}
+		col.defaultValue.R = normalizeDefault(col.defaultValue.R)
+		col.comment = comments[col.name]
+		if col.options, col.comment, err = sql2.ExtractOptions(col.comment); err != nil {
+			log2.Warning("Error in table comment options for table " + table.name + ":" + col.name + " - " + err.Error())
+		}
+		columns = append(columns, col)
+	}
+	err = rows.Err()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return
+}
+
+func (m *DB) getIndexes(tableName string) (indexes []sqliteIndex) {
+	rows, err := m.SqlDb().Query(fmt.Sprintf(`PRAGMA index_list(%s);`, iq(tableName)))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for rows.Next() {
+		var seq int
+		var partial bool
+		idx := sqliteIndex{}
+		err = rows.Scan(&seq, &idx.name, &idx.isUnique, &idx.origin, &partial)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if partial {
+			continue // partial indexes do not guarantee uniqueness across the whole table
+		}
+		indexes = append(indexes, idx)
+	}
+	err = rows.Err()
+	if err != nil {
+		log.Fatal(err)
+	}
+	rows.Close()
+
+	for i, idx := range indexes {
+		indexes[i].columnNames = m.getIndexColumns(idx.name)
+	}
+	return
+}
+
+func (m *DB) getIndexColumns(indexName string) (columnNames []string) {
+	rows, err := m.SqlDb().Query(fmt.Sprintf(`PRAGMA index_info(%s);`, iq(indexName)))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var seqNo, cid int
+		var name sql.NullString
+		err = rows.Scan(&seqNo, &cid, &name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if name.Valid { // expression indexes have no column name
+			columnNames = append(columnNames, name.String)
+		}
+	}
+	err = rows.Err()
+	if err != nil {
+		log.Fatal(err)
+	}
+	return
+}
+
+func (m *DB) getForeignKeys(table sqliteTable) (foreignKeys []sqliteForeignKey) {
+	rows, err := m.SqlDb().Query(fmt.Sprintf(`PRAGMA foreign_key_list(%s);`, iq(table.name)))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer rows.Close()
+
+	fkMap := make(map[int]sqliteForeignKey)
+	var ids []int
+	for rows.Next() {
+		var id, seq int
+		var match string
+		fk := sqliteForeignKey{tableName: table.name}
+		err = rows.Scan(&id, &seq, &fk.referencedTableName, &fk.columnName, &fk.referencedColumnName, &fk.updateRule, &fk.deleteRule, &match)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if seq > 0 {
+			log2.Warningf("Table %s has a foreign key on multiple columns. Multi-column foreign keys are not supported.", table.name)
+			delete(fkMap, id)
+			continue
+		}
+		fkMap[id] = fk
+		ids = append(ids, id)
+	}
+	err = rows.Err()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, id := range ids {
+		if fk, ok := fkMap[id]; ok {
+			foreignKeys = append(foreignKeys, fk)
+		}
+	}
+	return
+}
+
+// processTypeInfo converts the declared type of the column to a go table type.
+//
+// SQLite allows any type name to be declared, and uses the name only to determine the affinity of the column.
+// We recognize the common SQL type names so that databases designed for other SQL servers
+// will produce similar results, and fall back to the SQLite affinity rules otherwise.
+func (m *DB) processTypeInfo(tableName string, column sqliteColumn, cd *db.ColumnDescription) {
+	dataLen := sql2.GetDataDefLength(column.declaredType)
+	typ := strings.ToLower(column.declaredType)
+	if i := strings.Index(typ, "("); i >= 0 {
+		typ = strings.TrimSpace(typ[:i])
+	}
+	isUnsigned := strings.Contains(typ, "unsigned")
+	typ = strings.TrimSpace(strings.TrimSuffix(typ, "unsigned"))
+	cd.NativeType = column.declaredType
+
+	switch typ {
+	case "time":
+		cd.GoType = ColTypeTime.GoType()
+		cd.SubType = "time"
+	case "timestamp":
+		cd.GoType = ColTypeTime.GoType()
+		cd.SubType = "timestamp"
+	case "datetime":
+		cd.GoType = ColTypeTime.GoType()
+	case "date":
+		cd.GoType = ColTypeTime.GoType()
+		cd.SubType = "date"
+	case "bool", "boolean":
+		cd.GoType = ColTypeBool.GoType()
+	case "tinyint":
+		if dataLen == 1 {
+			cd.GoType = ColTypeBool.GoType()
+		} else if isUnsigned {
+			cd.GoType = ColTypeUnsigned.GoType()
+			cd.MinValue = uint64(0)
+			cd.MaxValue = uint64(255)
+			cd.MaxCharLength = 3
+		} else {
+			cd.GoType = ColTypeInteger.GoType()
+			cd.MinValue = int64(-128)
+			cd.MaxValue = int64(127)
+			cd.MaxCharLength = 4 // allow for a negative sign
+		}
+	case "smallint":
+		if isUnsigned {
+			cd.GoType = ColTypeUnsigned.GoType()
+			cd.MinValue = uint64(0)
+			cd.MaxValue = uint64(65535)
+			cd.MaxCharLength = 5
+		} else {
+			cd.GoType = ColTypeInteger.GoType()
+			cd.MinValue = int64(-32768)
+			cd.MaxValue = int64(32767)
+			cd.MaxCharLength = 6
+		}
+	case "int", "mediumint":
+		if isUnsigned {
+			cd.GoType = ColTypeUnsigned.GoType()
+			cd.MinValue = uint64(0)
+			cd.MaxValue = uint64(4294967295)
+			cd.MaxCharLength = 10
+		} else {
+			cd.GoType = ColTypeInteger.GoType()
+			cd.MinValue = int64(-2147483648)
+			cd.MaxValue = int64(2147483647)
+			cd.MaxCharLength = 11
+		}
+	case "integer":
+		// An INTEGER PRIMARY KEY is an alias for the rowid, which is always 64 bits,
+		// but we treat a plain INTEGER like other databases treat an INT.
+		cd.GoType = ColTypeInteger.GoType()
+	case "bigint", "int8":
+		if isUnsigned {
+			cd.GoType = ColTypeUnsigned64.GoType()
+			cd.MinValue = uint64(0)
+			cd.MaxValue = uint64(math.MaxUint64)
+			cd.MaxCharLength = 20
+		} else {
+			cd.GoType = ColTypeInteger64.GoType()
+			cd.MinValue = int64(math.MinInt64)
+			cd.MaxValue = int64(math.MaxInt64)
+			cd.MaxCharLength = 20
+		}
+	case "float":
+		cd.GoType = ColTypeFloat32.GoType()
+		cd.MinValue = -math.MaxFloat32 // float64 type
+		cd.MaxValue = math.MaxFloat32
+	case "real", "double", "double precision":
+		cd.GoType = ColTypeFloat64.GoType()
+		cd.MinValue = -math.MaxFloat64
+		cd.MaxValue = math.MaxFloat64
+	case "decimal", "numeric":
+		cd.GoType = ColTypeDecimal.GoType()
+		if dataLen > 0 {
+			cd.MaxCharLength = uint64(dataLen) + 3
+		}
+	case "uuid":
+		cd.GoType = ColTypeUUID.GoType()
+	case "varchar", "char", "character", "varying character", "nchar", "nvarchar", "native character":
+		cd.GoType = ColTypeString.GoType()
+		cd.MaxCharLength = uint64(dataLen)
+	case "text", "clob":
+		cd.GoType = ColTypeString.GoType()
+	case "json":
+		// SQLite stores JSON as text, but a column declared as json is treated as a JSON column
+		cd.GoType = ColTypeJSON.GoType()
+	case "blob", "":
+		cd.GoType = ColTypeBytes.GoType()
+	default:
+		// Use the SQLite affinity rules to determine the type.
+		switch {
+		case strings.Contains(typ, "int"):
+			cd.GoType = ColTypeInteger64.GoType()
+		case strings.Contains(typ, "char"), strings.Contains(typ, "clob"), strings.Contains(typ, "text"):
+			cd.GoType = ColTypeString.GoType()
+		case strings.Contains(typ, "blob"):
+			cd.GoType = ColTypeBytes.GoType()
+		case strings.Contains(typ, "real"), strings.Contains(typ, "floa"), strings.Contains(typ, "doub"):
+			cd.GoType = ColTypeFloat64.GoType()
+		default:
+			log2.Warning("Unknown type " + column.declaredType + " in table " + tableName + ":" + column.name + ". Treating it as a string.")
+			cd.GoType = ColTypeString.GoType()
+		}
+	}
+
+	cd.DefaultValue = column.defaultValue.UnpackDefaultValue(ColTypeFromGoTypeString(cd.GoType))
+}
+
+func (m *DB) descriptionFromRawTables(rawTables map[string]sqliteTable, options Options) db.DatabaseDescription {
+
+	dd := db.DatabaseDescription{}
+
+	keys := stringmap.SortedKeys(rawTables)
+	for _, tableName := range keys {
+		table := rawTables[tableName]
+		if table.options["skip"] != nil {
+			continue
+		}
+
+		if strings2.EndsWith(tableName, options.EnumTableSuffix) {
+			t := m.getEnumTableDescription(table)
+			dd.Tables = append(dd.Tables, t)
+		} else if strings2.EndsWith(tableName, options.AssociationTableSuffix) {
+			if mm, ok := m.getManyManyDescription(table, options.EnumTableSuffix); ok {
+				dd.MM = append(dd.MM, mm)
+			}
+		} else {
+			t := m.getTableDescription(table)
+			dd.Tables = append(dd.Tables, t)
+		}
+	}
+	return dd
+}
+
+func (m *DB) getTableDescription(t sqliteTable) db.TableDescription {
+	var columnDescriptions []db.ColumnDescription
+
+	// Build the indexes
+	pkColumns := make(map[string]bool)
+	indexes := make(map[string]*db.IndexDescription)
+	uniqueColumns := make(map[string]bool)
+
+	// The primary key is reported by table_info, since an INTEGER PRIMARY KEY does not have an index
+	for _, col := range t.columns {
+		if col.pk > 0 {
+			pkColumns[col.name] = true
+		}
+	}
+
+	for _, idx := range t.indexes {
+		if idx.origin == "pk" || len(idx.columnNames) == 0 {
+			continue
+		}
+		columnNames := append([]string(nil), idx.columnNames...)
+		sort.Strings(columnNames) // make sure this list stays in a predictable order each time
+		indexes[idx.name] = &db.IndexDescription{Name: idx.name, IsUnique: idx.isUnique, ColumnNames: columnNames}
+	}
+
+	// Fill the uniqueColumns map with all the columns that have a single unique index,
+	// including any PK columns. Single indexes are used to determine 1 to 1 relationships.
+	for _, i := range indexes {
+		if len(i.ColumnNames) == 1 && i.IsUnique {
+			uniqueColumns[i.ColumnNames[0]] = true
+		}
+	}
+	if len(pkColumns) == 1 {
+		for k := range pkColumns {
+			uniqueColumns[k] = true
+		}
+	}
+
+	var pkCount int
+	for _, col := range t.columns {
+		cd := m.getColumnDescription(t, col, pkColumns[col.name], uniqueColumns[col.name], len(pkColumns) == 1)
+
+		if cd.IsPk {
+			// private keys go first
+			// the following code does an insert after whatever previous pks have been found.
+			// It is important to do these in order.
+			columnDescriptions = append(columnDescriptions, db.ColumnDescription{})
+			copy(columnDescriptions[pkCount+1:], columnDescriptions[pkCount:])
+			columnDescriptions[pkCount] = cd
+			pkCount++
+		} else {
+			columnDescriptions = append(columnDescriptions, cd)
+		}
+	}
+
+	td := db.TableDescription{
+		Name:                t.name,
+		Columns:             columnDescriptions,
+		SupportsForeignKeys: true,
+	}
+
+	td.Comment = t.comment
+	td.Options = t.options
+
+	// Create the indexes array in index name order so its predictable
+	stringmap.Range(indexes, func(key string, val *db.IndexDescription) bool {
+		td.Indexes = append(td.Indexes, *val)
+		return true
+	})
+	return td
+}
+
+func (m *DB) getEnumTableDescription(t sqliteTable) db.TableDescription {
+	td := m.getTableDescription(t)
+
+	var columnNames []string
+	var quotedNames []string
+	var columnTypes []GoColumnType
+
+	for i, c := range td.Columns {
+		columnNames = append(columnNames, c.Name)
+		quotedNames = append(quotedNames, iq(c.Name))
+		colType := ColTypeFromGoTypeString(c.GoType)
+		if i == 0 {
+			colType = ColTypeInteger // Force first value to be treated like an integer
+		}
+		columnTypes = append(columnTypes, colType)
+	}
+
+	result, err := m.SqlDb().Query(`
+	SELECT ` +
+		strings.Join(quotedNames, `,`) +
+		`
+	FROM ` +
+		iq(td.Name) +
+		` ORDER BY ` + quotedNames[0])
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	values := sql2.SqlReceiveRows(result, columnTypes, columnNames, nil)
+	td.EnumData = values
+	return td
+}
+
+func (m *DB) getColumnDescription(table sqliteTable, column sqliteColumn, isPk bool, isUnique bool, isSinglePk bool) db.ColumnDescription {
+	cd := db.ColumnDescription{
+		Name: column.name,
+	}
+
+	m.processTypeInfo(table.name, column, &cd)
+
+	// In SQLite, a single column INTEGER PRIMARY KEY is an alias for the rowid, and so is automatically generated.
+	cd.IsId = isPk && isSinglePk && strings.EqualFold(strings.TrimSpace(column.declaredType), "integer")
+	cd.IsPk = isPk
+	cd.IsNullable = !column.notNull && !isPk
+	cd.IsUnique = isUnique
+
+	if s, ok := cd.DefaultValue.(string); ok && s == "now" {
+		cd.SubType = "timestamp"
+	}
+
+	cd.Comment = column.comment
+	cd.Options = column.options
+
+	if fk, ok2 := table.fkMap[cd.Name]; ok2 {
+		cd.ForeignKey = &db.ForeignKeyDescription{
+			ReferencedTable:  fk.referencedTableName,
+			ReferencedColumn: fk.referencedColumnName.String,
+			UpdateAction:     fkRuleToAction(fk.updateRule),
+			DeleteAction:     fkRuleToAction(fk.deleteRule),
+		}
+		if cd.ForeignKey.ReferencedColumn == "" {
+			// SQLite allows leaving off the referenced column, which then refers to the primary key
+			cd.ForeignKey.ReferencedColumn = m.primaryKeyName(fk.referencedTableName)
+		}
+	}
+
+	return cd
+}
+
+// primaryKeyName returns the name of the first primary key column of the given table.
+func (m *DB) primaryKeyName(tableName string) string {
+	rows, err := m.SqlDb().Query(fmt.Sprintf(`SELECT name FROM pragma_table_info(%s) WHERE pk = 1;`, "'"+tableName+"'"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer rows.Close()
+	var name string
+	for rows.Next() {
+		if err = rows.Scan(&name); err != nil {
+			log.Fatal(err)
+		}
+	}
+	return name
+}
+
+func (m *DB) getManyManyDescription(t sqliteTable, enumTableSuffix string) (mm db.ManyManyDescription, ok bool) {
+	td := m.getTableDescription(t)
+	if len(td.Columns) != 2 {
+		log2.Warning("table " + td.Name + " must have only 2 primary key columns.")
+		return
+	}
+	var typeIndex = -1
+	for i, cd := range td.Columns {
+		if !cd.IsPk {
+			log2.Warning("column " + td.Name + ":" + cd.Name + " must be a primary key.")
+			return
+		}
+
+		if cd.ForeignKey == nil {
+			log2.Warning("column " + td.Name + ":" + cd.Name + " must be a foreign key.")
+			return
+		}
+
+		if cd.ForeignKey.DeleteAction != db.FKActionCascade {
+			log2.Warning("column " + td.Name + ":" + cd.Name + " has a DELETE action that is not CASCADE. You will need to manually delete the relationship before the associated object is deleted.")
+		}
+
+		if strings2.EndsWith(cd.ForeignKey.ReferencedTable, enumTableSuffix) {
+			if typeIndex != -1 {
+				log2.Warning("column " + td.Name + ":" + " cannot have two foreign keys to enum tables.")
+				return
+			}
+			typeIndex = i
+		}
+	}
+
+	idx1 := 0
+	idx2 := 1
+	if typeIndex == 0 {
+		idx1 = 1
+		idx2 = 0
+	}
+	mm.Table1 = td.Columns[idx1].ForeignKey.ReferencedTable
+	mm.Column1 = td.Columns[idx1].Name
+	options := td.Columns[idx1].Options
+	if opt := options["goName"]; opt != nil {
+		if mm.GoName1, ok = opt.(string); !ok {
+			log2.Warning("Error in table comment for table " + t.name + ":" + mm.Column1 + ": goName is not a string")
+			return
+		}
+	}
+	if opt := options["goPlural"]; opt != nil {
+		if mm.GoPlural1, ok = opt.(string); !ok {
+			log2.Warning("Error in table comment for table " + t.name + ":" + mm.Column1 + ": goPlural is not a string")
+			return
+		}
+	}
+
+	mm.Table2 = td.Columns[idx2].ForeignKey.ReferencedTable
+	mm.Column2 = td.Columns[idx2].Name
+	options = td.Columns[idx2].Options
+	if opt := options["goName"]; opt != nil {
+		if mm.GoName2, ok = opt.(string); !ok {
+			log2.Warning("Error in table comment for table " + t.name + ":" + mm.Column2 + ": goName is not a string")
+			return
+		}
+	}
+	if opt := options["goPlural"]; opt != nil {
+		if mm.GoPlural2, ok = opt.(string); !ok {
+			log2.Warning("Error in table comment for table " + t.name + ":" + mm.Column2 + ": goPlural is not a string")
+			return
+		}
+	}
+
+	mm.AssnTableName = t.name
+	mm.SupportsForeignKeys = true
+	ok = true
+	return
+}
+
+func fkRuleToAction(rule string) db.FKAction {
+	switch strings.ToUpper(rule) {
+	case "NO ACTION":
+		fallthrough
+	case "RESTRICT":
+		return db.FKActionRestrict
+	case "CASCADE":
+		return db.FKActionCascade
+	case "SET DEFAULT":
+		return db.FKActionSetDefault
+	case "SET NULL":
+		return db.FKActionSetNull
+	}
+	return db.FKActionNone
+}
+
+// tableComment returns the SQL comment that is on the same line as the CREATE TABLE clause in
+// the given create statement.
+func tableComment(createSql string) string {
+	line, _, _ := strings.Cut(createSql, "\n")
+	if _, comment, found := strings.Cut(line, "--"); found {
+		return strings.TrimSpace(comment)
+	}
+	return ""
+}
+
+// columnComments returns the SQL comments found at the end of the column definitions in the
+// given create statement, keyed by column name.
+func columnComments(createSql string) map[string]string {
+	comments := make(map[string]string)
+	lines := strings.Split(createSql, "\n")
+	if len(lines) < 2 {
+		return comments
+	}
+	for _, line := range lines[1:] {
+		def, comment, found := strings.Cut(line, "--")
+		if !found {
+			continue
+		}
+		fields := strings.Fields(def)
+		if len(fields) == 0 {
+			continue
+		}
+		name := strings.Trim(fields[0], "\"`[]")
+		comments[name] = strings.TrimSpace(comment)
+	}
+	return comments
+}
+
+// normalizeDefault converts the text of a default value as it appears in the create statement
+// to the value it represents.
+func normalizeDefault(v interface{}) interface{} {
+	var s string
+	switch v2 := v.(type) {
+	case string:
+		s = v2
+	case []byte:
+		s = string(v2)
+	default:
+		return v
+	}
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "NULL") {
+		return nil
+	}
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		s = strings.ReplaceAll(s[1:len(s)-1], "''", "'")
+	}
+	return s
+}
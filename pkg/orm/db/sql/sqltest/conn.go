@@ -0,0 +1,174 @@
+// Package sqltest has a database/sql driver for tests of the SQL databases that need to see the statements
+// sent to the database without running a database server.
+package sqltest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Conn is a database/sql connector and connection that records the statements sent to it.
+//
+// Each Exec reports the next row id as the id of the inserted row. Queries return the result of Rows,
+// or no rows if Rows is nil.
+type Conn struct {
+	// FailOn makes the statements that start with it fail, without recording them.
+	FailOn string
+	// Rows returns the column names and the rows of the result of a query.
+	Rows func(query string, args []driver.Value) (columns []string, rows [][]driver.Value)
+
+	mu         sync.Mutex
+	statements []string
+	args       [][]driver.Value
+	lastId     int64
+}
+
+// DB returns a sql.DB that connects to c.
+func (c *Conn) DB() *sql.DB {
+	return sql.OpenDB(c)
+}
+
+// Statements returns the statements that were sent, including BEGIN, COMMIT and ROLLBACK.
+func (c *Conn) Statements() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.statements...)
+}
+
+// Args returns the arguments of the statements that were sent, in the same order as Statements.
+func (c *Conn) Args() [][]driver.Value {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([][]driver.Value(nil), c.args...)
+}
+
+// Reset forgets the statements that were sent.
+func (c *Conn) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.statements = nil
+	c.args = nil
+}
+
+func (c *Conn) record(query string, args []driver.NamedValue) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.FailOn != "" && strings.HasPrefix(query, c.FailOn) {
+		return errors.New("failed")
+	}
+	var values []driver.Value
+	for _, a := range args {
+		values = append(values, a.Value)
+	}
+	c.statements = append(c.statements, query)
+	c.args = append(c.args, values)
+	return nil
+}
+
+// Connect implements the driver.Connector interface.
+func (c *Conn) Connect(_ context.Context) (driver.Conn, error) {
+	return c, nil
+}
+
+// Driver implements the driver.Connector interface.
+func (c *Conn) Driver() driver.Driver {
+	return c
+}
+
+// Open implements the driver.Driver interface.
+func (c *Conn) Open(_ string) (driver.Conn, error) {
+	return c, nil
+}
+
+// Prepare implements the driver.Conn interface. Statements are sent without preparing them.
+func (c *Conn) Prepare(_ string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+// Close implements the driver.Conn interface.
+func (c *Conn) Close() error {
+	return nil
+}
+
+// Begin implements the driver.Conn interface.
+func (c *Conn) Begin() (driver.Tx, error) {
+	if err := c.record("BEGIN", nil); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Commit implements the driver.Tx interface.
+func (c *Conn) Commit() error {
+	return c.record("COMMIT", nil)
+}
+
+// Rollback implements the driver.Tx interface.
+func (c *Conn) Rollback() error {
+	return c.record("ROLLBACK", nil)
+}
+
+// ExecContext implements the driver.ExecerContext interface.
+func (c *Conn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if err := c.record(query, args); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastId++
+	return result(c.lastId), nil
+}
+
+// QueryContext implements the driver.QueryerContext interface.
+func (c *Conn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if err := c.record(query, args); err != nil {
+		return nil, err
+	}
+	r := &rows{}
+	if c.Rows != nil {
+		var values []driver.Value
+		for _, a := range args {
+			values = append(values, a.Value)
+		}
+		r.columns, r.values = c.Rows(query, values)
+	}
+	return r, nil
+}
+
+// result reports the id of the row inserted by a statement.
+type result int64
+
+func (r result) LastInsertId() (int64, error) {
+	return int64(r), nil
+}
+
+func (r result) RowsAffected() (int64, error) {
+	return 1, nil
+}
+
+type rows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *rows) Columns() []string {
+	return r.columns
+}
+
+func (r *rows) Close() error {
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
@@ -30,6 +30,22 @@ func ExtractOptions(comment string) (options map[string]interface{}, remainingCo
 	return
 }
 
+// CommentWithOptions returns a comment that has the options encoded in it as json, such that
+// ExtractOptions will return the options and comment again.
+func CommentWithOptions(comment string, options map[string]interface{}) string {
+	if len(options) == 0 {
+		return comment
+	}
+	b, err := json.Marshal(options)
+	if err != nil {
+		log.Panic(err)
+	}
+	if comment == "" {
+		return string(b)
+	}
+	return string(b) + " " + comment
+}
+
 // GetDataDefLength will extract the length from the definition given a data definition description of the table.
 // If more than one number, returns the first number
 // Example:
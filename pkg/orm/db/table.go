@@ -1,6 +1,7 @@
 package db
 
 import (
+	"github.com/goradd/goradd/pkg/orm/query"
 	strings2 "github.com/goradd/goradd/pkg/strings"
 	"github.com/kenshaw/snaker"
 )
@@ -38,6 +39,8 @@ type Table struct {
 	ManyManyReferences []*ManyManyReference
 	// ReverseReferences describes the many-to-one references pointing to this table
 	ReverseReferences []*ReverseReference
+	// HistoryTable is the table that records the changes to this table, or nil if the table does not have the history option.
+	HistoryTable *Table
 }
 
 func (t *Table) PrimaryKeyColumn() *Column {
@@ -50,6 +53,78 @@ func (t *Table) PrimaryKeyColumn() *Column {
 	return t.Columns[0]
 }
 
+// OptimisticLockColumn returns the column used for optimistic locking, or nil if the table does not have one.
+func (t *Table) OptimisticLockColumn() *Column {
+	for _, col := range t.Columns {
+		if col.IsOptimisticLock {
+			return col
+		}
+	}
+	return nil
+}
+
+// SoftDeleteColumn returns the column that marks records as deleted, or nil if the table does not use soft deletes.
+func (t *Table) SoftDeleteColumn() *Column {
+	for _, col := range t.Columns {
+		if col.IsSoftDelete {
+			return col
+		}
+	}
+	return nil
+}
+
+// TenantColumn returns the column that holds the tenant of each record, or nil if the table does not have one.
+func (t *Table) TenantColumn() *Column {
+	for _, col := range t.Columns {
+		if col.IsTenant {
+			return col
+		}
+	}
+	return nil
+}
+
+// AuditColumns returns the audit columns of the table.
+func (t *Table) AuditColumns() (cols []*Column) {
+	for _, col := range t.Columns {
+		if col.Audit != "" {
+			cols = append(cols, col)
+		}
+	}
+	return
+}
+
+// HasUpdateAudit returns true if the table has audit columns that change each time a record is saved.
+func (t *Table) HasUpdateAudit() bool {
+	for _, col := range t.Columns {
+		if col.Audit.IsUpdate() {
+			return true
+		}
+	}
+	return false
+}
+
+// ColumnTypeImports returns the import paths of the packages needed by the Go types of the columns of the table,
+// including the types given to JSON columns with the jsonType option.
+func (t *Table) ColumnTypeImports() (imports []string) {
+	seen := make(map[string]bool)
+	add := func(imp string) {
+		if imp != "" && !seen[imp] {
+			seen[imp] = true
+			imports = append(imports, imp)
+		}
+	}
+	for _, col := range t.Columns {
+		switch col.ColumnType {
+		case query.ColTypeDecimal:
+			add("github.com/shopspring/decimal")
+		case query.ColTypeUUID:
+			add("github.com/google/uuid")
+		}
+		add(col.JsonGoTypeImport)
+	}
+	return
+}
+
 func (t *Table) PrimaryKeyGoType() string {
 	return t.PrimaryKeyColumn().ColumnType.GoType()
 }
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"github.com/goradd/goradd/pkg/goradd"
+)
+
+const tenantContext = goradd.ContextKey("goradd.tenant")
+
+// tenantInfo is what is stored in the context for the tenant.
+type tenantInfo struct {
+	tenant interface{}
+	all    bool
+}
+
+// WithTenant returns a context in which queries of tables with a tenant column only find the records of the given tenant,
+// and new records of those tables are given the tenant. The type of tenant must be the Go type of the tenant columns.
+func WithTenant(ctx context.Context, tenant interface{}) context.Context {
+	return context.WithValue(ctx, tenantContext, tenantInfo{tenant: tenant})
+}
+
+// WithoutTenant returns a context in which queries of tables with a tenant column find the records of all tenants.
+// Use it in administrative tools that work across tenants.
+func WithoutTenant(ctx context.Context) context.Context {
+	return context.WithValue(ctx, tenantContext, tenantInfo{all: true})
+}
+
+// Tenant returns the tenant that was put in the context with WithTenant, or nil if there is none.
+func Tenant(ctx context.Context) interface{} {
+	i, _ := ctx.Value(tenantContext).(tenantInfo)
+	return i.tenant
+}
+
+// IsWithoutTenant returns true if the context was prepared with WithoutTenant.
+func IsWithoutTenant(ctx context.Context) bool {
+	i, _ := ctx.Value(tenantContext).(tenantInfo)
+	return i.all
+}
+
+// InsertTenant returns the tenant in the context, for giving it to a new record of table. T is the Go type of the
+// tenant column of the table. ok is false if there is no tenant in the context. An error is returned if the tenant
+// is not a T, rather than inserting the record without a tenant.
+func InsertTenant[T any](ctx context.Context, table string) (tenant T, ok bool, err error) {
+	v := Tenant(ctx)
+	if v == nil {
+		return
+	}
+	if tenant, ok = v.(T); !ok {
+		err = fmt.Errorf("the tenant of the %s table must be of type %T, but the tenant in the context is of type %T", table, tenant, v)
+	}
+	return
+}
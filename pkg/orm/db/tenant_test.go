@@ -0,0 +1,24 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInsertTenant(t *testing.T) {
+	tenant, ok, err := InsertTenant[int](WithTenant(context.Background(), 7), "project")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 7, tenant)
+
+	_, ok, err = InsertTenant[int](context.Background(), "project")
+	assert.NoError(t, err)
+	assert.False(t, ok, "there is no tenant to give the record")
+
+	// inserting a record with a tenant of the wrong type is an error
+	_, ok, err = InsertTenant[int](WithTenant(context.Background(), "7"), "project")
+	assert.False(t, ok)
+	assert.EqualError(t, err, "the tenant of the project table must be of type int, but the tenant in the context is of type string")
+}
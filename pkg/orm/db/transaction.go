@@ -0,0 +1,88 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy controls how ExecuteTransactionWithRetry retries a transaction that failed because of
+// a deadlock or serialization failure.
+type RetryPolicy struct {
+	// MaxAttempts is the most number of times the transaction will be tried. A value of one or less means
+	// the transaction will not be retried.
+	MaxAttempts int
+	// Backoff returns how long to wait before making the given attempt, which will be 2 or more.
+	// If nil, the next attempt is made immediately.
+	Backoff func(attempt int) time.Duration
+}
+
+// DefaultRetryPolicy is the policy used by ExecuteTransactionWithRetry when no policy is given.
+// Change it during app startup to change the retry policy of the whole application.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	Backoff:     ExponentialBackoff(20*time.Millisecond, time.Second),
+}
+
+// ExponentialBackoff returns a Backoff function for a RetryPolicy that doubles the wait before each attempt,
+// starting with base and going no higher than max. A random jitter of up to half of the wait is added
+// so that competing processes do not retry at the same time.
+func ExponentialBackoff(base time.Duration, max time.Duration) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		d := base
+		for i := 2; i < attempt && d < max; i++ {
+			d *= 2
+		}
+		if d > max {
+			d = max
+		}
+		return d + time.Duration(rand.Int63n(int64(d)/2+1))
+	}
+}
+
+// ExecuteTransactionWithRetry is like ExecuteTransactionE, but if the transaction fails with a DeadlockError,
+// the transaction is rolled back and f is called again, following the given policy. If policy is nil,
+// DefaultRetryPolicy is used.
+//
+// Since f may be called more than once, it should start from a clean state each time it is called, for example by
+// loading the objects it works on rather than using objects that were loaded before the transaction.
+//
+// A transaction nested in another transaction is not retried, since the database aborts the whole transaction
+// in the event of a deadlock. The error is returned instead so that the outermost transaction can retry.
+func ExecuteTransactionWithRetry(ctx context.Context, d DatabaseI, policy *RetryPolicy, f func() error) error {
+	if policy == nil {
+		policy = &DefaultRetryPolicy
+	}
+	for attempt := 1; ; attempt++ {
+		txid, err := executeTransaction(ctx, d, f)
+		var deadlock DeadlockError
+		if err == nil ||
+			txid > 1 ||
+			attempt >= policy.MaxAttempts ||
+			!errors.As(err, &deadlock) {
+			return err
+		}
+		if policy.Backoff != nil {
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(policy.Backoff(attempt + 1)):
+			}
+		}
+	}
+}
+
+// executeTransaction runs f in a transaction, and returns the id of the transaction and any error that occurred.
+func executeTransaction(ctx context.Context, d DatabaseI, f func() error) (txid TransactionID, err error) {
+	txid, err = d.BeginE(ctx)
+	if err != nil {
+		return
+	}
+	defer d.Rollback(ctx, txid)
+	if err = f(); err != nil {
+		return
+	}
+	err = d.CommitE(ctx, txid)
+	return
+}
@@ -0,0 +1,50 @@
+package op
+
+import (
+	"fmt"
+	. "github.com/goradd/goradd/pkg/orm/query"
+	"reflect"
+	"strings"
+)
+
+// ArrayAny tests whether value equals any of the items in the array column arrayNode, like:
+//
+//	ArrayAny(node.Post().Tags(), "go")
+func ArrayAny(arrayNode NodeI, value interface{}) *OperationNode {
+	return NewOperationNode(OpArrayAny, arrayNode, value)
+}
+
+// ArrayAll tests whether value equals all the items in the array column arrayNode.
+// It is also true if the array is empty.
+func ArrayAll(arrayNode NodeI, value interface{}) *OperationNode {
+	return NewOperationNode(OpArrayAll, arrayNode, value)
+}
+
+// ArrayOverlaps tests whether the array column arrayNode has at least one item in common with values,
+// which must be a slice.
+func ArrayOverlaps(arrayNode NodeI, values interface{}) *OperationNode {
+	return NewOperationNode(OpArrayOverlaps, arrayNode, arrayLiteral(values))
+}
+
+// ArrayContains tests whether the array column arrayNode contains all the items in values,
+// which must be a slice.
+func ArrayContains(arrayNode NodeI, values interface{}) *OperationNode {
+	return NewOperationNode(OpArrayContains, arrayNode, arrayLiteral(values))
+}
+
+// arrayLiteral returns the values as an array in SQL text format, like {"a","b"}, so that
+// the array is sent to the database as one value rather than as a list of values.
+func arrayLiteral(values interface{}) string {
+	v := reflect.ValueOf(values)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		panic("values must be a slice")
+	}
+	items := make([]string, v.Len())
+	for i := range items {
+		s := fmt.Sprint(v.Index(i).Interface())
+		s = strings.ReplaceAll(s, `\`, `\\`)
+		s = strings.ReplaceAll(s, `"`, `\"`)
+		items[i] = `"` + s + `"`
+	}
+	return "{" + strings.Join(items, ",") + "}"
+}
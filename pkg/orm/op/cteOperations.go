@@ -0,0 +1,22 @@
+package op
+
+import . "github.com/goradd/goradd/pkg/orm/query"
+
+// Cte returns a common table expression with the given name that is defined by the query in subquery.
+// Refer to its columns with Column, which adds the Cte to the query that uses them.
+// For example, to find a project and all the projects under it:
+//
+//	tree := op.Cte("tree", model.QueryProjects(ctx).
+//	  Select(node.Project().ID()).
+//	  Where(op.Equal(node.Project().ID(), id)).
+//	  Subquery())
+//	tree.Union(model.QueryProjects(ctx).
+//	  Select(node.Project().ID()).
+//	  Where(op.Equal(node.Project().Parents().ID(), tree.Column("id"))).
+//	  Subquery())
+//	projects := model.QueryProjects(ctx).
+//	  Where(op.Equal(node.Project().ID(), tree.Column("id"))).
+//	  Load()
+func Cte(name string, subquery *SubqueryNode) *CommonTableExpression {
+	return NewCte(name, subquery)
+}
@@ -1,6 +1,9 @@
 package op
 
-import . "github.com/goradd/goradd/pkg/orm/query"
+import (
+	"encoding/json"
+	. "github.com/goradd/goradd/pkg/orm/query"
+)
 
 func StartsWith(arg1 interface{}, arg2 string) *OperationNode {
 	return NewOperationNode(OpStartsWith, arg1, arg2)
@@ -18,3 +21,36 @@ func DateAddSeconds(arg1 interface{}, arg2 interface{}) *OperationNode {
 	return NewOperationNode(OpDateAddSeconds, arg1, arg2)
 }
 
+
+// JsonExtract returns the value found by following the given keys into the JSON document in arg1, as text.
+// Keys are the names of object members. It can be used in Where, OrderBy and Alias nodes, like:
+//
+//	JsonExtract(node.Person().Settings(), "address", "city")
+//
+// If nothing is found at the path, the result is NULL.
+func JsonExtract(arg1 interface{}, keys ...string) *OperationNode {
+	operands := []interface{}{arg1}
+	for _, k := range keys {
+		operands = append(operands, k)
+	}
+	return NewOperationNode(OpJsonExtract, operands...)
+}
+
+// JsonContains tests whether the JSON document in arg1 contains the value in arg2.
+// arg2 can be a json.RawMessage, or a Go value that will be encoded as JSON, like a map.
+// An object contains another object if it has all of its members, and an array contains a value if one of
+// its items contains it.
+func JsonContains(arg1 interface{}, arg2 interface{}) *OperationNode {
+	var s string
+	switch v := arg2.(type) {
+	case json.RawMessage:
+		s = string(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			panic(err)
+		}
+		s = string(b)
+	}
+	return NewOperationNode(OpJsonContains, arg1, s)
+}
@@ -0,0 +1,39 @@
+package op
+
+import (
+	. "github.com/goradd/goradd/pkg/orm/query"
+)
+
+// Match tests whether the text columns match the search text using the full text search of the database.
+// The columns must belong to the same table, and should be covered by a full text index, like:
+//
+//	Match("database search", node.Project().Name(), node.Project().Description())
+//
+// MySQL searches in natural language mode, and Postgres compares the to_tsvector of the columns with the
+// plainto_tsquery of the text, using the search configuration of the matching full text index.
+func Match(text string, columns ...NodeI) *OperationNode {
+	return NewOperationNode(OpMatch, matchOperands(text, columns)...)
+}
+
+// MatchScore returns the relevance of the text columns to the search text, with larger numbers being more relevant.
+// Use it in an Alias to sort by relevance, like:
+//
+//	Alias("score", MatchScore(text, node.Project().Name())).
+//	OrderBy(Alias("score").Descending())
+func MatchScore(text string, columns ...NodeI) *OperationNode {
+	return NewOperationNode(OpMatchScore, matchOperands(text, columns)...)
+}
+
+func matchOperands(text string, columns []NodeI) []interface{} {
+	if len(columns) == 0 {
+		panic("a full text search needs at least one column")
+	}
+	operands := []interface{}{text}
+	for _, c := range columns {
+		if _, ok := c.(*ColumnNode); !ok {
+			panic("a full text search can only search column nodes")
+		}
+		operands = append(operands, c)
+	}
+	return operands
+}
@@ -0,0 +1,48 @@
+package op
+
+import . "github.com/goradd/goradd/pkg/orm/query"
+
+// Window functions calculate a value for each row from a set of related rows, called a window.
+// Use Over to turn a function into a window expression, and then add it to a query with Alias, like:
+//
+//	model.QueryProjects(ctx).
+//	  Alias("rank", op.Over(op.Rank()).PartitionBy(node.Project().StatusID()).OrderBy(node.Project().Spent().Descending()))
+//
+// The aggregate functions, like Sum, can also be used as window functions, which gives running totals
+// when the window is ordered:
+//
+//	op.Over(op.Sum(node.Project().Spent())).OrderBy(node.Project().StartDate())
+//
+// Window functions cannot be used in a Where condition. To filter on one, select it in a Cte and
+// use the Cte in the query.
+
+// Over returns a window expression that calculates the function over a window of rows.
+func Over(function NodeI) *WindowNode {
+	return NewWindowNode(function)
+}
+
+// RowNumber numbers the rows of the window, starting at 1.
+func RowNumber() *OperationNode {
+	return NewFunctionNode("ROW_NUMBER")
+}
+
+// Rank numbers the rows of the window by their order, giving rows that sort the same the same number,
+// and leaving gaps after them.
+func Rank() *OperationNode {
+	return NewFunctionNode("RANK")
+}
+
+// DenseRank numbers the rows of the window like Rank, but without leaving gaps.
+func DenseRank() *OperationNode {
+	return NewFunctionNode("DENSE_RANK")
+}
+
+// Lag returns the value of n in the row that is offset rows before the current row in the window.
+func Lag(n NodeI, offset int) *OperationNode {
+	return NewFunctionNode("LAG", n, offset)
+}
+
+// Lead returns the value of n in the row that is offset rows after the current row in the window.
+func Lead(n NodeI, offset int) *OperationNode {
+	return NewFunctionNode("LEAD", n, offset)
+}
@@ -5,6 +5,9 @@ import "context"
 // AliasResults is the special item to use for named aliases in the result set
 const AliasResults = "aliases_"
 
+// JoinAliasResults is the special item to use for the results of aliased joins in the result set
+const JoinAliasResults = "joins_"
+
 // QueryBuilderI is the primary aid in creating cross-platform, portable queries to the database(s)
 // The code-generated ORM classes call these functions to build a query. The query will eventually get
 // sent to the database for processing, and then unpacked into one of the ORM generated objects. You generally
@@ -23,10 +26,18 @@ type QueryBuilderI interface {
 	Limit(maxRowCount int, offset int)
 	Select(nodes ...NodeI)
 	Distinct()
+	// Cache marks the query as one whose results can be cached, if the database has a cache.
+	Cache()
+	// WithDeleted includes the records that are marked as deleted by a soft delete column, both in the table
+	// being queried and in the tables joined to it.
+	WithDeleted()
 	Alias(name string, n NodeI)
 	// Load terminates the builder, queries the database, and returns the results as an array of interfaces similar in structure to a json structure
 	Load() []map[string]interface{}
 	Delete()
+	// Update sets the given columns of all the records that match the conditions of the query.
+	// The keys are column nodes of the table being queried, and the values are either Go values or nodes.
+	Update(fields map[NodeI]interface{})
 	Count(distinct bool, nodes ...NodeI) uint
 	Subquery() *SubqueryNode
 	Context() context.Context
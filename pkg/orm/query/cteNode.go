@@ -0,0 +1,213 @@
+package query
+
+import (
+	"bytes"
+	"encoding/gob"
+	"github.com/goradd/goradd/pkg/log"
+	"strings"
+)
+
+// A CommonTableExpression, or Cte, is a named query that is defined in a WITH clause at the start
+// of a query, and that can then be used like a table by the query and its subqueries.
+//
+// A Cte can be recursive, in which case it is made of an anchor query, and a recursive query that refers
+// to the Cte itself. The database runs the anchor query, and then repeatedly runs the recursive query on the rows
+// found by the previous run until no more rows are found. This makes it possible to walk tree structures,
+// like a table that refers to itself.
+//
+// You generally create one using op.Cte, and refer to its columns with Column.
+type CommonTableExpression struct {
+	name      string
+	anchor    QueryBuilderI
+	recursive QueryBuilderI
+	unionAll  bool
+}
+
+// NewCte creates a new common table expression with the given name, using the query in the subquery.
+// The columns of the Cte are the selected columns of the subquery, named by their names in the database,
+// followed by the aliases of the subquery.
+func NewCte(name string, subquery *SubqueryNode) *CommonTableExpression {
+	return &CommonTableExpression{
+		name:   name,
+		anchor: subquery.b,
+	}
+}
+
+// Union makes the Cte a recursive Cte. The query in subquery should refer to columns of the Cte,
+// and must return the same columns as the anchor query. Duplicate rows are removed, which prevents
+// the query from looping forever when the data has a cycle.
+func (c *CommonTableExpression) Union(subquery *SubqueryNode) *CommonTableExpression {
+	if c.recursive != nil {
+		panic("the Cte is already recursive")
+	}
+	c.recursive = subquery.b
+	return c
+}
+
+// UnionAll makes the Cte a recursive Cte like Union, but does not remove duplicate rows.
+func (c *CommonTableExpression) UnionAll(subquery *SubqueryNode) *CommonTableExpression {
+	c.Union(subquery)
+	c.unionAll = true
+	return c
+}
+
+// Column returns a node that refers to the column of the Cte with the given name.
+// Using the node in a query makes the Cte a part of the query, joining each row of the query with each row
+// of the Cte, so you will generally use the node in a condition that relates the Cte to the query.
+func (c *CommonTableExpression) Column(name string) *CteColumnNode {
+	return &CteColumnNode{
+		cte:    c,
+		column: name,
+	}
+}
+
+func (c *CommonTableExpression) GobEncode() (data []byte, err error) {
+	var buf bytes.Buffer
+	e := gob.NewEncoder(&buf)
+
+	if err = e.Encode(c.name); err != nil {
+		panic(err)
+	}
+	if err = e.Encode(&c.anchor); err != nil {
+		panic(err)
+	}
+	if err = e.Encode(c.recursive != nil); err != nil {
+		panic(err)
+	}
+	if c.recursive != nil {
+		if err = e.Encode(&c.recursive); err != nil {
+			panic(err)
+		}
+	}
+	if err = e.Encode(c.unionAll); err != nil {
+		panic(err)
+	}
+	data = buf.Bytes()
+	return
+}
+
+func (c *CommonTableExpression) GobDecode(data []byte) (err error) {
+	buf := bytes.NewBuffer(data)
+	dec := gob.NewDecoder(buf)
+	if err = dec.Decode(&c.name); err != nil {
+		panic(err)
+	}
+	if err = dec.Decode(&c.anchor); err != nil {
+		panic(err)
+	}
+	var isRecursive bool
+	if err = dec.Decode(&isRecursive); err != nil {
+		panic(err)
+	}
+	if isRecursive {
+		if err = dec.Decode(&c.recursive); err != nil {
+			panic(err)
+		}
+	}
+	if err = dec.Decode(&c.unionAll); err != nil {
+		panic(err)
+	}
+	return
+}
+
+// CteName is used internally by the framework to get the name of the Cte.
+func CteName(c *CommonTableExpression) string {
+	return c.name
+}
+
+// CteBuilders is used internally by the framework to get the query builders of the Cte. The first is the
+// anchor query, and the second, if present, is the recursive query.
+func CteBuilders(c *CommonTableExpression) []QueryBuilderI {
+	if c.recursive == nil {
+		return []QueryBuilderI{c.anchor}
+	}
+	return []QueryBuilderI{c.anchor, c.recursive}
+}
+
+// CteIsRecursive is used internally by the framework to determine if the Cte is recursive.
+func CteIsRecursive(c *CommonTableExpression) bool {
+	return c.recursive != nil
+}
+
+// CteUnionAll is used internally by the framework to determine if the recursive query keeps duplicate rows.
+func CteUnionAll(c *CommonTableExpression) bool {
+	return c.unionAll
+}
+
+// A CteColumnNode refers to a column of a common table expression.
+type CteColumnNode struct {
+	nodeAlias
+	cte    *CommonTableExpression
+	column string
+}
+
+func (n *CteColumnNode) nodeType() NodeType {
+	return CteColumnNodeType
+}
+
+// Equals is used internally by the framework to tell if two nodes are equal
+func (n *CteColumnNode) Equals(n2 NodeI) bool {
+	if cn, ok := n2.(*CteColumnNode); ok {
+		return cn.cte == n.cte && cn.column == n.column
+	}
+	return false
+}
+
+func (n *CteColumnNode) tableName() string {
+	return ""
+}
+
+func (n *CteColumnNode) databaseKey() string {
+	return ""
+}
+
+func (n *CteColumnNode) log(level int) {
+	tabs := strings.Repeat("\t", level)
+	log.FrameworkDebug(tabs + "CteCol: " + n.cte.name + "." + n.column)
+}
+
+func (n *CteColumnNode) GobEncode() (data []byte, err error) {
+	var buf bytes.Buffer
+	e := gob.NewEncoder(&buf)
+
+	if err = e.Encode(n.alias); err != nil {
+		panic(err)
+	}
+	if err = e.Encode(n.cte); err != nil {
+		panic(err)
+	}
+	if err = e.Encode(n.column); err != nil {
+		panic(err)
+	}
+	data = buf.Bytes()
+	return
+}
+
+func (n *CteColumnNode) GobDecode(data []byte) (err error) {
+	buf := bytes.NewBuffer(data)
+	dec := gob.NewDecoder(buf)
+	if err = dec.Decode(&n.alias); err != nil {
+		panic(err)
+	}
+	if err = dec.Decode(&n.cte); err != nil {
+		panic(err)
+	}
+	if err = dec.Decode(&n.column); err != nil {
+		panic(err)
+	}
+	return
+}
+
+func init() {
+	gob.Register(&CteColumnNode{})
+}
+
+// CteColumnNodeCte is used internally by the framework to get the Cte of the column.
+func CteColumnNodeCte(n *CteColumnNode) *CommonTableExpression {
+	return n.cte
+}
+
+// CteColumnNodeName is used internally by the framework to get the name of the column.
+func CteColumnNodeName(n *CteColumnNode) string {
+	return n.column
+}
@@ -1,8 +1,12 @@
 package query
 
 import (
+	"encoding/json"
+	"github.com/google/uuid"
 	"github.com/goradd/goradd/pkg/config"
+	"github.com/shopspring/decimal"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -21,6 +25,12 @@ const (
 	ColTypeFloat32
 	ColTypeFloat64
 	ColTypeBool
+	ColTypeJSON
+	ColTypeDecimal
+	ColTypeUUID
+	ColTypeStringSlice
+	ColTypeIntegerSlice
+	ColTypeInteger64Slice
 )
 
 // String returns the constant type name as a string
@@ -48,6 +58,18 @@ func (g GoColumnType) String() string {
 		return "ColTypeFloat64"
 	case ColTypeBool:
 		return "ColTypeBool"
+	case ColTypeJSON:
+		return "ColTypeJSON"
+	case ColTypeDecimal:
+		return "ColTypeDecimal"
+	case ColTypeUUID:
+		return "ColTypeUUID"
+	case ColTypeStringSlice:
+		return "ColTypeStringSlice"
+	case ColTypeIntegerSlice:
+		return "ColTypeIntegerSlice"
+	case ColTypeInteger64Slice:
+		return "ColTypeInteger64Slice"
 	}
 	return ""
 }
@@ -77,6 +99,18 @@ func (g GoColumnType) GoType() string {
 		return "float64" // always internally represent with max bits
 	case ColTypeBool:
 		return "bool"
+	case ColTypeJSON:
+		return "json.RawMessage"
+	case ColTypeDecimal:
+		return "decimal.Decimal"
+	case ColTypeUUID:
+		return "uuid.UUID"
+	case ColTypeStringSlice:
+		return "[]string"
+	case ColTypeIntegerSlice:
+		return "[]int"
+	case ColTypeInteger64Slice:
+		return "[]int64"
 	}
 	return ""
 }
@@ -106,6 +140,14 @@ func (g GoColumnType) DefaultValue() string {
 		return "0.0" // always internally represent with max bits
 	case ColTypeBool:
 		return "false"
+	case ColTypeJSON:
+		return ""
+	case ColTypeDecimal:
+		return "decimal.Zero"
+	case ColTypeUUID:
+		return "uuid.Nil"
+	case ColTypeStringSlice, ColTypeIntegerSlice, ColTypeInteger64Slice:
+		return ""
 	}
 	return ""
 }
@@ -134,12 +176,24 @@ func ColTypeFromGoTypeString(name string) GoColumnType {
 		return ColTypeFloat64
 	case "bool":
 		return ColTypeBool
+	case "json.RawMessage":
+		return ColTypeJSON
+	case "decimal.Decimal":
+		return ColTypeDecimal
+	case "uuid.UUID":
+		return ColTypeUUID
+	case "[]string":
+		return ColTypeStringSlice
+	case "[]int":
+		return ColTypeIntegerSlice
+	case "[]int64":
+		return ColTypeInteger64Slice
 	default:
 		panic("unknown column go type " + name)
 	}
 }
 
-// FromString will convert from a string to the correct Go type
+// FromString will convert from a string to the correct Go type. Slices are given as comma separated values.
 func (g GoColumnType) FromString(s string) any {
 	switch g {
 	case ColTypeUnknown:
@@ -192,6 +246,40 @@ func (g GoColumnType) FromString(s string) any {
 		return f
 	case ColTypeBool:
 		return s == "true"
+	case ColTypeJSON:
+		if s == "" {
+			return json.RawMessage(nil)
+		}
+		return json.RawMessage(s)
+	case ColTypeDecimal:
+		d, _ := decimal.NewFromString(s)
+		return d
+	case ColTypeUUID:
+		u, _ := uuid.Parse(s)
+		return u
+	case ColTypeStringSlice:
+		if s == "" {
+			return []string(nil)
+		}
+		return strings.Split(s, ",")
+	case ColTypeIntegerSlice:
+		var a []int
+		for _, s2 := range strings.FieldsFunc(s, isComma) {
+			i, _ := strconv.Atoi(strings.TrimSpace(s2))
+			a = append(a, i)
+		}
+		return a
+	case ColTypeInteger64Slice:
+		var a []int64
+		for _, s2 := range strings.FieldsFunc(s, isComma) {
+			i, _ := strconv.ParseInt(strings.TrimSpace(s2), 10, 64)
+			a = append(a, i)
+		}
+		return a
 	}
 	return ""
 }
+
+func isComma(r rune) bool {
+	return r == ','
+}
@@ -111,7 +111,7 @@ func (n *ManyManyNode) Equals(n2 NodeI) bool {
 	} else {
 		return cn.dbTable == n.dbTable &&
 			cn.goPropName == n.goPropName &&
-			(cn.alias == "" || n.alias == "" || cn.alias == n.alias)
+			cn.alias == n.alias
 
 	}
 }
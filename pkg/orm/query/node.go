@@ -29,6 +29,8 @@ const (
 	OperationNodeType
 	AliasNodeType
 	SubqueryNodeType
+	WindowNodeType
+	CteColumnNodeType
 )
 
 type goNamer interface {
@@ -10,6 +10,7 @@ import (
 type OperationNodeI interface {
 	nodeContainer
 	Aliaser
+	NodeSorter
 }
 
 // Operator is used internally by the framework to specify an operation to be performed by the database.
@@ -72,7 +73,15 @@ const (
 	OpStartsWith     = "StartsWith"
 	OpEndsWith       = "EndsWith"
 	OpContains       = "Contains"
-	OpDateAddSeconds = "AddSeconds" // Adds the given number of seconds to a datetime
+	OpDateAddSeconds = "AddSeconds"    // Adds the given number of seconds to a datetime
+	OpJsonExtract    = "JsonExtract"   // Extracts the value at a path of keys in a JSON document as text
+	OpJsonContains   = "JsonContains"  // Tests whether a JSON document contains another JSON document
+	OpArrayAny       = "ArrayAny"      // Tests whether a value equals any item of an array
+	OpArrayAll       = "ArrayAll"      // Tests whether a value equals all the items of an array
+	OpArrayOverlaps  = "ArrayOverlaps" // Tests whether two arrays have an item in common
+	OpArrayContains  = "ArrayContains" // Tests whether an array contains all the items of another array
+	OpMatch          = "Match"         // Tests whether text columns match a full text search
+	OpMatchScore     = "MatchScore"    // The relevance of text columns to a full text search
 )
 
 // String returns a string representation of the Operator type. For convenience, this also corresponds to the SQL
@@ -85,10 +94,11 @@ func (o Operator) String() string {
 // The operation could be arithmetic, boolean, or a function.
 type OperationNode struct {
 	nodeAlias
-	op           Operator
-	operands     []NodeI
-	functionName string // for function operations specific to the db driver
-	distinct     bool   // some aggregate queries, particularly count, allow this inside the function
+	op             Operator
+	operands       []NodeI
+	functionName   string // for function operations specific to the db driver
+	distinct       bool   // some aggregate queries, particularly count, allow this inside the function
+	sortDescending bool   // when used in an OrderBy, whether to sort in descending order
 }
 
 // NewOperationNode returns a new operation.
@@ -143,17 +153,18 @@ func (n *OperationNode) assignOperands(operands ...interface{}) {
 	}
 }
 
-/*
-func (n *OperationNode) Ascending() *OperationNode {
+// Ascending is used in an OrderBy query builder function to sort by the result of the operation in ascending order.
+func (n *OperationNode) Ascending() NodeI {
 	n.sortDescending = false
 	return n
 }
 
-func (n *OperationNode) Descending() *OperationNode {
+// Descending is used in an OrderBy query builder function to sort by the result of the operation in descending order,
+// like sorting the most relevant search results first.
+func (n *OperationNode) Descending() NodeI {
 	n.sortDescending = true
 	return n
 }
-*/
 
 // Distinct sets the operation to return distinct results
 func (n *OperationNode) Distinct() *OperationNode {
@@ -161,11 +172,9 @@ func (n *OperationNode) Distinct() *OperationNode {
 	return n
 }
 
-/*
 func (n *OperationNode) sortDesc() bool {
 	return n.sortDescending
 }
-*/
 
 // Equals is used internally by the framework to tell if two nodes are equal
 func (n *OperationNode) Equals(n2 NodeI) bool {
@@ -245,6 +254,9 @@ func (n *OperationNode) GobEncode() (data []byte, err error) {
 	if err = e.Encode(n.distinct); err != nil {
 		panic(err)
 	}
+	if err = e.Encode(n.sortDescending); err != nil {
+		panic(err)
+	}
 	data = buf.Bytes()
 	return
 }
@@ -267,6 +279,9 @@ func (n *OperationNode) GobDecode(data []byte) (err error) {
 	if err = dec.Decode(&n.distinct); err != nil {
 		panic(err)
 	}
+	if err = dec.Decode(&n.sortDescending); err != nil {
+		panic(err)
+	}
 	return
 }
 
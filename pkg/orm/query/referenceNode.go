@@ -96,7 +96,7 @@ func (n *ReferenceNode) Equals(n2 NodeI) bool {
 	} else {
 		return cn.dbTable == n.dbTable &&
 			cn.goPropName == n.goPropName &&
-			(cn.alias == "" || n.alias == "" || cn.alias == n.alias)
+			cn.alias == n.alias
 	}
 }
 
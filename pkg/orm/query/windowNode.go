@@ -0,0 +1,150 @@
+package query
+
+import (
+	"bytes"
+	"encoding/gob"
+	"github.com/goradd/goradd/pkg/log"
+	"strings"
+)
+
+// A WindowNode represents a window function, which is a function that is calculated over a set of rows
+// related to the current row, like ROW_NUMBER() OVER (PARTITION BY ... ORDER BY ...).
+// Unlike an aggregate function, the rows are not grouped into a single result row.
+// You generally create one using op.Over, and then add it to a query using Alias.
+type WindowNode struct {
+	nodeAlias
+	function     NodeI
+	partitionBys []NodeI
+	orderBys     []NodeI
+}
+
+// NewWindowNode returns a new window node that will calculate the given function over a window.
+func NewWindowNode(function NodeI) *WindowNode {
+	return &WindowNode{function: function}
+}
+
+// PartitionBy divides the rows into groups that have the same values for the given nodes, and
+// calculates the function separately for each group.
+func (n *WindowNode) PartitionBy(nodes ...NodeI) *WindowNode {
+	n.partitionBys = append(n.partitionBys, nodes...)
+	return n
+}
+
+// OrderBy sets the order of the rows within each partition. The nodes can be modified using
+// Ascending and Descending calls.
+func (n *WindowNode) OrderBy(nodes ...NodeI) *WindowNode {
+	n.orderBys = append(n.orderBys, nodes...)
+	return n
+}
+
+func (n *WindowNode) nodeType() NodeType {
+	return WindowNodeType
+}
+
+// Equals is used internally by the framework to tell if two nodes are equal
+func (n *WindowNode) Equals(n2 NodeI) bool {
+	if cn, ok := n2.(*WindowNode); ok {
+		if !cn.function.Equals(n.function) {
+			return false
+		}
+		return nodesEqual(cn.partitionBys, n.partitionBys) &&
+			nodesEqual(cn.orderBys, n.orderBys)
+	}
+	return false
+}
+
+func nodesEqual(a []NodeI, b []NodeI) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i, o := range a {
+		if !o.Equals(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n *WindowNode) containedNodes() (nodes []NodeI) {
+	all := append([]NodeI{n.function}, n.partitionBys...)
+	all = append(all, n.orderBys...)
+	for _, o := range all {
+		if nc, ok := o.(nodeContainer); ok {
+			nodes = append(nodes, nc.containedNodes()...)
+		} else {
+			nodes = append(nodes, o)
+		}
+	}
+	return
+}
+
+func (n *WindowNode) tableName() string {
+	return ""
+}
+
+func (n *WindowNode) databaseKey() string {
+	return ""
+}
+
+func (n *WindowNode) log(level int) {
+	tabs := strings.Repeat("\t", level)
+	log.FrameworkDebug(tabs + "Window: ")
+	n.function.log(level + 1)
+}
+
+func (n *WindowNode) GobEncode() (data []byte, err error) {
+	var buf bytes.Buffer
+	e := gob.NewEncoder(&buf)
+
+	if err = e.Encode(n.alias); err != nil {
+		panic(err)
+	}
+	if err = e.Encode(&n.function); err != nil {
+		panic(err)
+	}
+	if err = e.Encode(n.partitionBys); err != nil {
+		panic(err)
+	}
+	if err = e.Encode(n.orderBys); err != nil {
+		panic(err)
+	}
+	data = buf.Bytes()
+	return
+}
+
+func (n *WindowNode) GobDecode(data []byte) (err error) {
+	buf := bytes.NewBuffer(data)
+	dec := gob.NewDecoder(buf)
+	if err = dec.Decode(&n.alias); err != nil {
+		panic(err)
+	}
+	if err = dec.Decode(&n.function); err != nil {
+		panic(err)
+	}
+	if err = dec.Decode(&n.partitionBys); err != nil {
+		panic(err)
+	}
+	if err = dec.Decode(&n.orderBys); err != nil {
+		panic(err)
+	}
+	return
+}
+
+func init() {
+	gob.Register(&WindowNode{})
+}
+
+// WindowNodeFunction is used internally by the framework to get the function calculated over the window.
+func WindowNodeFunction(n *WindowNode) NodeI {
+	return n.function
+}
+
+// WindowNodePartitionBys is used internally by the framework to get the partition nodes.
+func WindowNodePartitionBys(n *WindowNode) []NodeI {
+	return n.partitionBys
+}
+
+// WindowNodeOrderBys is used internally by the framework to get the order by nodes.
+func WindowNodeOrderBys(n *WindowNode) []NodeI {
+	return n.orderBys
+}
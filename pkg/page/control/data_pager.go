@@ -2,9 +2,12 @@ package control
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"path"
 	"strconv"
 
+	"github.com/goradd/goradd/pkg/config"
 	"github.com/goradd/goradd/pkg/math"
 	"github.com/goradd/goradd/pkg/page"
 	"github.com/goradd/goradd/pkg/page/action"
@@ -14,8 +17,15 @@ import (
 
 const (
 	PageClick = iota + 1000
+	VirtualScrollChange
 )
 
+// VirtualScrollEvent is triggered by a control in virtual scrolling mode when the user scrolls outside
+// the window of items that were drawn.
+func VirtualScrollEvent() *event.Event {
+	return event.NewEvent("gr-vscroll")
+}
+
 // PagedControlI is the interface that paged controls must implement
 type PagedControlI interface {
 	DataManagerI
@@ -30,6 +40,11 @@ type PagedControlI interface {
 	HasDataPagers() bool
 	GetDataPagerIDs() []string
 	SliceOffsets() (start, end int)
+	SetVirtualScroll(rowHeight int)
+	IsVirtualScroll() bool
+	ResetPosition()
+	ScrollSpacerHeights(count int) (top, bottom int)
+	SetDataWindow(start, count int)
 }
 
 // PagedControl is a mixin that makes a ControlBase controllable by a data pager. All embedders of a
@@ -40,6 +55,12 @@ type PagedControl struct {
 	pageSize   int
 	pageNum    int
 	dataPagers []string
+	rowHeight  int // the height of an item in pixels when virtual scrolling, or zero when paging
+	firstRow   int // the index of the first item drawn when virtual scrolling
+	scrollTop  int // the scroll position of the scrolling container when virtual scrolling
+	// dataStart and dataCount are the window of items set by SetDataWindow, if dataCount is not zero
+	dataStart int
+	dataCount int
 }
 
 // DefaultPagerPageSize is the default number of items that a paged control will show. You can change this in an individual control, too.
@@ -54,6 +75,7 @@ var DefaultMaxPagerButtons = 10
 func (c *PagedControl) SetTotalItems(count uint) {
 	c.totalItems = int(count)
 	c.limitPageNumber()
+	c.limitFirstRow()
 }
 
 // TotalItems returns the number of items that the paginator is aware of in the list it is managing.
@@ -110,6 +132,15 @@ func (c *PagedControl) limitPageNumber() {
 	}
 }
 
+func (c *PagedControl) limitFirstRow() {
+	if c.firstRow > c.totalItems-c.pageSize {
+		c.firstRow = c.totalItems - c.pageSize
+	}
+	if c.firstRow < 0 {
+		c.firstRow = 0
+	}
+}
+
 // CalcPageCount will return the number of pages based on the page size and total items.
 func (c *PagedControl) CalcPageCount() int {
 	if c.pageSize == 0 || c.totalItems == 0 {
@@ -125,21 +156,174 @@ func (c *PagedControl) HasDataPagers() bool {
 // SliceOffsets returns the start and end values to use to specify a portion of a slice corresponding to the
 // data the pager refers to
 func (c *PagedControl) SliceOffsets() (start, end int) {
-	start = (c.PageNum() - 1) * c.PageSize()
+	if c.dataCount > 0 {
+		start = c.dataStart
+		_, end = math.MinInt(start+c.dataCount, c.TotalItems())
+		return
+	}
+	start = c.windowStart()
 	_, end = math.MinInt(start+c.PageSize(), c.TotalItems())
 	return
 }
 
 // SqlLimits returns the limits you would use in a sql database limit clause
 func (c *PagedControl) SqlLimits() (maxRowCount, offset int) {
-	offset = (c.PageNum() - 1) * c.PageSize()
+	if c.dataCount > 0 {
+		return c.dataCount, c.dataStart
+	}
+	offset = c.windowStart()
 	maxRowCount = c.PageSize()
 	return
 }
 
+// SetDataWindow temporarily makes SliceOffsets and SqlLimits cover count items starting at start, instead of the
+// items that are showing. Exporters use this to load the entire data set through the data binder a part at a time.
+// Pass a count of zero to go back to the items that are showing. It is not saved with the control.
+func (c *PagedControl) SetDataWindow(start, count int) {
+	c.dataStart = start
+	c.dataCount = count
+}
+
+// windowStart returns the index of the first item to draw.
+func (c *PagedControl) windowStart() int {
+	if c.rowHeight > 0 {
+		return c.firstRow
+	}
+	return (c.PageNum() - 1) * c.PageSize()
+}
+
+// SetVirtualScroll turns on virtual scrolling. Instead of drawing a page at a time, the control will draw
+// a window of PageSize items around the items that are scrolled into view, and will fetch and draw a new window
+// through ajax as the user scrolls. Data binders continue to use SliceOffsets or SqlLimits to get the items to draw,
+// and must call SetTotalItems so that the control knows the size of the entire list.
+//
+// rowHeight is the height of each item in pixels. Items that are not drawn are replaced by space of that height,
+// so all items must be the same height. Pass zero to turn virtual scrolling off.
+//
+// The control, or one of its parents, should be a scrolling container, like a div with a fixed height and an
+// overflow-y of auto. Otherwise, the window itself is used.
+// Set the PageSize to at least a few times the number of items that are visible at once.
+func (c *PagedControl) SetVirtualScroll(rowHeight int) {
+	c.rowHeight = rowHeight
+	c.firstRow = 0
+	c.scrollTop = 0
+}
+
+// ResetPosition moves the control back to the first page, or to the top of the list when virtually scrolling.
+// Call it when the data changes so much that the current position means nothing, like when a filter changes.
+func (c *PagedControl) ResetPosition() {
+	c.pageNum = 1
+	c.firstRow = 0
+	c.scrollTop = 0
+}
+
+// IsVirtualScroll returns true if virtual scrolling is turned on.
+func (c *PagedControl) IsVirtualScroll() bool {
+	return c.rowHeight > 0
+}
+
+// FirstRow returns the index of the first item that is drawn in virtual scrolling mode.
+func (c *PagedControl) FirstRow() int {
+	return c.firstRow
+}
+
+// ScrollSpacerHeights returns the height in pixels of the space that stands in for the items before and after
+// the window of count items that are drawn in virtual scrolling mode.
+func (c *PagedControl) ScrollSpacerHeights(count int) (top, bottom int) {
+	top = c.firstRow * c.rowHeight
+	bottom = (c.totalItems - c.firstRow - count) * c.rowHeight
+	if bottom < 0 {
+		bottom = 0
+	}
+	return
+}
+
+// ScrollTo moves the window of drawn items so that it surrounds the items that are visible when the items are
+// scrolled to top pixels, and the scrolling container is height pixels high.
+// It returns true if the window changed and the control should be redrawn.
+func (c *PagedControl) ScrollTo(top, height int) bool {
+	if c.rowHeight <= 0 {
+		return false
+	}
+	if top < 0 {
+		top = 0
+	}
+	first := top / c.rowHeight
+	visible := (height + c.rowHeight - 1) / c.rowHeight
+	start := first - (c.pageSize-visible)/2
+	if start > first {
+		start = first // the window is smaller than the visible area
+	}
+	prev := c.firstRow
+	c.firstRow = start
+	c.limitFirstRow()
+	return c.firstRow != prev
+}
+
+// SetScrollTop records the scroll position of the scrolling container, so that it can be restored when the control is redrawn.
+func (c *PagedControl) SetScrollTop(top int) {
+	c.scrollTop = top
+}
+
+// VirtualScrollAttributes adds the attributes that attach the virtual scrolling javascript widget to a control.
+// Controls that use a PagedControl call this from their DrawingAttributes function.
+func (c *PagedControl) VirtualScrollAttributes(a html5tag.Attributes) {
+	if c.rowHeight <= 0 {
+		return
+	}
+	a.SetData("grWidget", "goradd.VirtualScroll")
+	a.SetData("grOptRowHeight", strconv.Itoa(c.rowHeight))
+	a.SetData("grOptFirstRow", strconv.Itoa(c.firstRow))
+	a.SetData("grOptRowCount", strconv.Itoa(c.pageSize))
+	a.SetData("grOptTotalItems", strconv.Itoa(c.totalItems))
+	if c.scrollTop > 0 {
+		a.SetData("grOptScrollTop", strconv.Itoa(c.scrollTop))
+	}
+}
+
+// virtualScrollValues is the event value of the VirtualScrollEvent.
+type virtualScrollValues struct {
+	Top    int `json:"top"`
+	Height int `json:"height"`
+}
+
+// DoVirtualScrollAction moves the window of drawn items in response to the VirtualScrollChange action
+// that the VirtualScrollEvent sends. It returns true if the control should be redrawn.
+func (c *PagedControl) DoVirtualScrollAction(p action.Params) bool {
+	var v virtualScrollValues
+	if ok, err := p.EventValue(&v); !ok || err != nil {
+		return false
+	}
+	return c.ScrollTo(v.Top, v.Height)
+}
+
+// UpdateVirtualScroll reads the scroll position that the virtual scrolling widget of the control with the given id reports.
+// Controls that use a PagedControl call this from their UpdateFormValues function.
+func (c *PagedControl) UpdateVirtualScroll(ctx context.Context, id string) {
+	if c.rowHeight <= 0 {
+		return
+	}
+	if v := page.GetContext(ctx).CustomControlValue(id, "scrollTop"); v != nil {
+		if top, err := strconv.Atoi(fmt.Sprint(v)); err == nil {
+			c.scrollTop = top
+		}
+	}
+}
+
+// AddVirtualScrollEvent adds the javascript and the event that a control needs to scroll virtually.
+// The control will receive the VirtualScrollChange action in its DoPrivateAction function.
+func AddVirtualScrollEvent(ctrl page.ControlI) {
+	ctrl.ParentForm().AddJavaScriptFile(path.Join(config.AssetPrefix, "goradd", "/js/virtual-scroll.js"), false, nil)
+	ctrl.On(VirtualScrollEvent().Private().Action(action.Do().ID(VirtualScrollChange)))
+}
+
 // MarshalState is an internal function to save the state of the control
 func (c *PagedControl) MarshalState(m page.SavedState) {
 	m.Set("pn", c.pageNum)
+	if c.rowHeight > 0 {
+		m.Set("fr", c.firstRow)
+		m.Set("st", c.scrollTop)
+	}
 }
 
 // UnmarshalState is an internal function to restore the state of the control
@@ -149,6 +333,18 @@ func (c *PagedControl) UnmarshalState(m page.SavedState) {
 			c.pageNum = pn
 		}
 	}
+	if c.rowHeight > 0 {
+		if v, ok := m.Load("fr"); ok {
+			if fr, ok2 := v.(int); ok2 {
+				c.firstRow = fr
+			}
+		}
+		if v, ok := m.Load("st"); ok {
+			if st, ok2 := v.(int); ok2 {
+				c.scrollTop = st
+			}
+		}
+	}
 }
 
 // Serialize encodes the PagedControl data for serialization. Note that all control implementations
@@ -167,6 +363,15 @@ func (c *PagedControl) Serialize(e page.Encoder) {
 	if err := e.Encode(c.dataPagers); err != nil {
 		panic(err)
 	}
+	if err := e.Encode(c.rowHeight); err != nil {
+		panic(err)
+	}
+	if err := e.Encode(c.firstRow); err != nil {
+		panic(err)
+	}
+	if err := e.Encode(c.scrollTop); err != nil {
+		panic(err)
+	}
 
 	return
 }
@@ -187,6 +392,18 @@ func (c *PagedControl) Deserialize(dec page.Decoder) {
 	if err := dec.Decode(&c.dataPagers); err != nil {
 		panic(err)
 	}
+
+	if err := dec.Decode(&c.rowHeight); err != nil {
+		panic(err)
+	}
+
+	if err := dec.Decode(&c.firstRow); err != nil {
+		panic(err)
+	}
+
+	if err := dec.Decode(&c.scrollTop); err != nil {
+		panic(err)
+	}
 }
 
 // DataPagerI is the data pager interface that allows this object to call into subclasses.
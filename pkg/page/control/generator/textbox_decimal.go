@@ -0,0 +1,74 @@
+package generator
+
+import (
+	"fmt"
+	"github.com/goradd/goradd/codegen/generator"
+	"github.com/goradd/goradd/pkg/orm/db"
+	"github.com/goradd/goradd/pkg/orm/query"
+)
+
+func init() {
+	generator.RegisterControlGenerator(DecimalTextbox{}, "github.com/goradd/goradd/pkg/page/control/textbox/DecimalTextbox")
+}
+
+// DecimalTextbox describes the DecimalTextbox to the connector dialog and code generator
+type DecimalTextbox struct {
+}
+
+func (d DecimalTextbox) Imports() []string {
+	return []string{"github.com/shopspring/decimal"}
+}
+
+func (d DecimalTextbox) SupportsColumn(ref interface{}) bool {
+	if col, ok := ref.(*db.Column); ok && col.ColumnType == query.ColTypeDecimal {
+		return true
+	}
+	return false
+}
+
+func (d DecimalTextbox) GenerateCreator(ref interface{}, desc *generator.ControlDescription) (s string) {
+	col := ref.(*db.Column)
+
+	s = fmt.Sprintf(
+		`%s.DecimalTextboxCreator{
+			ID:        p.ID() + "-%s",
+`, desc.Package, desc.ControlID)
+
+	if col.MinValue != nil {
+		sMinVal := fmt.Sprintf("%q", fmt.Sprint(col.MinValue))
+		s += `    // Set this with a "min" value in the column comment. For example: {"min":"1.00"}
+    MinValue: &textbox.DecimalLimit{
+		Value: decimal.RequireFromString(` + sMinVal + `),
+		InvalidMessage: fmt.Sprintf(p.GT("Must be at least %s"),` + sMinVal + `),
+	},
+`
+	}
+	if col.MaxValue != nil {
+		sMaxVal := fmt.Sprintf("%q", fmt.Sprint(col.MaxValue))
+		s += `    // Set this with a "max" value in the column comment. For example: {"max":"10.00"}
+	MaxValue: &textbox.DecimalLimit{
+		Value: decimal.RequireFromString(` + sMaxVal + `),
+		InvalidMessage: fmt.Sprintf(p.GT("Must be at most %s"), ` + sMaxVal + `),
+	},
+`
+	}
+	s += fmt.Sprintf(`
+			ControlOptions: page.ControlOptions{
+				IsRequired:      %#v,
+				DataConnector: %s{},
+			},
+		}`, !col.IsNullable, desc.Connector)
+	return
+}
+
+func (d DecimalTextbox) GenerateRefresh(ref interface{}, desc *generator.ControlDescription) (s string) {
+	return `ctrl.SetValue(val)`
+}
+
+func (d DecimalTextbox) GenerateUpdate(ref interface{}, desc *generator.ControlDescription) (s string) {
+	return `val := ctrl.Decimal()`
+}
+
+func (d DecimalTextbox) GenerateModifies(ref interface{}, desc *generator.ControlDescription) (s string) {
+	return `!val.Equal(ctrl.Decimal())`
+}
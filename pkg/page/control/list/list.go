@@ -153,6 +153,27 @@ func (l *List) reindex(start int) {
 	}
 }
 
+// SortByValues puts the items in the order of the given values. Items whose values are not given are moved to the end.
+// The ids of the items change to reflect their new order.
+func (l *List) SortByValues(values []string) {
+	positions := make(map[string]int, len(values))
+	for i, v := range values {
+		if _, ok := positions[v]; !ok {
+			positions[v] = i
+		}
+	}
+	position := func(item *Item) int {
+		if p, ok := positions[item.Value()]; ok {
+			return p
+		}
+		return len(values)
+	}
+	sort.SliceStable(l.items, func(i, j int) bool {
+		return position(l.items[i]) < position(l.items[j])
+	})
+	l.reindex(0)
+}
+
 // ItemAt retrieves an item by index.
 func (l *List) ItemAt(index int) *Item {
 	if index >= len(l.items) {
@@ -73,6 +73,10 @@ func (l *OrderedList) NumberType() string {
 }
 
 func (l *OrderedList) DrawInnerHtml(_ context.Context, w io.Writer) {
+	l.ResetDrawnItems()
+	for _, item := range l.items {
+		l.AddDrawnItem(item.Value())
+	}
 	h := l.getItemsHtml(l.items)
 	page.WriteString(w, h)
 	return
@@ -84,7 +88,7 @@ func (l *OrderedList) getItemsHtml(items []*Item) string {
 	for _, item := range items {
 		if item.HasChildItems() {
 			innerhtml := l.getItemsHtml(item.Items())
-			a := item.Attributes().Copy()
+			a := l.itemAttributes(item).Copy()
 
 			// Certain attributes apply to the sub list and not the list item, so we split them here
 			a2 := html5tag.NewAttributes()
@@ -101,7 +105,7 @@ func (l *OrderedList) getItemsHtml(items []*Item) string {
 			innerhtml = html5tag.RenderTag(l.Tag, a2, innerhtml)
 			h += html5tag.RenderTag(l.itemTag, a, item.Label()+" "+innerhtml)
 		} else {
-			h += html5tag.RenderTag(l.itemTag, item.Attributes(), html.EscapeString(item.Label()))
+			h += html5tag.RenderTag(l.itemTag, l.itemAttributes(item), html.EscapeString(item.Label()))
 		}
 	}
 	return h
@@ -119,6 +123,8 @@ type OrderedListCreator struct {
 	NumberType string
 	// StartAt sets the number to start counting from. The default is 1.
 	StartAt int
+	// Reorderable lets the user reorder the top level items. See UnorderedList.SetReorderable.
+	Reorderable bool
 	page.ControlOptions
 }
 
@@ -148,6 +154,9 @@ func (c OrderedListCreator) Init(ctx context.Context, ctrl OrderedListI) {
 	if c.StartAt != 0 {
 		ctrl.SetStart(c.StartAt)
 	}
+	if c.Reorderable {
+		ctrl.SetReorderable(true)
+	}
 	ctrl.ApplyOptions(ctx, c.ControlOptions)
 }
 
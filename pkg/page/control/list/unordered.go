@@ -14,6 +14,7 @@ type UnorderedListI interface {
 	page.ControlI
 	ListI
 	control2.DataManagerI
+	control2.ReorderableI
 	GetItemsHtml(items []*Item) string
 	SetBulletStyle(s string) UnorderedListI
 	SetItemTag(s string) UnorderedListI
@@ -23,10 +24,13 @@ type UnorderedListI interface {
 // UnorderedList is a dynamically generated html unordered list (ul). Such lists are often used as the basis for
 // javascript and css widgets. If you use a data provider to set the data, you should call AddItems to the list
 // in your LoadData function.
+//
+// Call SetReorderable to let the user reorder the top level items of the list.
 type UnorderedList struct {
 	page.ControlBase
 	List
 	control2.DataManager
+	control2.Reorderable
 	itemTag string
 }
 
@@ -90,11 +94,46 @@ func (l *UnorderedList) DrawTag(ctx context.Context, w io.Writer) {
 func (l *UnorderedList) DrawingAttributes(ctx context.Context) html5tag.Attributes {
 	a := l.ControlBase.DrawingAttributes(ctx)
 	a.SetData("grctl", "hlist")
+	l.Reorderable.ReorderableAttributes(a, ":scope > "+l.itemTag)
+	return a
+}
+
+// SetReorderable lets the user reorder the top level items of the list. The items are identified by their values,
+// and the list puts its items in the new order when the user moves one. See control.Reorderable.
+func (l *UnorderedList) SetReorderable(reorderable bool) {
+	if reorderable && !l.IsReorderable() {
+		control2.AddReorderableScript(l)
+	}
+	l.Reorderable.SetReorderable(reorderable)
+	l.Refresh()
+}
+
+// UpdateFormValues is used by the framework to cause the control to retrieve its values from the form.
+func (l *UnorderedList) UpdateFormValues(ctx context.Context) {
+	if l.Reorderable.UpdateItemOrder(ctx, l.ID()) {
+		l.SortByValues(l.ItemOrder())
+		// The ids of the items changed, so redraw them
+		l.Refresh()
+	}
+}
+
+// itemAttributes returns the attributes of the tag of an item. If the list is reorderable, the value of the item
+// is added as its data-id.
+func (l *UnorderedList) itemAttributes(item *Item) html5tag.Attributes {
+	a := item.Attributes()
+	if l.IsReorderable() {
+		a = a.Copy()
+		a.SetData("id", item.Value())
+	}
 	return a
 }
 
 // DrawInnerHtml is called by the framework to draw the content of the tag.
 func (l *UnorderedList) DrawInnerHtml(_ context.Context, w io.Writer) {
+	l.ResetDrawnItems()
+	for _, item := range l.items {
+		l.AddDrawnItem(item.Value())
+	}
 	h := l.this().GetItemsHtml(l.items)
 	page.WriteString(w, h)
 	return
@@ -109,9 +148,9 @@ func (l *UnorderedList) GetItemsHtml(items []*Item) string {
 		if item.HasChildItems() {
 			innerhtml := l.this().GetItemsHtml(item.Items())
 			innerhtml = html5tag.RenderTag(l.Tag, nil, innerhtml)
-			h += html5tag.RenderTag(l.itemTag, item.Attributes(), item.Label()+" "+innerhtml)
+			h += html5tag.RenderTag(l.itemTag, l.itemAttributes(item), item.Label()+" "+innerhtml)
 		} else {
-			h += html5tag.RenderTag(l.itemTag, item.Attributes(), item.RenderLabel())
+			h += html5tag.RenderTag(l.itemTag, l.itemAttributes(item), item.RenderLabel())
 		}
 	}
 	return h
@@ -136,6 +175,7 @@ func (l *UnorderedList) Serialize(e page.Encoder) {
 	l.ControlBase.Serialize(e)
 	l.List.Serialize(e)
 	l.DataManager.Serialize(e)
+	l.Reorderable.Serialize(e)
 	if err := e.Encode(l.itemTag); err != nil {
 		panic(err)
 	}
@@ -145,6 +185,7 @@ func (l *UnorderedList) Deserialize(dec page.Decoder) {
 	l.ControlBase.Deserialize(dec)
 	l.List.Deserialize(dec)
 	l.DataManager.Deserialize(dec)
+	l.Reorderable.Deserialize(dec)
 	if err := dec.Decode(&l.itemTag); err != nil {
 		panic(err)
 	}
@@ -162,6 +203,8 @@ type UnorderedListCreator struct {
 	DataProviderID string
 	// BulletStyle is the list-style-type property.
 	BulletStyle string
+	// Reorderable lets the user reorder the top level items. See UnorderedList.SetReorderable.
+	Reorderable bool
 	page.ControlOptions
 }
 
@@ -186,6 +229,9 @@ func (c UnorderedListCreator) Init(ctx context.Context, ctrl UnorderedListI) {
 	if c.BulletStyle != "" {
 		ctrl.SetBulletStyle(c.BulletStyle)
 	}
+	if c.Reorderable {
+		ctrl.SetReorderable(true)
+	}
 	ctrl.ApplyOptions(ctx, c.ControlOptions)
 }
 
@@ -0,0 +1,85 @@
+package list
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/gob"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/goradd/goradd/pkg/page"
+	"github.com/goradd/goradd/pkg/session"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnorderedList_Reorderable(t *testing.T) {
+	p := page.NewMockForm()
+
+	l := NewUnorderedList(p, "list")
+	l.Add("A", "a")
+	l.Add("B", "b")
+	l.Add("C", "c")
+
+	assert.NotContains(t, l.GetItemsHtml(l.Items()), "data-id")
+
+	l.SetReorderable(true)
+	assert.True(t, l.IsReorderable())
+	assert.Contains(t, l.GetItemsHtml(l.Items()), `data-id="a"`)
+	assert.Equal(t, "goradd.Reorderable", l.DrawingAttributes(page.NewMockContext()).DataAttribute("grWidget"))
+
+	// Values that are not given go to the end
+	l.SortByValues([]string{"c", "a"})
+	assert.Equal(t, "c", l.ItemAt(0).Value())
+	assert.Equal(t, "a", l.ItemAt(1).Value())
+	assert.Equal(t, "b", l.ItemAt(2).Value())
+	assert.Equal(t, "list_0", l.ItemAt(0).ID())
+	assert.Equal(t, "c", l.GetItemByID("list_0").Value())
+
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+	l.Serialize(enc)
+	l2 := UnorderedList{}
+	dec := gob.NewDecoder(&buf)
+	l2.Deserialize(dec)
+	assert.True(t, l2.IsReorderable())
+}
+
+// orderContext returns a context in which the reordering widget of the list reports the given order.
+func orderContext(order string) context.Context {
+	params := base64.StdEncoding.EncodeToString([]byte(`{"controlValues":{"list":{"order":` + order + `}}}`))
+	form := url.Values{page.HtmlVarPagestate: {"1"}, "Goradd__Params": {params}}
+	r := httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+	r.Header.Set("content-type", "application/x-www-form-urlencoded")
+	s := session.NewMock()
+	session.SetSessionManager(s)
+	r = r.WithContext(s.With(r.Context()))
+	return page.PutContext(r, nil).Context()
+}
+
+func TestUnorderedList_UpdateItemOrder(t *testing.T) {
+	p := page.NewMockForm()
+
+	l := NewUnorderedList(p, "list")
+	l.SetReorderable(true)
+	l.Add("A", "a")
+	l.Add("B", "b")
+	l.Add("C", "c")
+	var buf bytes.Buffer
+	l.DrawInnerHtml(page.NewMockContext(), &buf)
+
+	// Orders that are not a rearrangement of the drawn items are ignored
+	for _, order := range []string{`["c","a"]`, `["c","a","b","d"]`, `["c","a","a"]`, `["c","a","d"]`} {
+		l.UpdateFormValues(orderContext(order))
+		assert.Nil(t, l.ItemOrder(), order)
+		assert.Equal(t, "a", l.ItemAt(0).Value(), order)
+	}
+
+	l.UpdateFormValues(orderContext(`["c","a","b"]`))
+	assert.Equal(t, []string{"c", "a", "b"}, l.ItemOrder())
+	assert.Equal(t, "c", l.ItemAt(0).Value())
+	assert.Equal(t, "b", l.ItemAt(2).Value())
+}
@@ -0,0 +1,174 @@
+package control
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"path"
+	"regexp"
+
+	"github.com/goradd/goradd/pkg/config"
+	"github.com/goradd/goradd/pkg/page"
+	"github.com/goradd/goradd/pkg/page/event"
+	"github.com/goradd/html5tag"
+)
+
+// ReorderEvent is triggered by a reorderable control when the user moves one of its items.
+// The event value is a slice of the ids of the items in their new order. You can also call the ItemOrder
+// function of the control in your action handler to get the ids.
+func ReorderEvent() *event.Event {
+	return event.NewEvent("gr-reorder")
+}
+
+// ReorderableI is the interface of controls whose items the user can reorder.
+type ReorderableI interface {
+	SetReorderable(bool)
+	IsReorderable() bool
+	ItemOrder() []string
+}
+
+// Reorderable is a mixin for controls that lets the user reorder their items by dragging them, or by focusing an item
+// and pressing Alt+Up or Alt+Down to move it one place.
+//
+// Each item is identified by its data-id attribute, or by its id attribute if it has no data-id. When the user
+// moves an item, the new order of the ids is reported to the control, where ItemOrder returns it, and the
+// control fires the ReorderEvent. An order that is not made of exactly the items that were drawn is ignored. The new order is not saved anywhere else, so respond to the event by saving
+// the order of your data, or the items will go back to their old order the next time the control loads its data.
+//
+// Reordering uses the javascript widget of the control, so it cannot be combined with other features that
+// use a widget, like virtual scrolling or a SelectTable.
+type Reorderable struct {
+	reorderable bool
+	itemOrder   []string
+	drawnItems  []string // the ids of the items that were last drawn
+}
+
+// SetReorderable turns reordering on or off. Controls that use a Reorderable override this to add the javascript.
+func (r *Reorderable) SetReorderable(reorderable bool) {
+	r.reorderable = reorderable
+}
+
+// IsReorderable returns true if the user can reorder the items.
+func (r *Reorderable) IsReorderable() bool {
+	return r.reorderable
+}
+
+// ItemOrder returns the ids of the items in the order the user last put them in, or nil if the user has not moved any items.
+func (r *Reorderable) ItemOrder() []string {
+	return r.itemOrder
+}
+
+// ReorderableAttributes sets the attributes that attach the reordering widget to the control if reordering is on.
+// itemSelector is a css selector, relative to the control, that selects the items that can be moved.
+func (r *Reorderable) ReorderableAttributes(a html5tag.Attributes, itemSelector string) {
+	if !r.reorderable {
+		return
+	}
+	a.SetData("grWidget", "goradd.Reorderable")
+	a.SetData("grOptItems", itemSelector)
+}
+
+// ResetDrawnItems forgets the items that were drawn. Controls that use a Reorderable call this before drawing their
+// items, and call AddDrawnItem for each item they draw, so that UpdateItemOrder only accepts an order of those items.
+func (r *Reorderable) ResetDrawnItems() {
+	r.drawnItems = nil
+}
+
+// AddDrawnItem records the id of an item that was drawn. See ResetDrawnItems.
+func (r *Reorderable) AddDrawnItem(id string) {
+	if r.reorderable {
+		r.drawnItems = append(r.drawnItems, id)
+	}
+}
+
+var itemTagRegexp = regexp.MustCompile(`^\s*<[^>]*>`)
+var itemIDRegexp = regexp.MustCompile(`\s(data-id|id)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+
+// itemID returns the id that the reordering widget gives to the item drawn in h, which is the data-id attribute of its
+// tag, or its id attribute if it has no data-id.
+func itemID(h string) string {
+	tag := itemTagRegexp.FindString(h)
+	var id string
+	for _, m := range itemIDRegexp.FindAllStringSubmatch(tag, -1) {
+		v := html.UnescapeString(m[2] + m[3])
+		if m[1] == "data-id" {
+			return v
+		} else if id == "" {
+			id = v
+		}
+	}
+	return id
+}
+
+// UpdateItemOrder reads the order of the items that the reordering widget of the control with the given id reports.
+// It returns true if a new order was reported. An order that is not a rearrangement of the items that were drawn
+// is ignored. Controls that use a Reorderable call this from their UpdateFormValues function.
+func (r *Reorderable) UpdateItemOrder(ctx context.Context, id string) bool {
+	if !r.reorderable {
+		return false
+	}
+	v := page.GetContext(ctx).CustomControlValue(id, "order")
+	if v == nil {
+		return false
+	}
+	ids, ok := v.([]interface{})
+	if !ok {
+		return false
+	}
+	order := make([]string, len(ids))
+	for i, id := range ids {
+		order[i] = fmt.Sprint(id)
+	}
+	if !r.isDrawnOrder(order) {
+		return false
+	}
+	r.itemOrder = order
+	return true
+}
+
+// isDrawnOrder returns true if order has each of the items that were drawn exactly once.
+func (r *Reorderable) isDrawnOrder(order []string) bool {
+	if len(order) != len(r.drawnItems) {
+		return false
+	}
+	counts := make(map[string]int, len(r.drawnItems))
+	for _, id := range r.drawnItems {
+		counts[id]++
+	}
+	for _, id := range order {
+		if counts[id] == 0 {
+			return false
+		}
+		counts[id]--
+	}
+	return true
+}
+
+// AddReorderableScript adds the javascript that a control needs to reorder its items.
+func AddReorderableScript(ctrl page.ControlI) {
+	ctrl.ParentForm().AddJavaScriptFile(path.Join(config.AssetPrefix, "goradd", "/js/reorderable.js"), false, nil)
+}
+
+func (r *Reorderable) Serialize(e page.Encoder) {
+	if err := e.Encode(r.reorderable); err != nil {
+		panic(err)
+	}
+	if err := e.Encode(r.itemOrder); err != nil {
+		panic(err)
+	}
+	if err := e.Encode(r.drawnItems); err != nil {
+		panic(err)
+	}
+}
+
+func (r *Reorderable) Deserialize(dec page.Decoder) {
+	if err := dec.Decode(&r.reorderable); err != nil {
+		panic(err)
+	}
+	if err := dec.Decode(&r.itemOrder); err != nil {
+		panic(err)
+	}
+	if err := dec.Decode(&r.drawnItems); err != nil {
+		panic(err)
+	}
+}
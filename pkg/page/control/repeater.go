@@ -2,15 +2,19 @@ package control
 
 import (
 	"context"
+	"fmt"
 	"io"
 
 	"github.com/goradd/goradd/pkg/log"
 	"github.com/goradd/goradd/pkg/page"
+	"github.com/goradd/goradd/pkg/page/action"
+	"github.com/goradd/goradd/pkg/pool"
 	"github.com/goradd/html5tag"
 )
 
 type RepeaterI interface {
 	PagedControlI
+	ReorderableI
 	DrawItem(ctx context.Context, i int, data interface{}, w io.Writer)
 	SetItemHtmler(h RepeaterHtmler) RepeaterI
 }
@@ -23,10 +27,14 @@ type RepeaterI interface {
 //
 // Like a table, the child items can be based on content taken from a DataBinder. You can also limit
 // the amount of data displayed at one time by calling DataPager() and assigning a pager control.
+//
+// Call SetReorderable to let the user reorder the items. The item htmler should then give each item
+// a data-id attribute that identifies it. See Reorderable.
 type Repeater struct {
 	page.ControlBase
 	PagedControl
 	DataManager
+	Reorderable
 	itemHtmler   RepeaterHtmler
 	itemHtmlerId string // only used for serialization
 }
@@ -79,6 +87,8 @@ func (r *Repeater) DrawTag(ctx context.Context, w io.Writer) {
 func (r *Repeater) DrawingAttributes(ctx context.Context) html5tag.Attributes {
 	a := r.ControlBase.DrawingAttributes(ctx)
 	a.SetData("grctl", "repeater")
+	r.PagedControl.VirtualScrollAttributes(a)
+	r.Reorderable.ReorderableAttributes(a, ":scope > *")
 	return a
 }
 
@@ -86,11 +96,53 @@ func (r *Repeater) DrawingAttributes(ctx context.Context) html5tag.Attributes {
 func (r *Repeater) DrawInnerHtml(ctx context.Context, w io.Writer) {
 	var this = r.this() // Get the sub class so we call into its hooks for drawing
 
+	r.ResetDrawnItems()
+	if !r.IsVirtualScroll() {
+		r.RangeData(func(index int, value interface{}) bool {
+			r.drawItem(ctx, this, index, value, w)
+			return true
+		})
+		return
+	}
+
+	// Stand in for the items that are not drawn so that the scroll bar reflects the entire list
+	buf := pool.GetBuffer()
+	defer pool.PutBuffer(buf)
+	var count int
 	r.RangeData(func(index int, value interface{}) bool {
-		this.DrawItem(ctx, index, value, w)
+		r.drawItem(ctx, this, index, value, buf)
+		count++
 		return true
 	})
-	return
+	top, bottom := r.ScrollSpacerHeights(count)
+	r.drawSpacer(top, w)
+	page.WriteString(w, buf.String())
+	r.drawSpacer(bottom, w)
+}
+
+// drawItem draws an item, and records its id if the repeater is reorderable.
+func (r *Repeater) drawItem(ctx context.Context, this RepeaterI, i int, data interface{}, w io.Writer) {
+	if !r.IsReorderable() {
+		this.DrawItem(ctx, i, data, w)
+		return
+	}
+	buf := pool.GetBuffer()
+	defer pool.PutBuffer(buf)
+	this.DrawItem(ctx, i, data, buf)
+	r.AddDrawnItem(itemID(buf.String()))
+	page.WriteString(w, buf.String())
+}
+
+// drawSpacer draws an empty item of the given height in pixels.
+func (r *Repeater) drawSpacer(height int, w io.Writer) {
+	if height <= 0 {
+		return
+	}
+	tag := "div"
+	if r.Tag == "ul" || r.Tag == "ol" {
+		tag = "li"
+	}
+	page.WriteString(w, fmt.Sprintf(`<%s class="gr-spacer" aria-hidden="true" style="height:%dpx"></%[1]s>`, tag, height))
 }
 
 func (r *Repeater) DrawItem(ctx context.Context, i int, data interface{}, w io.Writer) {
@@ -100,6 +152,52 @@ func (r *Repeater) DrawItem(ctx context.Context, i int, data interface{}, w io.W
 	return
 }
 
+// SetVirtualScroll turns on virtual scrolling, which draws only the items that are scrolled into view.
+// See PagedControl.SetVirtualScroll.
+func (r *Repeater) SetVirtualScroll(rowHeight int) {
+	if rowHeight > 0 && !r.IsVirtualScroll() {
+		AddVirtualScrollEvent(r)
+	}
+	r.PagedControl.SetVirtualScroll(rowHeight)
+	r.Refresh()
+}
+
+// SetReorderable lets the user reorder the items of the repeater. See Reorderable.
+func (r *Repeater) SetReorderable(reorderable bool) {
+	if reorderable && !r.IsReorderable() {
+		AddReorderableScript(r)
+	}
+	r.Reorderable.SetReorderable(reorderable)
+	r.Refresh()
+}
+
+// ResetPosition moves the repeater back to the first page, or scrolls it back to the top when virtually scrolling.
+func (r *Repeater) ResetPosition() {
+	r.PagedControl.ResetPosition()
+	if r.IsVirtualScroll() {
+		r.Refresh()
+		r.ExecuteWidgetFunction("scrollToStart", page.PriorityLow)
+	}
+}
+
+// UpdateFormValues is used by the framework to cause the control to retrieve its values from the form
+func (r *Repeater) UpdateFormValues(ctx context.Context) {
+	r.PagedControl.UpdateVirtualScroll(ctx, r.ID())
+	r.Reorderable.UpdateItemOrder(ctx, r.ID())
+}
+
+// DoPrivateAction is called by the framework to redraw the repeater when it is virtually scrolled.
+func (r *Repeater) DoPrivateAction(ctx context.Context, p action.Params) {
+	switch p.ID {
+	case VirtualScrollChange:
+		if r.DoVirtualScrollAction(p) {
+			r.Refresh()
+		}
+	default:
+		r.ControlBase.DoPrivateAction(ctx, p)
+	}
+}
+
 // MarshalState is an internal function to save the state of the control
 func (r *Repeater) MarshalState(m page.SavedState) {
 	r.PagedControl.MarshalState(m)
@@ -114,6 +212,7 @@ func (r *Repeater) Serialize(e page.Encoder) {
 	r.ControlBase.Serialize(e)
 	r.PagedControl.Serialize(e)
 	r.DataManager.Serialize(e)
+	r.Reorderable.Serialize(e)
 
 	// If itemHtmler is a control, we will just serialize the control's id, since the control will get
 	// serialized elsewhere. Otherwise, we serialize the itemHtmler itself.
@@ -130,6 +229,7 @@ func (r *Repeater) Deserialize(dec page.Decoder) {
 	r.ControlBase.Deserialize(dec)
 	r.PagedControl.Deserialize(dec)
 	r.DataManager.Deserialize(dec)
+	r.Reorderable.Deserialize(dec)
 
 	var htmler interface{}
 	if err := dec.Decode(&htmler); err != nil {
@@ -167,6 +267,11 @@ type RepeaterCreator struct {
 	PageSize int
 	// SaveState will cause the table to remember what page it was on
 	SaveState bool
+	// VirtualScrollRowHeight turns on virtual scrolling, and is the height of each item in pixels.
+	// See PagedControl.SetVirtualScroll.
+	VirtualScrollRowHeight int
+	// Reorderable lets the user reorder the items. See Reorderable.
+	Reorderable bool
 }
 
 // Create is called by the framework to create a new control from the Creator. You
@@ -195,6 +300,12 @@ func (c RepeaterCreator) Init(ctx context.Context, ctrl RepeaterI) {
 	if c.PageSize != 0 {
 		ctrl.SetPageSize(c.PageSize)
 	}
+	if c.VirtualScrollRowHeight != 0 {
+		ctrl.SetVirtualScroll(c.VirtualScrollRowHeight)
+	}
+	if c.Reorderable {
+		ctrl.SetReorderable(true)
+	}
 	if c.SaveState {
 		ctrl.SaveState(ctx, true)
 	}
@@ -0,0 +1,357 @@
+package column
+
+import (
+	"context"
+	"fmt"
+	"html"
+
+	"github.com/goradd/goradd/pkg/javascript"
+	"github.com/goradd/goradd/pkg/page"
+	"github.com/goradd/goradd/pkg/page/action"
+	"github.com/goradd/goradd/pkg/page/control/button"
+	"github.com/goradd/goradd/pkg/page/control/list"
+	"github.com/goradd/goradd/pkg/page/control/table"
+	"github.com/goradd/goradd/pkg/page/control/textbox"
+	"github.com/goradd/goradd/pkg/page/event"
+	"github.com/goradd/goradd/pkg/pool"
+	"github.com/goradd/html5tag"
+)
+
+const (
+	editStartAction = iota + 1100
+	editCommitAction
+	editCancelAction
+)
+
+// EditType is the kind of control that edits the cells of an EditColumn.
+type EditType int
+
+const (
+	// EditText edits cells with a textbox. Enter saves the change.
+	EditText EditType = iota
+	// EditSelect edits cells with a select list. Choosing an item saves the change.
+	EditSelect
+	// EditCheckbox edits cells with a checkbox. Clicking it saves the change.
+	EditCheckbox
+)
+
+// CellEditProvider connects the cells of an EditColumn to the data of the table.
+// If your CellEditProvider is not a control, you should register it with gob.
+type CellEditProvider interface {
+	// RowID returns a unique id for the given data item, like the primary key of a database record.
+	// It is given to SetCellValue so that you can find the data to change.
+	RowID(data interface{}) string
+	// CellValue returns the value of the cell for the given data item. It is shown in the cell, and
+	// is the starting value of the editor.
+	CellValue(ctx context.Context, data interface{}) interface{}
+	// SetCellValue is called when the user saves a change to a cell of the column with the id columnID.
+	// rowID is the id of one of the rows that the table last drew, and value is the new value, which is a string
+	// for text and select editors, and a bool for checkbox editors. Check that the user may make the change,
+	// and record it. The table redraws afterwards.
+	SetCellValue(ctx context.Context, columnID string, rowID string, value interface{})
+}
+
+// EditColumn is a table column whose cells the user can edit in place.
+//
+// Clicking a cell replaces its text with a textbox, select list or checkbox. Enter (or a change in a select list or
+// checkbox) saves the change, and Escape cancels it. Saving calls the SetCellValue function of the CellEditProvider
+// with the id of the row, the id of the column and the new value, and the table redraws with the new data.
+// The row being edited and the new value are kept on the server, so the browser cannot change which row is saved.
+//
+// Only one cell of the column is edited at a time.
+type EditColumn struct {
+	table.ColumnBase
+	editType   EditType
+	provider   CellEditProvider
+	providerID string // for deserializing
+	editor     page.ControlI
+	editorID   string          // for deserializing
+	items      []interface{}   // items of the select list, until the editor is created
+	editRow    string          // the row id of the cell being edited
+	loadEditor bool            // true when the editor needs the value of the cell
+	rowIDs     map[string]bool // the ids of the rows that were last drawn, which are the only rows that can be edited
+}
+
+// NewEditColumn creates a new table column whose cells can be edited with the given type of control.
+// You must provide a CellEditProvider to connect the cells to the data.
+func NewEditColumn(p CellEditProvider, editType EditType) *EditColumn {
+	if p == nil {
+		panic("a cell edit provider is required")
+	}
+	i := EditColumn{provider: p, editType: editType}
+	i.Init()
+	return &i
+}
+
+func (c *EditColumn) Init() {
+	c.ColumnBase.Init(c)
+	c.SetIsHtml(true)
+}
+
+// Editor returns the control that edits the cells. Use it to add items to the select list of an EditSelect column,
+// or to style the control. It is nil until the column is added to a table.
+func (c *EditColumn) Editor() page.ControlI {
+	return c.editor
+}
+
+// EditRowID returns the row id of the cell being edited, or an empty string if no cell is being edited.
+func (c *EditColumn) EditRowID() string {
+	return c.editRow
+}
+
+// CellData returns the value of the cell from the CellEditProvider. The label of the item is returned for select lists.
+func (c *EditColumn) CellData(ctx context.Context, _ int, _ int, data interface{}) interface{} {
+	v := c.provider.CellValue(ctx, data)
+	if l, ok := c.editor.(*list.SelectList); ok {
+		if _, item := l.GetItemByValue(fmt.Sprint(v)); item != nil {
+			return item.Label()
+		}
+	}
+	return v
+}
+
+// CellText returns the html of the cell, which is the editor control for the cell being edited.
+func (c *EditColumn) CellText(ctx context.Context, row int, col int, data interface{}) string {
+	if c.editRow == "" || c.provider.RowID(data) != c.editRow {
+		return html.EscapeString(c.ColumnBase.CellText(ctx, row, col, data))
+	}
+
+	if c.loadEditor {
+		c.setEditorValue(c.provider.CellValue(ctx, data))
+		c.loadEditor = false
+		c.ParentTable().ParentForm().Response().ExecuteControlCommand(c.editor.ID(), "focus", page.PriorityLow)
+	}
+	buf := pool.GetBuffer()
+	defer pool.PutBuffer(buf)
+	c.editor.Draw(ctx, buf)
+	return buf.String()
+}
+
+// CellAttributes returns the attributes of the cell. Cells that are not being edited are marked so that a click
+// on them starts editing.
+func (c *EditColumn) CellAttributes(ctx context.Context, row int, col int, data interface{}) html5tag.Attributes {
+	a := c.ColumnBase.CellAttributes(ctx, row, col, data)
+	if a == nil {
+		a = html5tag.NewAttributes()
+	}
+	id := c.provider.RowID(data)
+	if id == "" {
+		panic("a row id is required")
+	}
+	if c.rowIDs == nil {
+		c.rowIDs = make(map[string]bool)
+	}
+	c.rowIDs[id] = true
+	if id == c.editRow {
+		a.AddClass("gr-editing")
+	} else {
+		a.SetData("grEdit", c.ID())
+		a.SetData("grEditRow", id)
+	}
+	return a
+}
+
+func (c *EditColumn) setEditorValue(v interface{}) {
+	switch e := c.editor.(type) {
+	case *textbox.Textbox:
+		if v == nil {
+			v = ""
+		}
+		e.SetValue(v)
+	case *list.SelectList:
+		e.SetValue(fmt.Sprint(v))
+	case *button.Checkbox:
+		e.SetChecked(page.ConvertToBool(v))
+	}
+}
+
+func (c *EditColumn) editorValue() interface{} {
+	switch e := c.editor.(type) {
+	case *textbox.Textbox:
+		return e.Text()
+	case *list.SelectList:
+		return e.StringValue()
+	case *button.Checkbox:
+		return e.Checked()
+	}
+	return nil
+}
+
+// PreRender is called by the table before it draws, and starts collecting the ids of the rows drawn.
+func (c *EditColumn) PreRender() {
+	c.ColumnBase.PreRender()
+	c.rowIDs = make(map[string]bool)
+}
+
+// AddActions creates the editor control in the table, and adds the actions that start and end editing.
+func (c *EditColumn) AddActions(t page.ControlI) {
+	// The id cannot have an underscore, since that would look like the id of a column to the action.
+	id := t.ID() + "-" + c.ID() + "-edit"
+	switch c.editType {
+	case EditSelect:
+		l := list.NewSelectList(t, id)
+		l.AddItems(c.items...)
+		c.items = nil
+		c.editor = l
+	case EditCheckbox:
+		c.editor = button.NewCheckbox(t, id)
+	default:
+		c.editor = textbox.NewTextbox(t, id)
+	}
+
+	dest := t.ID() + "_" + c.ID()
+	t.On(event.CellClick().
+		Selector(`td[data-gr-edit="` + c.ID() + `"]`).
+		EventValue(javascript.JsCode(`g$(event.goradd.match).data("grEditRow")`)).
+		Private().
+		Action(action.Do().ControlID(dest).ID(table.ColumnAction).ActionValue(editStartAction)))
+
+	commit := action.Do().ControlID(dest).ID(table.ColumnAction).ActionValue(editCommitAction)
+	if c.editType == EditText {
+		c.editor.On(event.EnterKey().Terminating().Private().Action(commit))
+	} else {
+		c.editor.On(event.Change().Private().Action(commit))
+	}
+	c.editor.On(event.EscapeKey().Terminating().Private().
+		Action(action.Do().ControlID(dest).ID(table.ColumnAction).ActionValue(editCancelAction)))
+}
+
+// DoAction is called by the framework to respond to the clicks and keys that start and end editing.
+func (c *EditColumn) DoAction(ctx context.Context, params action.Params) {
+	t := c.ParentTable()
+	switch params.ActionValueInt() {
+	case editStartAction:
+		id := params.EventValueString()
+		if !c.rowIDs[id] {
+			return // not a row that the table drew, so the browser sent an id that it was not given
+		}
+		c.editRow = id
+		c.loadEditor = true
+		t.Refresh()
+	case editCommitAction:
+		if c.editRow == "" {
+			return
+		}
+		row := c.editRow
+		c.editRow = ""
+		c.provider.SetCellValue(ctx, c.ID(), row, c.editorValue())
+		t.Refresh()
+	case editCancelAction:
+		c.editRow = ""
+		t.Refresh()
+	}
+}
+
+type editColumnEncoded struct {
+	EditType   EditType
+	Provider   interface{}
+	EditorID   string
+	EditRow    string
+	LoadEditor bool
+	RowIDs     map[string]bool
+}
+
+func (c *EditColumn) Serialize(e page.Encoder) {
+	c.ColumnBase.Serialize(e)
+
+	s := editColumnEncoded{
+		EditType:   c.editType,
+		Provider:   c.provider,
+		EditRow:    c.editRow,
+		LoadEditor: c.loadEditor,
+		RowIDs:     c.rowIDs,
+	}
+	if ctrl, ok := c.provider.(page.ControlI); ok {
+		s.Provider = ctrl.ID()
+	}
+	if c.editor != nil {
+		s.EditorID = c.editor.ID()
+	}
+	if err := e.Encode(s); err != nil {
+		panic(err)
+	}
+}
+
+func (c *EditColumn) Deserialize(dec page.Decoder) {
+	c.ColumnBase.Deserialize(dec)
+
+	s := editColumnEncoded{}
+	if err := dec.Decode(&s); err != nil {
+		panic(err)
+	}
+	c.editType = s.EditType
+	if p, ok := s.Provider.(CellEditProvider); ok {
+		c.provider = p
+	}
+	if id, ok := s.Provider.(string); ok {
+		c.providerID = id
+	}
+	c.editorID = s.EditorID
+	c.editRow = s.EditRow
+	c.loadEditor = s.LoadEditor
+	c.rowIDs = s.RowIDs
+}
+
+// Restore is called by the framework after the table is deserialized to reconnect the column to its controls.
+func (c *EditColumn) Restore(parentTable table.TableI) {
+	c.ColumnBase.Restore(parentTable)
+	if c.editorID != "" {
+		c.editor = parentTable.Page().GetControl(c.editorID)
+	}
+	if c.providerID != "" {
+		c.provider = parentTable.Page().GetControl(c.providerID).(CellEditProvider)
+	}
+}
+
+func init() {
+	table.RegisterColumn(EditColumn{})
+}
+
+// EditColumnCreator creates a column whose cells can be edited in place.
+type EditColumnCreator struct {
+	// ID will assign the given id to the column. If you do not specify it, an id will be given it by the framework.
+	ID string
+	// Title is the title of the column that appears in the header
+	Title string
+	// CellEditProvider connects the cells to the data. If it is a string, it is the id of a control
+	// that implements the CellEditProvider interface.
+	CellEditProvider interface{}
+	// EditType is the kind of control that edits the cells.
+	EditType EditType
+	// Items are the items of the select list of an EditSelect column. See list.List.AddItems for the kinds of items.
+	Items []interface{}
+	// Format is a format string applied to the data using fmt.Sprintf
+	Format string
+	// TimeFormat is a format string applied specifically to time data using time.Format
+	TimeFormat string
+	// SortDirection sets the initial sorting direction of the column, and will make the column sortable
+	// By default, the column is not sortable.
+	SortDirection table.SortDirection
+	table.ColumnOptions
+}
+
+func (c EditColumnCreator) Create(ctx context.Context, parent table.TableI) table.ColumnI {
+	var p CellEditProvider
+	if id, ok := c.CellEditProvider.(string); ok {
+		p = parent.Page().GetControl(id).(CellEditProvider)
+	} else {
+		p = c.CellEditProvider.(CellEditProvider)
+	}
+	col := NewEditColumn(p, c.EditType)
+	if c.ID != "" {
+		col.SetID(c.ID)
+	}
+	col.SetTitle(c.Title)
+	if c.Format != "" {
+		col.SetFormat(c.Format)
+	}
+	if c.TimeFormat != "" {
+		col.SetTimeFormat(c.TimeFormat)
+	}
+	if c.SortDirection != table.NotSortable {
+		col.SetSortDirection(c.SortDirection)
+	}
+	col.ApplyOptions(ctx, parent, c.ColumnOptions)
+	col.items = c.Items // the editor is created when the column is added to the table
+	return col
+}
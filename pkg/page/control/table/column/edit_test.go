@@ -0,0 +1,96 @@
+package column
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/goradd/goradd/pkg/page"
+	"github.com/goradd/goradd/pkg/page/action"
+	"github.com/goradd/goradd/pkg/page/control/list"
+	"github.com/goradd/goradd/pkg/page/control/table"
+	"github.com/stretchr/testify/assert"
+)
+
+type editTestProvider struct{}
+
+// editTestChanges are the changes saved through an editTestProvider
+var editTestChanges []string
+
+func (p editTestProvider) RowID(data interface{}) string {
+	return data.(map[string]string)["id"]
+}
+
+func (p editTestProvider) CellValue(_ context.Context, data interface{}) interface{} {
+	return data.(map[string]string)["status"]
+}
+
+func (p editTestProvider) SetCellValue(_ context.Context, columnID string, rowID string, value interface{}) {
+	editTestChanges = append(editTestChanges, fmt.Sprint(columnID, ":", rowID, ":", value))
+}
+
+func init() {
+	gob.Register(editTestProvider{})
+}
+
+func TestEditColumn(t *testing.T) {
+	f := page.NewMockForm()
+	f.AddControls(context.Background(),
+		table.TableCreator{
+			ID: "table",
+			Columns: table.Columns(
+				EditColumnCreator{
+					ID:               "status",
+					Title:            "Status",
+					CellEditProvider: editTestProvider{},
+					EditType:         EditSelect,
+					Items:            []interface{}{list.NewItem("Open", "1"), list.NewItem("Closed", "2")},
+				},
+			),
+		},
+	)
+	c := table.GetTable(f, "table")
+	col := c.GetColumnByID("status").(*EditColumn)
+	assert.Equal(t, "table-status-edit", col.Editor().ID())
+
+	row := map[string]string{"id": "7", "status": "2"}
+	ctx := context.Background()
+	col.PreRender()
+	assert.Equal(t, "Closed", col.CellText(ctx, 0, 0, row))
+	a := col.CellAttributes(ctx, 0, 0, row)
+	assert.Equal(t, "status", a.DataAttribute("grEdit"))
+	assert.Equal(t, "7", a.DataAttribute("grEditRow"))
+
+	// only the rows that were drawn can be edited
+	col.DoAction(ctx, editParams(editStartAction, "8"))
+	assert.Equal(t, "", col.EditRowID())
+	col.DoAction(ctx, editParams(editStartAction, "7"))
+	assert.Equal(t, "7", col.EditRowID())
+	a = col.CellAttributes(ctx, 0, 0, row)
+	assert.False(t, a.HasDataAttribute("grEdit"))
+	assert.True(t, a.HasClass("gr-editing"))
+
+	var buf bytes.Buffer
+	col.Serialize(gob.NewEncoder(&buf))
+	col2 := EditColumn{}
+	col2.Deserialize(gob.NewDecoder(&buf))
+	assert.Equal(t, "7", col2.EditRowID())
+	assert.Equal(t, EditSelect, col2.editType)
+	assert.Equal(t, "table-status-edit", col2.editorID)
+
+	// the change is saved to the row being edited, whatever the browser sends with the action
+	col.Editor().(*list.SelectList).SetValue("1")
+	col.DoAction(ctx, editParams(editCommitAction, "8"))
+	assert.Equal(t, []string{"status:7:1"}, editTestChanges)
+	assert.Equal(t, "", col.EditRowID())
+}
+
+func editParams(actionValue int, eventValue string) action.Params {
+	return action.NewActionParams("", table.ColumnAction, nil, "table_status", action.RawActionValues{
+		Event:  json.RawMessage(`"` + eventValue + `"`),
+		Action: json.RawMessage(fmt.Sprint(actionValue)),
+	})
+}
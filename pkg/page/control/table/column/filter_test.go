@@ -0,0 +1,53 @@
+package column
+
+import (
+	"context"
+	"testing"
+
+	"github.com/goradd/goradd/pkg/orm/op"
+	"github.com/goradd/goradd/pkg/orm/query"
+	"github.com/goradd/goradd/pkg/page"
+	"github.com/goradd/goradd/pkg/page/control/table"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColumnFilters(t *testing.T) {
+	name := query.NewColumnNode("db", "person", "name", "Name", query.ColTypeString, false)
+	age := query.NewColumnNode("db", "person", "age", "Age", query.ColTypeInteger, false)
+	f := page.NewMockForm()
+	f.AddControls(context.Background(),
+		table.TableCreator{
+			ID: "table",
+			Columns: table.Columns(
+				NodeColumnCreator{
+					ID:            "name",
+					Node:          name,
+					ColumnOptions: table.ColumnOptions{FilterType: table.FilterText},
+				},
+				NodeColumnCreator{
+					ID:            "age",
+					Node:          age,
+					ColumnOptions: table.ColumnOptions{FilterType: table.FilterRange},
+				},
+			),
+		},
+	)
+	c := table.GetTable(f, "table")
+	assert.True(t, c.HasFilters())
+	assert.Equal(t, op.All(), c.FilterCondition())
+
+	nameCol := c.GetColumnByID("name")
+	ageCol := c.GetColumnByID("age")
+	assert.Equal(t, []string{"", ""}, ageCol.FilterValues())
+
+	nameCol.SetFilterValues([]string{"Jo"})
+	assert.Equal(t, op.Contains(name, "Jo"), c.FilterCondition())
+
+	ageCol.SetFilterValues([]string{"", "40"})
+	assert.Equal(t,
+		op.And(op.Contains(name, "Jo"), op.LessOrEqual(age, int64(40))),
+		c.FilterCondition())
+
+	c.ClearFilters()
+	assert.Equal(t, op.All(), c.FilterCondition())
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/goradd/goradd/pkg/any"
+	"github.com/goradd/goradd/pkg/orm/query"
 	"github.com/goradd/goradd/pkg/page/action"
 	"github.com/goradd/goradd/pkg/page/event"
 	time2 "github.com/goradd/goradd/pkg/time"
@@ -80,6 +81,11 @@ type ColumnI interface {
 	SetSortable() ColumnI
 	RenderSortButton(labelHtml string) string
 	SetIsHtml(columnIsHtml bool) ColumnI
+	FilterType() FilterType
+	FilterValues() []string
+	SetFilterValues(values []string)
+	FilterCellHtml(ctx context.Context) string
+	FilterCondition() query.NodeI
 	PreRender()
 	MarshalState(m page.SavedState)
 	UnmarshalState(m page.SavedState)
@@ -141,6 +147,10 @@ type ColumnBase struct {
 	timeFormat string
 	// showLocalTime will convert a time to client local time for display.
 	showLocalTime bool
+	filterType    FilterType
+	filterNode    query.NodeI
+	filterItems   []interface{} // items of the select list of an enum filter, until the filter control is created
+	filterIDs     []string      // ids of the filter controls
 }
 
 func (c *ColumnBase) Init(self ColumnI) {
@@ -244,6 +254,11 @@ func (c *ColumnBase) SetIsHtml(columnIsHtml bool) ColumnI {
 	return c.this()
 }
 
+// IsHtml returns true if the text of the cells is html that will not be escaped.
+func (c *ColumnBase) IsHtml() bool {
+	return c.isHtml
+}
+
 // SetCellStyler sets the CellStyler for the body cells.
 func (c *ColumnBase) SetCellStyler(s CellStyler) {
 	c.cellStyler = s
@@ -519,6 +534,9 @@ type columnBaseEncoded struct {
 	Format           string
 	TimeFormat       string
 	ShowLocalTime    bool
+	FilterType       FilterType
+	FilterNode       query.NodeI
+	FilterIDs        []string
 }
 
 func (c *ColumnBase) Serialize(e page.Encoder) {
@@ -555,6 +573,9 @@ func (c *ColumnBase) Serialize(e page.Encoder) {
 		s.CellStyler = ctrl.ID()
 	}
 	s.ShowLocalTime = c.showLocalTime
+	s.FilterType = c.filterType
+	s.FilterNode = c.filterNode
+	s.FilterIDs = c.filterIDs
 
 	if err := e.Encode(s); err != nil {
 		panic(err)
@@ -610,6 +631,9 @@ func (c *ColumnBase) Deserialize(dec page.Decoder) {
 		}
 	}
 	c.showLocalTime = s.ShowLocalTime
+	c.filterType = s.FilterType
+	c.filterNode = s.FilterNode
+	c.filterIDs = s.FilterIDs
 }
 
 func (c *ColumnBase) Restore(parentTable TableI) {
@@ -672,6 +696,12 @@ type ColumnOptions struct {
 	TimeFormat string
 	// ShowLocalTime will convert the time to the client's local time.
 	ShowLocalTime bool
+	// FilterType gives the column a filter in the filter row of the table.
+	FilterType FilterType
+	// FilterItems are the items of the select list of a FilterEnum filter.
+	FilterItems []interface{}
+	// FilterNode is the node that the filter is applied to. Node columns use their own node by default.
+	FilterNode query.NodeI
 }
 
 func (c *ColumnBase) ApplyOptions(ctx context.Context, parent TableI, opt ColumnOptions) {
@@ -725,6 +755,15 @@ func (c *ColumnBase) ApplyOptions(ctx context.Context, parent TableI, opt Column
 		c.SetTimeFormat(opt.TimeFormat)
 	}
 	c.SetShowLocalTime(opt.ShowLocalTime)
+	if opt.FilterNode != nil {
+		c.SetFilterNode(opt.FilterNode)
+	}
+	if opt.FilterItems != nil {
+		c.SetFilterItems(opt.FilterItems...)
+	}
+	if opt.FilterType != NoFilter {
+		c.SetFilterType(opt.FilterType)
+	}
 }
 
 // ApplyFormat is used by table columns to apply the given fmt.Sprintf and time.Format strings to the data.
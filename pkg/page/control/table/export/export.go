@@ -0,0 +1,277 @@
+// Package export writes the data of a table to a CSV, XLSX or PDF file, and delivers the file to the browser as a download.
+//
+// The entire data set of the table is exported, not just the page that is showing. The text of each cell comes from the
+// CellText function of its column, so the file shows the data the way the table does.
+//
+// To export a table from an action handler, call Download:
+//
+//	if err := export.Download(ctx, f.PeopleTable, export.XLSX, "people"); err != nil {
+//		...
+//	}
+//
+// Call Write to send the file somewhere else.
+package export
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	http2 "github.com/goradd/goradd/pkg/http"
+	"github.com/goradd/goradd/pkg/log"
+	"github.com/goradd/goradd/pkg/page/control"
+	"github.com/goradd/goradd/pkg/page/control/table"
+	"github.com/goradd/goradd/pkg/session"
+)
+
+// Format is the kind of file to export.
+type Format int
+
+const (
+	// CSV is a comma separated values file.
+	CSV Format = iota
+	// XLSX is an Excel spreadsheet.
+	XLSX
+	// PDF is a paginated PDF document.
+	PDF
+)
+
+// Extension returns the file name extension of the format, including the dot.
+func (f Format) Extension() string {
+	switch f {
+	case XLSX:
+		return ".xlsx"
+	case PDF:
+		return ".pdf"
+	default:
+		return ".csv"
+	}
+}
+
+// ContentType returns the mime type of the format.
+func (f Format) ContentType() string {
+	switch f {
+	case XLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case PDF:
+		return "application/pdf"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// Exportable is a data manager with columns, like a table.Table.
+type Exportable interface {
+	control.DataManagerI
+	RangeData(f func(int, interface{}) bool)
+	Columns() []table.ColumnI
+}
+
+// rowWriter is implemented by the writers of each format.
+type rowWriter interface {
+	WriteHeader(titles []string) error
+	WriteRow(cells []string) error
+	Close() error
+}
+
+// DownloadPath is the path of the handler that delivers downloads. The token of the download is added to the end.
+var DownloadPath = "/goradd/export/"
+
+// BatchSize is the number of rows that are loaded from the data provider of a paged table at a time.
+var BatchSize = 1000
+
+// DownloadTTL is the number of seconds a download is available after it is created. It is read each time
+// a download is created, so it can be changed at any time.
+var DownloadTTL int64 = 300
+
+// downloadOwnerKey is the session key of the secret that ties downloads to the session that created them.
+const downloadOwnerKey = "goradd.export.owner"
+
+// downloads holds the downloads created by Download until the browser fetches them, keyed by token.
+var downloads = struct {
+	sync.Mutex
+	items map[string]download
+}{items: make(map[string]download)}
+
+type download struct {
+	fileName    string
+	contentType string
+	write       func(ctx context.Context, w io.Writer) error // writes the file
+	owner       string                                       // the secret of the session that created the download
+	expires     time.Time                                    // when the download is no longer available
+}
+
+func init() {
+	http2.RegisterAppPrefixHandler(DownloadPath, http.HandlerFunc(serveDownload))
+}
+
+// Write writes the data of t to w in the given format.
+//
+// The columns that are not hidden are exported, using their titles as the header of the file. If t is paged,
+// its data is loaded BatchSize rows at a time through SetDataWindow, so its data binder must honor SqlLimits or
+// SliceOffsets, call SetTotalItems, and call SetDataWithOffset so that the rows keep their numbers.
+func Write(ctx context.Context, t Exportable, format Format, w io.Writer) error {
+	var rw rowWriter
+	switch format {
+	case XLSX:
+		rw = newXlsxWriter(w)
+	case PDF:
+		rw = newPdfWriter(w)
+	default:
+		rw = newCsvWriter(w)
+	}
+
+	var columns []table.ColumnI
+	var titles []string
+	for _, col := range t.Columns() {
+		if !col.IsHidden() {
+			columns = append(columns, col)
+			titles = append(titles, col.Title())
+		}
+	}
+	if err := rw.WriteHeader(titles); err != nil {
+		return err
+	}
+
+	if p, ok := t.(control.PagedControlI); ok && t.HasDataProvider() {
+		defer p.SetDataWindow(0, 0)
+		for start := 0; ; start += BatchSize {
+			p.SetDataWindow(start, BatchSize)
+			t.LoadData(ctx, t)
+			n, err := writeRows(ctx, t, columns, rw)
+			t.ResetData()
+			if err != nil {
+				return err
+			}
+			if n < BatchSize || start+n >= p.TotalItems() {
+				break
+			}
+		}
+	} else {
+		if t.HasDataProvider() {
+			t.LoadData(ctx, t)
+			defer t.ResetData()
+		}
+		if _, err := writeRows(ctx, t, columns, rw); err != nil {
+			return err
+		}
+	}
+	return rw.Close()
+}
+
+// writeRows writes the data that is loaded in t, and returns the number of rows written.
+func writeRows(ctx context.Context, t Exportable, columns []table.ColumnI, rw rowWriter) (n int, err error) {
+	t.RangeData(func(index int, value interface{}) bool {
+		cells := make([]string, len(columns))
+		for i, col := range columns {
+			cells[i] = cellText(ctx, col, index, i, value)
+		}
+		if err = rw.WriteRow(cells); err != nil {
+			return false
+		}
+		n++
+		return true
+	})
+	return
+}
+
+var tagRegexp = regexp.MustCompile(`<[^>]*>`)
+
+// cellText returns the text of a cell, removing the markup from columns that draw html.
+func cellText(ctx context.Context, col table.ColumnI, row int, colNum int, data interface{}) string {
+	s := col.CellText(ctx, row, colNum, data)
+	if h, ok := col.(interface{ IsHtml() bool }); ok && h.IsHtml() {
+		s = html.UnescapeString(tagRegexp.ReplaceAllString(s, ""))
+	}
+	return strings.TrimSpace(s)
+}
+
+// Download tells the browser to download the data of t in the given format. Call it from an action handler of the
+// form. fileName is the name of the file without an extension.
+//
+// The file is written to the response by the download handler while the browser fetches it, so t must stay on the
+// form until then. The download can be fetched once, by the session that created it, within DownloadTTL seconds.
+// Errors that happen while writing the file are logged, and end the response.
+func Download(ctx context.Context, t Exportable, format Format, fileName string) error {
+	token, err := storeDownload(ctx, download{
+		fileName:    fileName + format.Extension(),
+		contentType: format.ContentType(),
+		write: func(ctx context.Context, w io.Writer) error {
+			return Write(ctx, t, format, w)
+		},
+	})
+	if err != nil {
+		return err
+	}
+	t.ParentForm().Response().SetLocation(http2.MakeLocalPath(DownloadPath + token))
+	return nil
+}
+
+// storeDownload keeps d for DownloadTTL seconds for the session in ctx, and returns the token that fetches it.
+func storeDownload(ctx context.Context, d download) (string, error) {
+	owner := session.GetString(ctx, downloadOwnerKey)
+	if owner == "" {
+		var err error
+		if owner, err = newToken(); err != nil {
+			return "", err
+		}
+		session.SetString(ctx, downloadOwnerKey, owner)
+	}
+	d.owner = owner
+	now := time.Now()
+	d.expires = now.Add(time.Duration(DownloadTTL) * time.Second)
+	token, err := newToken()
+	if err != nil {
+		return "", err
+	}
+
+	downloads.Lock()
+	defer downloads.Unlock()
+	for k, v := range downloads.items {
+		if now.After(v.expires) {
+			delete(downloads.items, k)
+		}
+	}
+	downloads.items[token] = d
+	return token, nil
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// serveDownload serves a download created by Download.
+func serveDownload(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimPrefix(r.URL.Path, "/")
+	// Only the session that created the download can fetch it
+	owner := session.GetString(r.Context(), downloadOwnerKey)
+	downloads.Lock()
+	d, ok := downloads.items[token]
+	ok = ok && owner != "" && owner == d.owner
+	if ok {
+		delete(downloads.items, token)
+	}
+	downloads.Unlock()
+	if !ok || time.Now().After(d.expires) {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", d.contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", d.fileName))
+	w.Header().Set("Cache-Control", "no-store")
+	if err := d.write(r.Context(), w); err != nil {
+		log.Error("export of " + d.fileName + " failed: " + err.Error())
+	}
+}
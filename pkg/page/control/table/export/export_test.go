@@ -0,0 +1,169 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goradd/goradd/pkg/page/control"
+	"github.com/goradd/goradd/pkg/page/control/table"
+	"github.com/goradd/goradd/pkg/page/control/table/column"
+	"github.com/goradd/goradd/pkg/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type exportTestForm struct {
+	control.FormBase
+	data  [][]string
+	loads int
+}
+
+func (f *exportTestForm) Init(ctx context.Context, id string) {
+	f.FormBase.Init(f, ctx, id)
+}
+
+// BindData binds a page of the data, the way a paged data binder would.
+func (f *exportTestForm) BindData(_ context.Context, s control.DataManagerI) {
+	t := s.(*table.PagedTable)
+	t.SetTotalItems(uint(len(f.data)))
+	start, end := t.SliceOffsets()
+	t.SetDataWithOffset(f.data[start:end], start)
+	f.loads++
+}
+
+func newExportTestTable(rowCount int) *table.PagedTable {
+	f := new(exportTestForm)
+	f.Init(context.Background(), "MockFormId")
+	f.data = append(f.data, []string{"Smith, Jo", "007"})
+	for i := 1; i < rowCount; i++ {
+		f.data = append(f.data, []string{"Person " + strconv.Itoa(i), strconv.Itoa(i)})
+	}
+	f.AddControls(context.Background(),
+		table.PagedTableCreator{
+			ID:           "table",
+			DataProvider: f,
+			PageSize:     2,
+			Columns: table.Columns(
+				column.SliceColumnCreator{Index: 0, Title: "Name"},
+				column.SliceColumnCreator{Index: 1, Title: "Number"},
+			),
+		},
+	)
+	return table.GetPagedTable(f, "table")
+}
+
+func TestWriteCsv(t *testing.T) {
+	tbl := newExportTestTable(3)
+	var buf bytes.Buffer
+	require.NoError(t, Write(context.Background(), tbl, CSV, &buf))
+	// All the rows are exported, not just the first page
+	assert.Equal(t, "Name,Number\n\"Smith, Jo\",007\nPerson 1,1\nPerson 2,2\n", buf.String())
+	start, end := tbl.SliceOffsets()
+	assert.Equal(t, 2, end-start, "paging is restored")
+}
+
+// rowNumTexter shows the number of the row.
+type rowNumTexter struct{}
+
+func (rowNumTexter) CellText(_ context.Context, _ table.ColumnI, info table.CellInfo) string {
+	return strconv.Itoa(info.RowNum)
+}
+
+func TestWriteBatches(t *testing.T) {
+	defer func(n int) { BatchSize = n }(BatchSize)
+	BatchSize = 2
+	tbl := newExportTestTable(5)
+	tbl.AddColumn(column.NewTexterColumn(rowNumTexter{}).SetTitle("Row"))
+	var buf bytes.Buffer
+	require.NoError(t, Write(context.Background(), tbl, CSV, &buf))
+	assert.Equal(t, "Name,Number,Row\n\"Smith, Jo\",007,0\nPerson 1,1,1\nPerson 2,2,2\nPerson 3,3,3\nPerson 4,4,4\n", buf.String())
+	assert.Equal(t, 3, tbl.ParentForm().(*exportTestForm).loads, "the data is loaded a batch at a time")
+}
+
+func TestWriteXlsx(t *testing.T) {
+	tbl := newExportTestTable(3)
+	var buf bytes.Buffer
+	require.NoError(t, Write(context.Background(), tbl, XLSX, &buf))
+
+	z, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	var sheet string
+	for _, f := range z.File {
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			r, _ := f.Open()
+			b, _ := io.ReadAll(r)
+			sheet = string(b)
+		}
+	}
+	assert.Contains(t, sheet, `<c r="A1" t="inlineStr" s="1"><is><t xml:space="preserve">Name</t></is></c>`)
+	assert.Contains(t, sheet, `<c r="B2" t="inlineStr"><is><t xml:space="preserve">007</t></is></c>`)
+	assert.Contains(t, sheet, `<c r="B4"><v>2</v></c>`)
+	assert.Equal(t, "AB", xlsxColumnName(27))
+}
+
+func TestWritePdf(t *testing.T) {
+	tbl := newExportTestTable(120)
+	var buf bytes.Buffer
+	require.NoError(t, Write(context.Background(), tbl, PDF, &buf))
+
+	s := buf.String()
+	assert.True(t, strings.HasPrefix(s, "%PDF-1.4"))
+	assert.True(t, strings.HasSuffix(s, "%%EOF\n"))
+	assert.Contains(t, s, "/Count 3")
+	assert.Contains(t, s, "(Person 119  119) Tj")
+	assert.Contains(t, s, "(3 / 3) Tj")
+	assert.Equal(t, `a\(b\)\\ \351?`, pdfLine([]string{"a(b)\\ é☃"}, []int{10}))
+}
+
+func TestServeDownload(t *testing.T) {
+	s := session.NewMock()
+	session.SetSessionManager(s)
+	ctx := s.With(context.Background())
+
+	newDownload := func() string {
+		token, err := storeDownload(ctx, download{fileName: "a.csv", contentType: "text/csv", write: func(_ context.Context, w io.Writer) error {
+			_, err := w.Write([]byte("a,b"))
+			return err
+		}})
+		require.NoError(t, err)
+		return token
+	}
+	get := func(ctx context.Context, token string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", "/"+token, nil).WithContext(ctx)
+		w := httptest.NewRecorder()
+		serveDownload(w, r)
+		return w
+	}
+
+	token := newDownload()
+	// another session cannot fetch it
+	w := get(s.With(context.Background()), token)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = get(ctx, token)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "a,b", w.Body.String())
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "a.csv")
+
+	// it can only be fetched once
+	w = get(ctx, token)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// an expired download is gone
+	token = newDownload()
+	downloads.Lock()
+	d := downloads.items[token]
+	d.expires = time.Now().Add(-time.Second)
+	downloads.items[token] = d
+	downloads.Unlock()
+	w = get(ctx, token)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
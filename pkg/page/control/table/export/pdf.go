@@ -0,0 +1,196 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// The PDF is a landscape letter page using the Courier font, so that the columns line up without font metrics.
+const (
+	pdfPageWidth   = 792
+	pdfPageHeight  = 612
+	pdfMargin      = 36
+	pdfFontSize    = 8
+	pdfLineHeight  = 10
+	pdfCharWidth   = pdfFontSize * 0.6 // Courier characters are 600/1000 of the font size wide
+	pdfColumnGap   = 2                 // characters between columns
+	pdfMinColWidth = 3                 // characters
+	pdfTitleY      = pdfPageHeight - pdfMargin - pdfFontSize
+	pdfFirstRowY   = pdfTitleY - 14
+	pdfFooterY     = pdfMargin - 12
+	pdfRowsPerPage = (pdfFirstRowY-(pdfMargin+12))/pdfLineHeight + 1
+)
+
+// pdfWriter writes a simple paginated table. Since the widths of the columns depend on all the data,
+// the rows are collected and the document is written when the writer is closed.
+type pdfWriter struct {
+	w      io.Writer
+	titles []string
+	rows   [][]string
+}
+
+func newPdfWriter(w io.Writer) *pdfWriter {
+	return &pdfWriter{w: w}
+}
+
+func (p *pdfWriter) WriteHeader(titles []string) error {
+	p.titles = titles
+	return nil
+}
+
+func (p *pdfWriter) WriteRow(cells []string) error {
+	p.rows = append(p.rows, cells)
+	return nil
+}
+
+func (p *pdfWriter) Close() error {
+	widths := p.columnWidths()
+	title := pdfLine(p.titles, widths)
+
+	pageCount := (len(p.rows) + pdfRowsPerPage - 1) / pdfRowsPerPage
+	if pageCount == 0 {
+		pageCount = 1
+	}
+	var contents [][]byte
+	for i := 0; i < pageCount; i++ {
+		var b bytes.Buffer
+		fmt.Fprintf(&b, "BT /F2 %d Tf %d %d Td (%s) Tj ET\n", pdfFontSize, pdfMargin, pdfTitleY, title)
+		fmt.Fprintf(&b, "0.5 w %d %d m %d %d l S\n", pdfMargin, pdfTitleY-4, pdfPageWidth-pdfMargin, pdfTitleY-4)
+		fmt.Fprintf(&b, "BT /F1 %d Tf %d TL %d %d Td\n", pdfFontSize, pdfLineHeight, pdfMargin, pdfFirstRowY)
+		end := (i + 1) * pdfRowsPerPage
+		if end > len(p.rows) {
+			end = len(p.rows)
+		}
+		for _, row := range p.rows[i*pdfRowsPerPage : end] {
+			fmt.Fprintf(&b, "(%s) Tj T*\n", pdfLine(row, widths))
+		}
+		b.WriteString("ET\n")
+		footer := fmt.Sprintf("%d / %d", i+1, pageCount)
+		x := float64(pdfPageWidth-pdfMargin) - float64(len(footer))*pdfCharWidth
+		fmt.Fprintf(&b, "BT /F1 %d Tf %.1f %d Td (%s) Tj ET\n", pdfFontSize, x, pdfFooterY, footer)
+		contents = append(contents, b.Bytes())
+	}
+	return p.writeDocument(contents)
+}
+
+// columnWidths returns the width in characters of each column, shrinking the widest columns until the table fits the page.
+func (p *pdfWriter) columnWidths() []int {
+	widths := make([]int, len(p.titles))
+	measure := func(cells []string) {
+		for i, c := range cells {
+			if i < len(widths) && utf8.RuneCountInString(c) > widths[i] {
+				widths[i] = utf8.RuneCountInString(c)
+			}
+		}
+	}
+	measure(p.titles)
+	for _, row := range p.rows {
+		measure(row)
+	}
+
+	if len(widths) == 0 {
+		return widths
+	}
+	available := int(float64(pdfPageWidth-2*pdfMargin) / pdfCharWidth)
+	for {
+		total := 0
+		widest := 0
+		for i, w := range widths {
+			if w < pdfMinColWidth {
+				widths[i] = pdfMinColWidth
+			}
+			total += widths[i] + pdfColumnGap
+			if widths[i] > widths[widest] {
+				widest = i
+			}
+		}
+		if total-pdfColumnGap <= available || widths[widest] <= pdfMinColWidth {
+			return widths
+		}
+		widths[widest]--
+	}
+}
+
+// pdfLine returns the cells padded or truncated to the widths of their columns, as the content of a PDF string.
+func pdfLine(cells []string, widths []int) string {
+	var b strings.Builder
+	for i, w := range widths {
+		var cell []rune
+		if i < len(cells) {
+			cell = []rune(cells[i])
+		}
+		if len(cell) > w {
+			cell = append(cell[:w-1], '…')
+		}
+		for _, r := range cell {
+			b.WriteString(pdfChar(r))
+		}
+		if i < len(widths)-1 {
+			b.WriteString(strings.Repeat(" ", w-len(cell)+pdfColumnGap))
+		}
+	}
+	return b.String()
+}
+
+// winAnsiChars are the characters of WinAnsiEncoding that are not in Latin-1.
+var winAnsiChars = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88, '‰': 0x89, 'Š': 0x8A,
+	'‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+	'˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// pdfChar returns the rune encoded for a PDF string in WinAnsiEncoding.
+func pdfChar(r rune) string {
+	switch {
+	case r == '(' || r == ')' || r == '\\':
+		return `\` + string(r)
+	case r < ' ':
+		return " "
+	case r < 0x7F:
+		return string(r)
+	case r >= 0xA0 && r <= 0xFF:
+		return fmt.Sprintf(`\%03o`, r)
+	}
+	if c, ok := winAnsiChars[r]; ok {
+		return fmt.Sprintf(`\%03o`, c)
+	}
+	return "?"
+}
+
+// writeDocument writes the PDF objects: the catalog, the page tree, the two fonts, and a page and content stream for each page.
+func (p *pdfWriter) writeDocument(contents [][]byte) error {
+	var b bytes.Buffer
+	var offsets []int
+	obj := func(s string) {
+		offsets = append(offsets, b.Len())
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", len(offsets), s)
+	}
+
+	b.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	var kids []string
+	for i := range contents {
+		kids = append(kids, fmt.Sprintf("%d 0 R", 5+2*i))
+	}
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(contents)))
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>")
+	for i, content := range contents {
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 6+2*i))
+		obj(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content))
+	}
+
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, o := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", o)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := p.w.Write(b.Bytes())
+	return err
+}
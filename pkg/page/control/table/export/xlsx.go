@@ -0,0 +1,148 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`</Types>`
+
+const xlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+	`<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets>` +
+	`</workbook>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+// xlsxStyles has a normal cell style, and a bold style for the header.
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+	`</styleSheet>`
+
+const xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+
+const xlsxSheetEnd = `</sheetData></worksheet>`
+
+// numberRegexp matches text that a spreadsheet should treat as a number. Numbers with leading zeros,
+// like zip codes, are kept as text.
+var numberRegexp = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?$`)
+
+// xlsxWriter writes a single sheet spreadsheet. The rows are streamed into the sheet as they are written.
+type xlsxWriter struct {
+	z     *zip.Writer
+	sheet io.Writer
+	row   int
+	err   error
+}
+
+func newXlsxWriter(w io.Writer) *xlsxWriter {
+	return &xlsxWriter{z: zip.NewWriter(w)}
+}
+
+func (x *xlsxWriter) WriteHeader(titles []string) error {
+	// The sheet is written last, so that it can be streamed
+	files := []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, f := range files {
+		fw, err := x.z.Create(f.name)
+		if err != nil {
+			return err
+		}
+		if _, err = io.WriteString(fw, f.content); err != nil {
+			return err
+		}
+	}
+	var err error
+	if x.sheet, err = x.z.Create("xl/worksheets/sheet1.xml"); err != nil {
+		return err
+	}
+	x.writeString(xlsxSheetStart)
+	x.writeRow(titles, true)
+	return x.err
+}
+
+func (x *xlsxWriter) WriteRow(cells []string) error {
+	x.writeRow(cells, false)
+	return x.err
+}
+
+func (x *xlsxWriter) Close() error {
+	x.writeString(xlsxSheetEnd)
+	if x.err != nil {
+		return x.err
+	}
+	return x.z.Close()
+}
+
+func (x *xlsxWriter) writeRow(cells []string, isHeader bool) {
+	x.row++
+	r := strconv.Itoa(x.row)
+	x.writeString(`<row r="` + r + `">`)
+	for i, cell := range cells {
+		ref := xlsxColumnName(i) + r
+		if isHeader {
+			x.writeString(`<c r="` + ref + `" t="inlineStr" s="1"><is><t xml:space="preserve">`)
+			x.writeText(cell)
+			x.writeString(`</t></is></c>`)
+		} else if numberRegexp.MatchString(cell) {
+			x.writeString(`<c r="` + ref + `"><v>` + cell + `</v></c>`)
+		} else if cell != "" {
+			x.writeString(`<c r="` + ref + `" t="inlineStr"><is><t xml:space="preserve">`)
+			x.writeText(cell)
+			x.writeString(`</t></is></c>`)
+		}
+	}
+	x.writeString(`</row>`)
+}
+
+func (x *xlsxWriter) writeString(s string) {
+	if x.err == nil {
+		_, x.err = io.WriteString(x.sheet, s)
+	}
+}
+
+func (x *xlsxWriter) writeText(s string) {
+	if x.err == nil {
+		x.err = xml.EscapeText(x.sheet, []byte(s))
+	}
+}
+
+// xlsxColumnName returns the spreadsheet name of the zero based column i, like A, B, or AA.
+func xlsxColumnName(i int) string {
+	var name []byte
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = append([]byte{byte('A' + (i-1)%26)}, name...)
+	}
+	return string(name)
+}
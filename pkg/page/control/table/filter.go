@@ -0,0 +1,343 @@
+package table
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/goradd/goradd/pkg/orm/op"
+	"github.com/goradd/goradd/pkg/orm/query"
+	"github.com/goradd/goradd/pkg/page"
+	"github.com/goradd/goradd/pkg/page/action"
+	"github.com/goradd/goradd/pkg/page/control/list"
+	"github.com/goradd/goradd/pkg/page/control/textbox"
+	"github.com/goradd/goradd/pkg/page/event"
+	"github.com/goradd/goradd/pkg/pool"
+	"github.com/goradd/html5tag"
+)
+
+// FilterType is the kind of filter that a column shows in the filter row of its table.
+type FilterType int
+
+const (
+	// NoFilter indicates the column cannot be filtered.
+	NoFilter FilterType = iota
+	// FilterText shows a textbox, and filters on data that contains the text.
+	FilterText
+	// FilterRange shows two number boxes, and filters on data that is between the minimum and maximum numbers.
+	FilterRange
+	// FilterEnum shows a select list, and filters on data that equals the value of the selected item.
+	FilterEnum
+	// FilterDateRange shows two date boxes, and filters on dates that are on or after the first date, and on or before the second.
+	FilterDateRange
+)
+
+// filterDateFormat is the format of the value of a date input.
+const filterDateFormat = "2006-01-02"
+
+type filterNoder interface {
+	GetNode() query.NodeI
+}
+
+// filterControlMaker is implemented by columns that create the controls of their filters when they are added to a
+// table, which are the columns that embed ColumnBase. Other columns do not get filter controls.
+type filterControlMaker interface {
+	makeFilterControls()
+}
+
+// SetFilterType gives the column a filter in the filter row of the table.
+// The filter will be applied to the node given by SetFilterNode, or to the node of the column if it is a node column.
+//
+// For a FilterEnum filter, also call SetFilterItems to give the items of the select list.
+func (c *ColumnBase) SetFilterType(filterType FilterType) ColumnI {
+	c.filterType = filterType
+	if c.parentTable != nil && c.id != "" {
+		c.makeFilterControls()
+		c.parentTable.Refresh()
+	}
+	return c.this()
+}
+
+// FilterType returns the kind of filter the column has.
+func (c *ColumnBase) FilterType() FilterType {
+	return c.filterType
+}
+
+// SetFilterItems sets the items of the select list of a FilterEnum filter. See list.List.AddItems for the kinds
+// of items you can add. An item that selects everything is added to the top of the list.
+func (c *ColumnBase) SetFilterItems(items ...interface{}) ColumnI {
+	if l, ok := c.filterControl(0).(*list.SelectList); ok {
+		l.Clear()
+		l.Add("", "")
+		l.AddItems(items...)
+	} else {
+		c.filterItems = items
+	}
+	return c.this()
+}
+
+// SetFilterNode sets the node that the filter of the column is applied to. You do not need to set this for
+// node columns, which filter on their own node.
+func (c *ColumnBase) SetFilterNode(n query.NodeI) ColumnI {
+	c.filterNode = n
+	return c.this()
+}
+
+// FilterNode returns the node that the filter of the column is applied to.
+func (c *ColumnBase) FilterNode() query.NodeI {
+	if c.filterNode != nil {
+		return c.filterNode
+	}
+	if n, ok := c.this().(filterNoder); ok {
+		return n.GetNode()
+	}
+	return nil
+}
+
+// makeFilterControls creates the controls that show the filter in the filter row.
+func (c *ColumnBase) makeFilterControls() {
+	if c.filterType == NoFilter || c.filterIDs != nil {
+		return
+	}
+	t := c.parentTable
+	// The id cannot have an underscore, since that would look like the id of a column to the action.
+	id := t.ID() + "-" + c.ID() + "-filter"
+	var controls []page.ControlI
+
+	switch c.filterType {
+	case FilterText:
+		tb := textbox.NewTextbox(t, id)
+		tb.SetType(textbox.SearchType)
+		controls = append(controls, tb)
+	case FilterRange, FilterDateRange:
+		typ := textbox.NumberType
+		if c.filterType == FilterDateRange {
+			typ = "date"
+		}
+		from := textbox.NewTextbox(t, id)
+		from.SetType(typ)
+		from.SetAttribute("aria-label", t.GT("From"))
+		to := textbox.NewTextbox(t, id+"2")
+		to.SetType(typ)
+		to.SetAttribute("aria-label", t.GT("To"))
+		controls = append(controls, from, to)
+	case FilterEnum:
+		l := list.NewSelectList(t, id)
+		l.Add("", "")
+		l.AddItems(c.filterItems...)
+		c.filterItems = nil
+		controls = append(controls, l)
+	}
+
+	for _, ctrl := range controls {
+		ctrl.SetAttribute("aria-controls", t.ID())
+		ctrl.On(event.Change().Private().Action(action.Do().ControlID(t.ID()).ID(FilterChange)))
+		c.filterIDs = append(c.filterIDs, ctrl.ID())
+	}
+}
+
+// filterControl returns the i-th control of the filter, or nil if it does not exist.
+func (c *ColumnBase) filterControl(i int) page.ControlI {
+	if i >= len(c.filterIDs) || c.parentTable == nil {
+		return nil
+	}
+	return c.parentTable.Page().GetControl(c.filterIDs[i])
+}
+
+// FilterValues returns the values the user entered into the filter of the column. A range filter has two values,
+// and other filters have one.
+func (c *ColumnBase) FilterValues() (values []string) {
+	for i := range c.filterIDs {
+		switch ctrl := c.filterControl(i).(type) {
+		case *textbox.Textbox:
+			values = append(values, ctrl.Text())
+		case *list.SelectList:
+			values = append(values, ctrl.StringValue())
+		}
+	}
+	return
+}
+
+// SetFilterValues sets the values of the filter of the column.
+func (c *ColumnBase) SetFilterValues(values []string) {
+	for i, v := range values {
+		switch ctrl := c.filterControl(i).(type) {
+		case *textbox.Textbox:
+			ctrl.SetText(v)
+		case *list.SelectList:
+			if _, item := ctrl.GetItemByValue(v); item != nil {
+				ctrl.SetSelectedValue(v)
+			}
+		}
+	}
+}
+
+// FilterCellHtml returns the html of the cell of the column in the filter row of the table.
+func (c *ColumnBase) FilterCellHtml(ctx context.Context) string {
+	buf := pool.GetBuffer()
+	defer pool.PutBuffer(buf)
+	for i := range c.filterIDs {
+		if ctrl := c.filterControl(i); ctrl != nil {
+			ctrl.Draw(ctx, buf)
+		}
+	}
+	return buf.String()
+}
+
+// FilterCondition returns the condition that the values of the filter of the column represent,
+// or nil if the filter is empty.
+func (c *ColumnBase) FilterCondition() query.NodeI {
+	values := c.FilterValues()
+	var conditions []interface{}
+
+	node := func() query.NodeI {
+		n := c.FilterNode()
+		if n == nil {
+			panic("column " + c.ID() + " has a filter, but does not have a filter node")
+		}
+		return n
+	}
+
+	switch c.filterType {
+	case FilterText:
+		if len(values) > 0 && values[0] != "" {
+			conditions = append(conditions, op.Contains(node(), values[0]))
+		}
+	case FilterEnum:
+		if len(values) > 0 && values[0] != "" {
+			conditions = append(conditions, op.Equal(node(), filterNumber(values[0])))
+		}
+	case FilterRange:
+		if len(values) > 0 && values[0] != "" {
+			conditions = append(conditions, op.GreaterOrEqual(node(), filterNumber(values[0])))
+		}
+		if len(values) > 1 && values[1] != "" {
+			conditions = append(conditions, op.LessOrEqual(node(), filterNumber(values[1])))
+		}
+	case FilterDateRange:
+		if len(values) > 0 && values[0] != "" {
+			if d, err := time.Parse(filterDateFormat, values[0]); err == nil {
+				conditions = append(conditions, op.GreaterOrEqual(node(), d))
+			}
+		}
+		if len(values) > 1 && values[1] != "" {
+			if d, err := time.Parse(filterDateFormat, values[1]); err == nil {
+				// include the entire day
+				conditions = append(conditions, op.LessThan(node(), d.AddDate(0, 0, 1)))
+			}
+		}
+	}
+
+	switch len(conditions) {
+	case 0:
+		return nil
+	case 1:
+		return conditions[0].(query.NodeI)
+	default:
+		return op.And(conditions...)
+	}
+}
+
+// filterNumber converts a filter value to a number if it is one, so that it compares correctly to numeric data.
+func filterNumber(v string) interface{} {
+	if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(v, 64); err == nil {
+		return f
+	}
+	return v
+}
+
+// HasFilters returns true if any of the columns of the table has a filter, in which case the table draws a filter row.
+func (t *Table) HasFilters() bool {
+	for _, col := range t.columns {
+		if col.FilterType() != NoFilter {
+			return true
+		}
+	}
+	return false
+}
+
+// DrawFilterRow is called by the framework to draw the row of column filters at the bottom of the header.
+func (t *Table) DrawFilterRow(ctx context.Context, w io.Writer) {
+	buf := pool.GetBuffer()
+	defer pool.PutBuffer(buf)
+	for _, col := range t.columns {
+		if !col.IsHidden() {
+			page.WriteString(buf, html5tag.RenderTag("td", nil, col.FilterCellHtml(ctx)))
+		}
+	}
+	a := html5tag.NewAttributes().AddClass("gr-filter-row")
+	page.WriteString(w, html5tag.RenderTag("tr", a, buf.String()))
+}
+
+// FilterCondition returns the condition that represents the values of all the column filters of the table.
+// Pass it to the Where function of a query builder in your DataBinder to show only the rows that pass the filters.
+// If no filter has a value, it returns a condition that selects everything.
+func (t *Table) FilterCondition() query.NodeI {
+	var conditions []interface{}
+	for _, col := range t.columns {
+		if col.FilterType() == NoFilter {
+			continue
+		}
+		if cond := col.FilterCondition(); cond != nil {
+			conditions = append(conditions, cond)
+		}
+	}
+	switch len(conditions) {
+	case 0:
+		return op.All()
+	case 1:
+		return conditions[0].(query.NodeI)
+	default:
+		return op.And(conditions...)
+	}
+}
+
+// ClearFilters empties all the column filters of the table.
+func (t *Table) ClearFilters() {
+	for _, col := range t.columns {
+		if values := col.FilterValues(); values != nil {
+			col.SetFilterValues(make([]string, len(values)))
+		}
+	}
+	t.Refresh()
+}
+
+// filterChanged responds to a change in a column filter.
+func (t *Table) filterChanged(p action.Params) {
+	// Show the filtered rows from the beginning
+	if pc, ok := t.Self().(interface{ ResetPosition() }); ok {
+		pc.ResetPosition()
+	}
+	t.Refresh()
+	// Refreshing redraws the filter, so put the focus back
+	if p.ControlId != "" {
+		t.ParentForm().Response().ExecuteControlCommand(p.ControlId, "focus", page.PriorityLow)
+	}
+}
+
+// filterState returns the values of the filters to save in the session.
+func (t *Table) filterState() map[string][]string {
+	var m map[string][]string
+	for _, col := range t.columns {
+		if values := col.FilterValues(); values != nil {
+			if m == nil {
+				m = make(map[string][]string)
+			}
+			m[col.ID()] = values
+		}
+	}
+	return m
+}
+
+// setFilterState restores the values of the filters saved in the session.
+func (t *Table) setFilterState(m map[string][]string) {
+	for id, values := range m {
+		if col := t.GetColumnByID(id); col != nil {
+			col.SetFilterValues(values)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/goradd/goradd/pkg/page"
 	"github.com/goradd/goradd/pkg/page/action"
+	"github.com/goradd/html5tag"
 )
 
 type PagedTableI interface {
@@ -29,6 +30,50 @@ func (t *PagedTable) Init(self any, parent page.ControlI, id string) {
 	t.PagedControl.SetPageSize(0) // use the application default
 }
 
+// SetVirtualScroll turns on virtual scrolling, which draws only the rows that are scrolled into view.
+// See PagedControl.SetVirtualScroll.
+func (t *PagedTable) SetVirtualScroll(rowHeight int) {
+	if rowHeight > 0 && !t.IsVirtualScroll() {
+		control2.AddVirtualScrollEvent(t)
+	}
+	t.PagedControl.SetVirtualScroll(rowHeight)
+	t.Refresh()
+}
+
+// ResetPosition moves the table back to the first page, or scrolls it back to the top when virtually scrolling.
+func (t *PagedTable) ResetPosition() {
+	t.PagedControl.ResetPosition()
+	if t.IsVirtualScroll() {
+		t.Refresh()
+		t.ExecuteWidgetFunction("scrollToStart", page.PriorityLow)
+	}
+}
+
+// DrawingAttributes is an override to attach the virtual scrolling widget to the table.
+func (t *PagedTable) DrawingAttributes(ctx context.Context) html5tag.Attributes {
+	a := t.Table.DrawingAttributes(ctx)
+	t.PagedControl.VirtualScrollAttributes(a)
+	return a
+}
+
+// UpdateFormValues is used by the framework to cause the control to retrieve its values from the form
+func (t *PagedTable) UpdateFormValues(ctx context.Context) {
+	t.Table.UpdateFormValues(ctx)
+	t.PagedControl.UpdateVirtualScroll(ctx, t.ID())
+}
+
+// DoPrivateAction is called by the framework to redraw the table when it is virtually scrolled.
+func (t *PagedTable) DoPrivateAction(ctx context.Context, p action.Params) {
+	switch p.ID {
+	case control2.VirtualScrollChange:
+		if t.DoVirtualScrollAction(p) {
+			t.Refresh()
+		}
+	default:
+		t.Table.DoPrivateAction(ctx, p)
+	}
+}
+
 // MarshalState is an internal function to save the state of the control
 func (t *PagedTable) MarshalState(m page.SavedState) {
 	t.PagedControl.MarshalState(m)
@@ -92,6 +137,11 @@ type PagedTableCreator struct {
 	PageSize int
 	// SaveState will cause the table to remember what page it was on
 	SaveState bool
+	// VirtualScrollRowHeight turns on virtual scrolling, and is the height of each row in pixels.
+	// See PagedControl.SetVirtualScroll.
+	VirtualScrollRowHeight int
+	// Reorderable lets the user reorder the rows. See Table.SetReorderable.
+	Reorderable bool
 }
 
 // Create is called by the framework to create a new control from the Creator. You
@@ -125,11 +175,15 @@ func (c PagedTableCreator) Init(ctx context.Context, ctrl PagedTableI) {
 		OnCellClick:       c.OnCellClick,
 		ControlOptions:    c.ControlOptions,
 		SortColumnIDs:     c.SortColumnIDs,
+		Reorderable:       c.Reorderable,
 	}
 	sub.Init(ctx, ctrl)
 	if c.PageSize != 0 {
 		ctrl.SetPageSize(c.PageSize)
 	}
+	if c.VirtualScrollRowHeight != 0 {
+		ctrl.SetVirtualScroll(c.VirtualScrollRowHeight)
+	}
 	if c.SaveState {
 		ctrl.SaveState(ctx, true)
 	}
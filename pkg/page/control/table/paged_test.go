@@ -77,3 +77,104 @@ func TestPagedTable_Serialize(t *testing.T) {
 	assert.Equal(t, "This is a table", c2.caption)
 	assert.Equal(t, 3, c2.sortHistoryLimit)
 }
+
+func TestPagedTable_VirtualScroll(t *testing.T) {
+	f := new(pagedTableTestForm)
+	f.Init(context.Background(), "MockFormId")
+
+	f.AddControls(context.Background(),
+		PagedTableCreator{
+			ID:                     "table",
+			DataProvider:           f,
+			PageSize:               30,
+			VirtualScrollRowHeight: 20,
+		},
+	)
+
+	c := GetPagedTable(f, "table")
+	assert.True(t, c.IsVirtualScroll())
+	c.SetTotalItems(1000)
+
+	// Scrolling to the 100th row with 10 rows visible centers the window of 30 rows on the visible rows
+	assert.True(t, c.ScrollTo(2000, 200))
+	assert.False(t, c.ScrollTo(2000, 200))
+	assert.Equal(t, 90, c.FirstRow())
+	maxRowCount, offset := c.SqlLimits()
+	assert.Equal(t, 30, maxRowCount)
+	assert.Equal(t, 90, offset)
+	top, bottom := c.ScrollSpacerHeights(30)
+	assert.Equal(t, 1800, top)
+	assert.Equal(t, 17600, bottom)
+
+	// The window stops at the end of the data
+	c.ScrollTo(100000, 200)
+	assert.Equal(t, 970, c.FirstRow())
+	c.SetTotalItems(50)
+	assert.Equal(t, 20, c.FirstRow())
+
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+	c.Serialize(enc)
+	c2 := PagedTable{}
+	dec := gob.NewDecoder(&buf)
+	c2.Deserialize(dec)
+	assert.True(t, c2.IsVirtualScroll())
+	assert.Equal(t, 20, c2.FirstRow())
+}
+
+func TestPagedTable_Reorderable(t *testing.T) {
+	f := new(pagedTableTestForm)
+	f.Init(context.Background(), "MockFormId")
+
+	f.AddControls(context.Background(),
+		PagedTableCreator{
+			ID:           "table",
+			DataProvider: f,
+			Reorderable:  true,
+		},
+	)
+
+	c := GetPagedTable(f, "table")
+	assert.True(t, c.IsReorderable())
+	a := c.RowAttributes(0, map[string]string{"id": "5"})
+	assert.Equal(t, "5", a.DataAttribute("id"))
+
+	c.SetReorderable(false)
+	assert.Nil(t, c.RowAttributes(0, map[string]string{"id": "5"}))
+
+	c.SetReorderable(true)
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+	c.Serialize(enc)
+	c2 := PagedTable{}
+	dec := gob.NewDecoder(&buf)
+	c2.Deserialize(dec)
+	assert.True(t, c2.IsReorderable())
+}
+
+func TestPagedTable_ResetPosition(t *testing.T) {
+	f := new(pagedTableTestForm)
+	f.Init(context.Background(), "MockFormId")
+
+	f.AddControls(context.Background(),
+		PagedTableCreator{
+			ID:                     "table",
+			DataProvider:           f,
+			PageSize:               30,
+			VirtualScrollRowHeight: 20,
+		},
+	)
+
+	c := GetPagedTable(f, "table")
+	c.SetTotalItems(1000)
+	c.ScrollTo(2000, 200)
+	c.SetPageNum(3)
+	assert.Equal(t, 90, c.FirstRow())
+
+	// a change in the filter shows the rows from the top
+	c.ResetPosition()
+	assert.Equal(t, 0, c.FirstRow())
+	assert.Equal(t, 1, c.PageNum())
+	_, offset := c.SqlLimits()
+	assert.Equal(t, 0, offset)
+}
@@ -19,6 +19,21 @@ type PrimaryKeyer interface {
 	PrimaryKey() string
 }
 
+// rowDataID tries to find the id of the data of a row.
+func rowDataID(data interface{}) (id string) {
+	switch obj := data.(type) {
+	case list.IDer:
+		id = obj.ID()
+	case PrimaryKeyer:
+		id = obj.PrimaryKey()
+	case map[string]string:
+		id, _ = obj["id"]
+	case maps.Getter[string, string]:
+		id = obj.Get("id")
+	}
+	return
+}
+
 type SelectTableI interface {
 	TableI
 	SetSelectedID(id string) SelectTableI
@@ -70,16 +85,7 @@ func (t *SelectTable) RowAttributes(row int, data interface{}) (a html5tag.Attri
 
 	// try to guess the id from the data
 	if id == "" {
-		switch obj := data.(type) {
-		case list.IDer:
-			id = obj.ID()
-		case PrimaryKeyer:
-			id = obj.PrimaryKey()
-		case map[string]string:
-			id, _ = obj["id"]
-		case maps.Getter[string, string]:
-			id = obj.Get("id")
-		}
+		id = rowDataID(data)
 	}
 	if id != "" {
 		// TODO: If configured, encrypt the id so its not publicly showing database ids
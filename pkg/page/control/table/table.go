@@ -25,6 +25,7 @@ import (
 const (
 	ColumnAction = iota + 2000
 	SortClick
+	FilterChange
 )
 
 // TableI is the table interface that lets you create a "subclass" of the Table object.
@@ -32,6 +33,7 @@ const (
 type TableI interface {
 	page.ControlI
 	control2.DataManagerI
+	control2.ReorderableI
 	SetCaption(interface{}) TableI
 	DrawCaption(context.Context, io.Writer)
 	HeaderRowAttributes(row int) html5tag.Attributes
@@ -48,6 +50,7 @@ type TableI interface {
 	AddColumnAt(column ColumnI, loc int)
 	AddColumn(column ColumnI) ColumnI
 	GetColumn(loc int) ColumnI
+	Columns() []ColumnI
 	GetColumnByID(id string) ColumnI
 	GetColumnByTitle(title string) ColumnI
 	RemoveColumn(loc int)
@@ -106,6 +109,7 @@ type FooterRowAttributer interface {
 type Table struct {
 	page.ControlBase
 	control2.DataManager
+	control2.Reorderable
 
 	columns               []ColumnI
 	caption               interface{}
@@ -237,6 +241,7 @@ func (t *Table) DrawingAttributes(ctx context.Context) html5tag.Attributes {
 	if !t.HasData() && t.hideIfEmpty {
 		a.SetStyle("display", "none")
 	}
+	t.Reorderable.ReorderableAttributes(a, ":scope > tbody > tr")
 	return a
 }
 
@@ -254,8 +259,11 @@ func (t *Table) DrawInnerHtml(ctx context.Context, w io.Writer) {
 
 	t.DrawColumnTags(ctx, buf1)
 
-	if t.headerRowCount > 0 {
+	if t.headerRowCount > 0 || t.HasFilters() {
 		t.DrawHeaderRows(ctx, buf2)
+		if t.HasFilters() {
+			t.DrawFilterRow(ctx, buf2)
+		}
 		page.WriteString(buf1, html5tag.RenderTag("thead", nil, buf2.String()))
 		buf2.Reset()
 	}
@@ -266,14 +274,39 @@ func (t *Table) DrawInnerHtml(ctx context.Context, w io.Writer) {
 		buf2.Reset()
 	}
 
+	var count int
+	t.ResetDrawnItems()
 	t.RangeData(func(index int, value interface{}) bool {
 		t.this().DrawRow(ctx, index, value, buf2)
+		count++
 		return true
 	})
 
+	if p, ok := t.Self().(control2.PagedControlI); ok && p.IsVirtualScroll() {
+		// Stand in for the rows that are not drawn so that the scroll bar reflects the entire table
+		top, bottom := p.ScrollSpacerHeights(count)
+		rows := t.spacerRow(top) + buf2.String() + t.spacerRow(bottom)
+		page.WriteString(buf1, html5tag.RenderTag("tbody", nil, rows))
+		return
+	}
+
 	page.WriteString(buf1, html5tag.RenderTag("tbody", nil, buf2.String()))
 }
 
+// spacerRow returns an empty row of the given height in pixels.
+func (t *Table) spacerRow(height int) string {
+	if height <= 0 {
+		return ""
+	}
+	var colCount int
+	for _, col := range t.columns {
+		if !col.IsHidden() {
+			colCount++
+		}
+	}
+	return fmt.Sprintf(`<tr class="gr-spacer" aria-hidden="true" style="height:%dpx"><td colspan="%d" style="padding:0;border:0"></td></tr>`, height, colCount)
+}
+
 // DrawCaption is called internally to draw the caption. Subclasses can override this to draw a custom caption.
 func (t *Table) DrawCaption(ctx context.Context, w io.Writer) {
 	switch obj := t.caption.(type) {
@@ -378,8 +411,16 @@ func (t *Table) FooterRowAttributes(row int) html5tag.Attributes {
 
 // DrawRow is called by the framework to draw a row of the table.
 func (t *Table) DrawRow(ctx context.Context, row int, data interface{}, w io.Writer) {
+	a := t.this().RowAttributes(row, data)
+	if t.IsReorderable() {
+		id := a.DataAttribute("id")
+		if id == "" {
+			id = a.ID()
+		}
+		t.AddDrawnItem(id)
+	}
 	page.WriteString(w, "<tr ")
-	page.WriteString(w, t.this().RowAttributes(row, data).String())
+	page.WriteString(w, a.String())
 	page.WriteString(w, ">")
 	for i, col := range t.columns {
 		col.DrawCell(ctx, row, i, data, w)
@@ -389,11 +430,35 @@ func (t *Table) DrawRow(ctx context.Context, row int, data interface{}, w io.Wri
 }
 
 // RowAttributes is used internally to return the attributes for the tr tag of a data row.
+// If the table is reorderable, the row is given a data-id attribute from the data, if the row styler did not give it one.
 func (t *Table) RowAttributes(row int, data interface{}) html5tag.Attributes {
+	var a html5tag.Attributes
 	if t.rowStyler != nil {
-		return t.rowStyler.RowAttributes(row, data)
+		a = t.rowStyler.RowAttributes(row, data)
 	}
-	return nil
+	if !t.IsReorderable() {
+		return a
+	}
+	if a == nil {
+		a = html5tag.NewAttributes()
+	}
+	if !a.HasDataAttribute("id") {
+		if id := rowDataID(data); id != "" {
+			a.SetData("id", id)
+		}
+	}
+	return a
+}
+
+// SetReorderable lets the user reorder the rows of the table. The id of each row comes from the data-id attribute
+// that the row styler gives it, or from the data of the row, the same way as a SelectTable.
+// See control.Reorderable.
+func (t *Table) SetReorderable(reorderable bool) {
+	if reorderable && !t.IsReorderable() {
+		control2.AddReorderableScript(t)
+	}
+	t.Reorderable.SetReorderable(reorderable)
+	t.Refresh()
 }
 
 // AddColumnAt adds the given column at the column offset given. 0 is the first column location.
@@ -412,6 +477,9 @@ func (t *Table) AddColumnAt(column ColumnI, loc int) {
 		t.columns[loc] = column
 	}
 	column.AddActions(t)
+	if m, ok := column.(filterControlMaker); ok {
+		m.makeFilterControls()
+	}
 
 	t.Refresh()
 }
@@ -427,6 +495,11 @@ func (t *Table) GetColumn(loc int) ColumnI {
 	return t.columns[loc]
 }
 
+// Columns returns the columns of the table, including hidden columns.
+func (t *Table) Columns() []ColumnI {
+	return t.columns
+}
+
 // GetColumnByID returns the column with the given id.
 func (t *Table) GetColumnByID(id string) ColumnI {
 	for _, col := range t.columns {
@@ -530,6 +603,7 @@ func (t *Table) UpdateFormValues(ctx context.Context) {
 	for _, col := range t.columns {
 		col.UpdateFormValues(ctx)
 	}
+	t.Reorderable.UpdateItemOrder(ctx, t.ID())
 }
 
 // DoPrivateAction is called by the framework to allow controls to process actions internal to themselves.
@@ -553,6 +627,8 @@ func (t *Table) DoPrivateAction(ctx context.Context, p action.Params) {
 	case SortClick:
 		t.sortClick(p.EventValueString())
 		t.Refresh()
+	case FilterChange:
+		t.filterChanged(p)
 	default:
 		if par := t.Parent(); par != nil {
 			par.DoPrivateAction(ctx, p)
@@ -702,6 +778,9 @@ func (t *Table) SortIconHtml(c ColumnI) string {
 // MarshalState is an internal function to save the state of the control
 func (t *Table) MarshalState(m page.SavedState) {
 	m.Set("sortColumns", t.sortColumns)
+	if f := t.filterState(); f != nil {
+		m.Set("filters", f)
+	}
 	for _, col := range t.columns {
 		col.MarshalState(m)
 	}
@@ -714,6 +793,11 @@ func (t *Table) UnmarshalState(m page.SavedState) {
 			t.sortColumns = s
 		}
 	}
+	if v, ok := m.Load("filters"); ok {
+		if f, ok2 := v.(map[string][]string); ok2 {
+			t.setFilterState(f)
+		}
+	}
 	for _, col := range t.columns {
 		col.UnmarshalState(m)
 	}
@@ -741,6 +825,7 @@ type tableEncoded struct {
 func (t *Table) Serialize(e page.Encoder) {
 	t.ControlBase.Serialize(e)
 	t.DataManager.Serialize(e)
+	t.Reorderable.Serialize(e)
 
 	s := tableEncoded{
 		HideIfEmpty:      t.hideIfEmpty,
@@ -795,6 +880,7 @@ func (t *Table) Serialize(e page.Encoder) {
 func (t *Table) Deserialize(dec page.Decoder) {
 	t.ControlBase.Deserialize(dec)
 	t.DataManager.Deserialize(dec)
+	t.Reorderable.Deserialize(dec)
 
 	var s tableEncoded
 
@@ -911,7 +997,9 @@ type TableCreator struct {
 	// SortColumnIDs is a list of column ids that will be used to specify the initial sort order
 	SortColumnIDs []string
 	// OnCellClick is the action to take when a cell is clicked.
-	OnCellClick    action.ActionI
+	OnCellClick action.ActionI
+	// Reorderable lets the user reorder the rows. See Table.SetReorderable.
+	Reorderable    bool
 	ControlOptions page.ControlOptions
 }
 
@@ -991,6 +1079,10 @@ func (c TableCreator) Init(ctx context.Context, ctrl TableI) {
 		ctrl.On(event.CellClick(), c.OnCellClick)
 	}
 
+	if c.Reorderable {
+		ctrl.SetReorderable(true)
+	}
+
 	ctrl.ApplyOptions(ctx, c.ControlOptions)
 }
 
@@ -0,0 +1,221 @@
+package textbox
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+	"github.com/goradd/goradd/pkg/page"
+	"github.com/shopspring/decimal"
+)
+
+type DecimalI interface {
+	TextboxI
+	SetMinValue(minValue decimal.Decimal, invalidMessage string) DecimalI
+	SetMaxValue(maxValue decimal.Decimal, invalidMessage string) DecimalI
+}
+
+// DecimalTextbox is a textbox control that expects an exact decimal value, like an amount of money, and does server-side
+// validation on min and max values. Unlike the FloatTextbox, the value is never converted to a binary floating-point
+// number, so no precision is lost. It sets the inputmode to "decimal" to inform mobile browsers to
+// expect decimal input.
+type DecimalTextbox struct {
+	Textbox
+}
+
+func NewDecimalTextbox(parent page.ControlI, id string) *DecimalTextbox {
+	t := &DecimalTextbox{}
+	t.Init(t, parent, id)
+	return t
+}
+
+// Init is called by the framework, and subclasses of the DecimalTextbox.
+func (t *DecimalTextbox) Init(self any, parent page.ControlI, id string) {
+	t.Textbox.Init(self, parent, id)
+	t.ValidateWith(DecimalValidator{})
+	t.SetAttribute("inputmode", "decimal") // set inputmode for mobile input, but do it here so programmer could cancel this if desired.
+}
+
+func (t *DecimalTextbox) this() DecimalI {
+	return t.Self().(DecimalI)
+}
+
+func (t *DecimalTextbox) SetMinValue(minValue decimal.Decimal, invalidMessage string) DecimalI {
+	t.ValidateWith(MinDecimalValidator{minValue, invalidMessage})
+	return t.this()
+}
+
+func (t *DecimalTextbox) SetMaxValue(maxValue decimal.Decimal, invalidMessage string) DecimalI {
+	t.ValidateWith(MaxDecimalValidator{maxValue, invalidMessage})
+	return t.this()
+}
+
+func (t *DecimalTextbox) Value() interface{} {
+	return t.Decimal()
+}
+
+// Decimal returns the value as a decimal.Decimal. If the text is not a number, zero is returned.
+func (t *DecimalTextbox) Decimal() decimal.Decimal {
+	v, _ := decimal.NewFromString(t.Textbox.Text())
+	return v
+}
+
+// SetDecimal sets the value of the textbox to the given decimal.
+func (t *DecimalTextbox) SetDecimal(v decimal.Decimal) *DecimalTextbox {
+	t.Textbox.SetText(v.String())
+	return t
+}
+
+// SetValue sets the value of the textbox. v can be a decimal.Decimal, or anything that can be converted to
+// text, like a string or number.
+func (t *DecimalTextbox) SetValue(v interface{}) page.ControlI {
+	if d, ok := v.(decimal.Decimal); ok {
+		t.Textbox.SetText(d.String())
+	} else {
+		t.Textbox.SetValue(v)
+	}
+	return t.this()
+}
+
+type DecimalValidator struct {
+	Message string
+}
+
+func (v DecimalValidator) Validate(c page.ControlI, s string) (msg string) {
+	if s == "" {
+		return "" // empty textbox is checked elsewhere
+	}
+	if _, err := decimal.NewFromString(s); err != nil {
+		if v.Message == "" {
+			return c.GT("Please enter a number.")
+		} else {
+			return v.Message
+		}
+	}
+	return
+}
+
+type MinDecimalValidator struct {
+	MinValue decimal.Decimal
+	Message  string
+}
+
+func (v MinDecimalValidator) Validate(c page.ControlI, s string) (msg string) {
+	if s == "" {
+		return "" // empty textbox is checked elsewhere
+	}
+	if val, err := decimal.NewFromString(s); err == nil && val.LessThan(v.MinValue) {
+		if v.Message == "" {
+			return fmt.Sprintf(c.GT("Enter at least %s"), v.MinValue)
+		} else {
+			return v.Message
+		}
+	}
+	return
+}
+
+type MaxDecimalValidator struct {
+	MaxValue decimal.Decimal
+	Message  string
+}
+
+func (v MaxDecimalValidator) Validate(c page.ControlI, s string) (msg string) {
+	if s == "" {
+		return "" // empty textbox is checked elsewhere
+	}
+	if val, err := decimal.NewFromString(s); err == nil && val.GreaterThan(v.MaxValue) {
+		if v.Message == "" {
+			return fmt.Sprintf(c.GT("Enter at most %s"), v.MaxValue)
+		} else {
+			return v.Message
+		}
+	}
+	return
+}
+
+type DecimalLimit struct {
+	Value          decimal.Decimal
+	InvalidMessage string
+}
+
+// DecimalTextboxCreator creates a textbox that only accepts decimal numbers.
+// Pass it to AddControls of a control, or as a Child of
+// a FormFieldWrapper.
+type DecimalTextboxCreator struct {
+	// ID is the control id of the html widget and must be unique to the page
+	ID string
+	// Placeholder is the placeholder attribute of the textbox and shows as help text inside the field
+	Placeholder string
+	// Type is the type attribute of the textbox
+	Type string
+	// MinLength is the minimum number of characters that the user is required to enter. If the
+	// length is less than this number, a validation error will be shown.
+	MinLength int
+	// MaxLength is the maximum number of characters that the user is required to enter. If the
+	// length is more than this number, a validation error will be shown.
+	MaxLength int
+	// ColumnCount is the number of characters wide the textbox will be, and becomes the width attribute in the tag.
+	// The actual width is browser dependent. For better control, use a width style property.
+	ColumnCount int
+	// ReadOnly sets the readonly attribute of the textbox, which prevents it from being changed by the user.
+	ReadOnly bool
+	// SaveState will save the text in the textbox, to be restored if the user comes back to the page.
+	SaveState bool
+	// MinValue is the minimum value the user can enter. If the user does not
+	// enter at least this amount, or enters something that is not a number, it will fail validation
+	// and the FormFieldWrapper will show an error.
+	MinValue *DecimalLimit
+	// MaxValue is the maximum value the user can enter. If the user enter more
+	// than this amount, or enters something that is not a number, it will fail validation
+	// and the FormFieldWrapper will show an error.
+	MaxValue *DecimalLimit
+	// Value is the initial value of the textbox. Often its best to load the value in a separate Load step after creating the control.
+	Value interface{}
+
+	page.ControlOptions
+}
+
+// Create is called by the framework to create a new control from the Creator. You
+// do not normally need to call this.
+func (c DecimalTextboxCreator) Create(ctx context.Context, parent page.ControlI) page.ControlI {
+	ctrl := NewDecimalTextbox(parent, c.ID)
+	c.Init(ctx, ctrl)
+	return ctrl
+}
+
+// Init is called by implementations of Textboxes to initialize a control with the
+// creator.
+func (c DecimalTextboxCreator) Init(ctx context.Context, ctrl DecimalI) {
+	if c.MinValue != nil {
+		ctrl.SetMinValue(c.MinValue.Value, c.MinValue.InvalidMessage)
+	}
+	if c.MaxValue != nil {
+		ctrl.SetMaxValue(c.MaxValue.Value, c.MaxValue.InvalidMessage)
+	}
+	if c.Value != nil {
+		ctrl.SetValue(c.Value)
+	}
+	// Reuse subclass
+	sub := TextboxCreator{
+		Placeholder:    c.Placeholder,
+		Type:           c.Type,
+		MinLength:      c.MinLength,
+		MaxLength:      c.MaxLength,
+		ColumnCount:    c.ColumnCount,
+		ReadOnly:       c.ReadOnly,
+		ControlOptions: c.ControlOptions,
+		SaveState:      c.SaveState,
+	}
+	sub.Init(ctx, ctrl)
+}
+
+// GetDecimalTextbox is a convenience method to return the control with the given id from the page.
+func GetDecimalTextbox(c page.ControlI, id string) *DecimalTextbox {
+	return c.Page().GetControl(id).(*DecimalTextbox)
+}
+
+func init() {
+	gob.Register(MaxDecimalValidator{})
+	gob.Register(MinDecimalValidator{})
+	gob.Register(DecimalValidator{})
+	page.RegisterControl(&DecimalTextbox{})
+}
@@ -0,0 +1,27 @@
+package textbox
+
+import (
+	"testing"
+
+	"github.com/goradd/goradd/pkg/page"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecimalTextbox(t *testing.T) {
+	p := page.NewMockForm()
+
+	d := NewDecimalTextbox(p, "")
+	d.SetMinValue(decimal.RequireFromString("0.01"), "")
+	d.SetMaxValue(decimal.RequireFromString("100"), "")
+
+	d.SetValue(decimal.RequireFromString("10.10"))
+	assert.Equal(t, "10.1", d.Text())
+
+	assert.True(t, d.MockFormValue("0.10"))
+	assert.True(t, decimal.RequireFromString("0.1").Equal(d.Decimal()))
+
+	assert.False(t, d.MockFormValue("abc"))
+	assert.False(t, d.MockFormValue("0.001"))
+	assert.False(t, d.MockFormValue("100.01"))
+}
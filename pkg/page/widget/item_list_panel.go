@@ -85,7 +85,11 @@ func (c *ItemListPanel) DrawingAttributes(ctx context.Context) html5tag.Attribut
 func (f *ItemListPanel) DoAction(ctx context.Context, a action.Params) {
 	switch a.ID {
 	case filterChanged:
-		f.ItemTable.Refresh() // TODO: Change this to some kind of data only refresh so that when control is redrawn the scroll position is maintained
+		// Show the filtered items from the top, and then bring the selected item into view if it is still in the list.
+		// The commands run after the table is redrawn.
+		f.ItemTable.Refresh()
+		f.ParentForm().Response().ExecuteControlCommand(f.ScrollPanel.ID(), "prop", "scrollTop", 0, page.PriorityLow)
+		f.ItemTable.ExecuteWidgetFunction("showSelectedItem", page.PriorityLow)
 	default:
 		f.Panel.DoAction(ctx, a)
 	}
@@ -142,6 +142,10 @@ func (a *Application) SetupPaths() {
 		http2.RegisterPrefixHandler("/debug/pprof/trace", http.HandlerFunc(pprof.Trace))
 	}
 
+	if !config.Release {
+		http2.RegisterAppHandler(DbProfilePath, http.HandlerFunc(serveDbProfiles))
+	}
+
 	if config.WebsocketMessengerPrefix != "" {
 		http2.RegisterPrefixHandler(config.WebsocketMessengerPrefix, http.HandlerFunc(WebsocketMessengerHandler))
 	}
@@ -181,6 +185,7 @@ func (a *Application) MakeAppServer() http.Handler {
 	h = a.this().ServeAppMux(h)         // Serves other dynamic files, and possibly the api
 	h = a.ServePageHandler(h)           // Serves the Goradd dynamic pages
 	h = a.PutAppContextHandler(h)
+	h = a.DbProfileHandler(h) // Must be after the session handler, since it saves profiles to the session
 	h = a.this().SessionHandler(h)
 	h = a.BufferedOutputHandler(h) // Must be in front of the session handler
 	h = a.StatsHandler(h)
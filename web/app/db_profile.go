@@ -0,0 +1,129 @@
+package app
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+	"github.com/goradd/goradd/pkg/config"
+	"github.com/goradd/goradd/pkg/orm/db"
+	"github.com/goradd/goradd/pkg/orm/db/sql"
+	"github.com/goradd/goradd/pkg/session"
+	"html"
+	"net/http"
+	"time"
+)
+
+// DbProfilePath is the path of the development page that lists the database profiles saved in the session.
+const DbProfilePath = "/goradd/dbprofile"
+
+const dbProfileSessionKey = "goradd.dbProfiles"
+
+// maxDbProfileRequests is the number of requests whose profiles are kept in the session.
+const maxDbProfileRequests = 50
+
+// DbRequestProfile is the database profile of one request.
+type DbRequestProfile struct {
+	Request  string
+	Time     time.Time
+	Profiles []sql.ProfileEntry
+}
+
+type profiler interface {
+	GetProfiles(ctx context.Context) []sql.ProfileEntry
+}
+
+// DbProfileHandler saves the queries profiled during each request to the session, so that the queries of ajax
+// requests can be seen too. Turn on profiling of a database by calling StartProfiling on it, and view the profiles
+// at DbProfilePath. It does nothing in the release build.
+func (a *Application) DbProfileHandler(next http.Handler) http.Handler {
+	if config.Release {
+		return next
+	}
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+		if r.URL.Path == DbProfilePath {
+			return
+		}
+		ctx := r.Context()
+		if !session.HasSession(ctx) {
+			return
+		}
+		p := DbRequestProfile{
+			Request: r.Method + " " + r.URL.Path,
+			Time:    time.Now(),
+		}
+		for _, d := range db.GetDatabases() {
+			if d2, ok := d.(profiler); ok {
+				p.Profiles = append(p.Profiles, d2.GetProfiles(ctx)...)
+			}
+		}
+		if len(p.Profiles) == 0 {
+			return
+		}
+		profiles, _ := session.Get(ctx, dbProfileSessionKey).([]DbRequestProfile)
+		profiles = append(profiles, p)
+		if len(profiles) > maxDbProfileRequests {
+			profiles = profiles[len(profiles)-maxDbProfileRequests:]
+		}
+		session.Set(ctx, dbProfileSessionKey, profiles)
+	}
+	return http.HandlerFunc(fn)
+}
+
+// serveDbProfiles draws the page that lists the database profiles saved in the session, most recent first.
+func serveDbProfiles(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if r.FormValue("clear") != "" {
+		session.Remove(ctx, dbProfileSessionKey)
+		http.Redirect(w, r, DbProfilePath, http.StatusSeeOther)
+		return
+	}
+	profiles, _ := session.Get(ctx, dbProfileSessionKey).([]DbRequestProfile)
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = fmt.Fprint(w, `<!DOCTYPE html>
+<html><head><title>Database Profiles</title>
+<style>
+table {border-collapse: collapse; margin-bottom: 1em;}
+td, th {border: 1px solid #ccc; padding: 4px; vertical-align: top; text-align: left;}
+pre {margin: 0; white-space: pre-wrap;}
+.slow {background-color: #fdd;}
+.repeated {background-color: #ffd;}
+</style></head><body>
+<h1>Database Profiles</h1>
+<p><a href="?clear=1">Clear</a></p>
+`)
+	if len(profiles) == 0 {
+		_, _ = fmt.Fprint(w, "<p>No queries have been profiled. Call StartProfiling on a database to profile it.</p>\n")
+	}
+	for i := len(profiles) - 1; i >= 0; i-- {
+		p := profiles[i]
+		_, _ = fmt.Fprintf(w, "<h2>%s %s</h2>\n", p.Time.Format("15:04:05.000"), html.EscapeString(p.Request))
+		_, _ = fmt.Fprint(w, "<table><tr><th>Database</th><th>Type</th><th>Time</th><th>Repeats</th><th>Sql</th></tr>\n")
+		for _, e := range p.Profiles {
+			var class string
+			if e.Slow {
+				class = "slow"
+			} else if e.Repeats > 1 {
+				class = "repeated"
+			}
+			_, _ = fmt.Fprintf(w, `<tr class="%s"><td>%s</td><td>%s</td><td>%v</td><td>%d</td><td><pre>%s</pre>`,
+				class,
+				html.EscapeString(e.DbKey),
+				e.Typ,
+				e.EndTime.Sub(e.BeginTime),
+				e.Repeats,
+				html.EscapeString(e.Sql))
+			if e.Explain != "" {
+				_, _ = fmt.Fprintf(w, "<p>Explain:</p><pre>%s</pre>", html.EscapeString(e.Explain))
+			}
+			_, _ = fmt.Fprint(w, "</td></tr>\n")
+		}
+		_, _ = fmt.Fprint(w, "</table>\n")
+	}
+	_, _ = fmt.Fprint(w, "</body></html>\n")
+}
+
+func init() {
+	gob.Register([]DbRequestProfile{})
+}
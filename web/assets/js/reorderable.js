@@ -0,0 +1,155 @@
+/**
+ * Widget script designed to be attached to a list, repeater or table whose items the user can reorder.
+ *
+ * The user drags an item to a new place among its siblings, or focuses an item and presses Alt+Up or Alt+Down
+ * to move it one place. After a move, the ids of the items in their new order are sent to the control as its
+ * "order" value, and the gr-reorder event is triggered with the ids as its value. The id of an item is its data-id
+ * attribute, or its id attribute if it has no data-id.
+ */
+
+(function() {
+    // The item the user last moved with the keyboard, by control id, so that it can get the focus back if the control is redrawn
+    var moved = {};
+
+    goradd.Reorderable = goradd.extendWidget({
+        constructor: function(element, options) {
+            var optionDefaults = {
+                items: ":scope > *" // selector of the items that can be moved
+            };
+            options = goradd.extendOptions(optionDefaults, options);
+            this._super(element, options);
+            this._dragged = null;
+            this._startOrder = null;
+
+            this._items().forEach(function(item) {
+                item.setAttribute("draggable", "true");
+                if (!item.hasAttribute("tabindex")) {
+                    item.setAttribute("tabindex", "0");
+                }
+                item.setAttribute("aria-keyshortcuts", "Alt+ArrowUp Alt+ArrowDown");
+            });
+            this.on("dragstart", this._handleDragStart);
+            this.on("dragover", this._handleDragOver);
+            this.on("drop", this._handleDrop);
+            this.on("dragend", this._handleDragEnd);
+            this.on("keydown", this._handleKeyDown);
+            this._restoreFocus();
+        },
+        _restoreFocus: function() {
+            var m = moved[this.element.id];
+            delete moved[this.element.id];
+            if (!m || Date.now() - m.time > 5000 ||
+                (document.activeElement && document.activeElement !== document.body)) {
+                return;
+            }
+            var self = this;
+            this._items().some(function(item) {
+                if (self._itemId(item) === m.id) {
+                    item.focus();
+                    return true;
+                }
+                return false;
+            });
+        },
+        _items: function() {
+            var items = this.element.querySelectorAll(this.options.items);
+            return Array.prototype.filter.call(items, function(item) {
+                return !item.classList.contains("gr-spacer");
+            });
+        },
+        _itemFor: function(el) {
+            var items = this._items();
+            while (el && el !== this.element) {
+                if (items.indexOf(el) >= 0) {
+                    return el;
+                }
+                el = el.parentElement;
+            }
+            return null;
+        },
+        _itemId: function(item) {
+            return item.getAttribute("data-id") || item.id;
+        },
+        _order: function() {
+            return this._items().map(this._itemId);
+        },
+        _handleDragStart: function(e) {
+            var item = this._itemFor(e.target);
+            if (!item) {
+                return;
+            }
+            this._dragged = item;
+            this._startOrder = this._order().join("\n");
+            e.dataTransfer.effectAllowed = "move";
+            e.dataTransfer.setData("text/plain", this._itemId(item)); // some browsers will not drag without data
+            item.classList.add("gr-dragging");
+        },
+        _handleDragOver: function(e) {
+            var dragged = this._dragged;
+            if (!dragged) {
+                return;
+            }
+            e.preventDefault(); // allows the drop
+            e.dataTransfer.dropEffect = "move";
+            var item = this._itemFor(e.target);
+            if (!item || item === dragged || item.parentNode !== dragged.parentNode) {
+                return;
+            }
+            var rect = item.getBoundingClientRect();
+            if (e.clientY > rect.top + rect.height / 2) {
+                item.parentNode.insertBefore(dragged, item.nextSibling);
+            } else {
+                item.parentNode.insertBefore(dragged, item);
+            }
+        },
+        _handleDrop: function(e) {
+            if (this._dragged) {
+                e.preventDefault();
+            }
+        },
+        _handleDragEnd: function() {
+            var dragged = this._dragged;
+            if (!dragged) {
+                return;
+            }
+            this._dragged = null;
+            dragged.classList.remove("gr-dragging");
+            if (this._order().join("\n") !== this._startOrder) {
+                this._changed();
+            }
+        },
+        _handleKeyDown: function(e) {
+            if (!e.altKey || (e.keyCode !== 38 && e.keyCode !== 40)) {
+                return;
+            }
+            var item = this._itemFor(e.target);
+            if (!item) {
+                return;
+            }
+            var items = this._items();
+            var sibling = item;
+            do {
+                sibling = (e.keyCode === 38) ? sibling.previousElementSibling : sibling.nextElementSibling;
+            } while (sibling && items.indexOf(sibling) < 0);
+            e.preventDefault();
+            if (!sibling) {
+                return;
+            }
+            if (e.keyCode === 38) { // up
+                item.parentNode.insertBefore(item, sibling);
+            } else { // down
+                item.parentNode.insertBefore(item, sibling.nextSibling);
+            }
+            item.focus(); // moving the item can lose the focus
+            moved[this.element.id] = {id: this._itemId(item), time: Date.now()};
+            this._changed();
+        },
+        _changed: function() {
+            var ids = this._order();
+            goradd.setControlValue(this.element.id, "order", ids);
+            this.trigger("gr-reorder", ids);
+        }
+    });
+
+    goradd.registerWidget("goradd.Reorderable", goradd.Reorderable);
+})();
@@ -0,0 +1,123 @@
+/**
+ * Widget script designed to be attached to a table or repeater that uses virtual scrolling.
+ *
+ * The control draws only a window of its items, and stands in for the rest with spacers. This widget
+ * reports the scroll position to the control, and triggers the gr-vscroll event when the user scrolls close
+ * to the edge of the window, so that the control can draw a new window. It also restores the scroll position
+ * when the scrolling container is redrawn.
+ */
+
+(function() {
+    var listeners = {}; // scroll listeners by control id, so that a redrawn control replaces its old listener
+
+    goradd.VirtualScroll = goradd.extendWidget({
+        constructor: function(element, options) {
+            var optionDefaults = {
+                rowHeight: 0,
+                firstRow: 0,
+                rowCount: 0,
+                totalItems: 0,
+                scrollTop: 0
+            };
+            options = goradd.extendOptions(optionDefaults, options);
+            this._super(element, options);
+            this._timer = null;
+            this._scroller = this._findScroller();
+
+            var self = this;
+            var prev = listeners[this.id];
+            if (prev) {
+                prev.target.removeEventListener("scroll", prev.handler);
+            }
+            var target = this._isWindow() ? window : this._scroller;
+            var handler = function() {
+                self._handleScroll();
+            };
+            target.addEventListener("scroll", handler, {passive: true});
+            listeners[this.id] = {target: target, handler: handler};
+
+            var top = parseInt(this.options.scrollTop) || 0;
+            if (top > 0 && this._scroller.scrollTop === 0) {
+                // The scrolling container was redrawn, so put it back where it was
+                this._scroller.scrollTop = top;
+            }
+        },
+        /**
+         * scrollToStart scrolls the first item into view, if it is scrolled above the visible area.
+         */
+        scrollToStart: function() {
+            var top = this.element.getBoundingClientRect().top;
+            if (!this._isWindow()) {
+                top -= this._scroller.getBoundingClientRect().top;
+            }
+            if (top < 0) {
+                this._scroller.scrollTop += top;
+            }
+        },
+        _findScroller: function() {
+            var el = this.element;
+            while (el && el !== document.body && el !== document.documentElement) {
+                var overflow = window.getComputedStyle(el).overflowY;
+                if (overflow === "auto" || overflow === "scroll") {
+                    return el;
+                }
+                el = el.parentElement;
+            }
+            return document.scrollingElement || document.documentElement;
+        },
+        _isWindow: function() {
+            return this._scroller === document.scrollingElement || this._scroller === document.documentElement;
+        },
+        _itemArea: function() {
+            if (this.element.tagName === "TABLE" && this.element.tBodies.length) {
+                return this.element.tBodies[0];
+            }
+            return this.element;
+        },
+        _handleScroll: function() {
+            var self = this;
+            if (this._timer) {
+                clearTimeout(this._timer);
+            }
+            this._timer = setTimeout(function() {
+                self._timer = null;
+                self._checkWindow();
+            }, 100);
+        },
+        _checkWindow: function() {
+            if (!document.body.contains(this.element)) {
+                return;
+            }
+            var scroller = this._scroller;
+            var viewTop, height;
+            if (this._isWindow()) {
+                viewTop = 0;
+                height = window.innerHeight;
+            } else {
+                viewTop = scroller.getBoundingClientRect().top + scroller.clientTop;
+                height = scroller.clientHeight;
+            }
+            goradd.setControlValue(this.element.id, "scrollTop", Math.round(scroller.scrollTop));
+
+            // top is the distance from the first item to the top of the visible area
+            var top = Math.round(viewTop - this._itemArea().getBoundingClientRect().top);
+            var rowHeight = parseInt(this.options.rowHeight) || 0;
+            if (rowHeight <= 0) {
+                return;
+            }
+            var totalItems = parseInt(this.options.totalItems) || 0;
+            var firstRow = parseInt(this.options.firstRow) || 0;
+            var lastRow = Math.min(firstRow + (parseInt(this.options.rowCount) || 0), totalItems);
+            var firstVisible = Math.max(Math.floor(top / rowHeight), 0);
+            var lastVisible = Math.ceil((top + height) / rowHeight);
+            var margin = Math.max(Math.floor((lastRow - firstRow - (lastVisible - firstVisible)) / 4), 0);
+
+            if ((firstRow > 0 && firstVisible < firstRow + margin) ||
+                (lastRow < totalItems && lastVisible > lastRow - margin)) {
+                this.trigger("gr-vscroll", {top: Math.max(top, 0), height: height});
+            }
+        }
+    });
+
+    goradd.registerWidget("goradd.VirtualScroll", goradd.VirtualScroll);
+})();
@@ -0,0 +1,235 @@
+-- SQLite version of the goradd example database
+--
+-- Load it with:
+--   sqlite3 goradd.db < sqlite.goradd.sql
+
+PRAGMA foreign_keys = OFF;
+
+CREATE TABLE "person" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "first_name" VARCHAR(50) NOT NULL,
+    "last_name" VARCHAR(50) NOT NULL
+);
+CREATE INDEX "IDX_person_1" ON "person" ("last_name");
+
+INSERT INTO "person" ("id", "first_name", "last_name") VALUES
+    (1, 'John', 'Doe'),
+    (2, 'Kendall', 'Public'),
+    (3, 'Ben', 'Robinson'),
+    (4, 'Mike', 'Ho'),
+    (5, 'Alex', 'Smith'),
+    (6, 'Wendy', 'Smith'),
+    (7, 'Karen', 'Wolfe'),
+    (8, 'Samantha', 'Jones'),
+    (9, 'Linda', 'Brady'),
+    (10, 'Jennifer', 'Smith'),
+    (11, 'Brett', 'Carlisle'),
+    (12, 'Jacob', 'Pratt');
+
+CREATE TABLE "person_type_enum" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "name" VARCHAR(50) NOT NULL
+);
+CREATE UNIQUE INDEX "person_type_enum_name" ON "person_type_enum" ("name");
+
+INSERT INTO "person_type_enum" ("id", "name") VALUES
+    (4, 'Company Car'),
+    (1, 'Contractor'),
+    (3, 'Inactive'),
+    (2, 'Manager'),
+    (5, 'Works From Home');
+
+CREATE TABLE "project_status_enum" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "name" VARCHAR(50) NOT NULL,
+    "description" TEXT DEFAULT NULL,
+    "guidelines" TEXT DEFAULT NULL,
+    "is_active" BOOLEAN NOT NULL
+);
+CREATE UNIQUE INDEX "IDX_projectstatustype_1" ON "project_status_enum" ("name");
+
+INSERT INTO "project_status_enum" ("id", "name", "description", "guidelines", "is_active") VALUES
+    (1, 'Open', 'The project is currently active', 'All projects that we are working on should be in this state', 1),
+    (2, 'Cancelled', 'The project has been canned', NULL, 1),
+    (3, 'Completed', 'The project has been completed successfully', 'Celebrate successes!', 1),
+    (4, 'Planned', 'Project is in the planning stages and has not been assigned a manager', 'Get ready', 0);
+
+CREATE TABLE "address" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "person_id" INT NOT NULL REFERENCES "person" ("id") ON DELETE CASCADE ON UPDATE CASCADE,
+    "street" VARCHAR(100) NOT NULL,
+    "city" VARCHAR(100) DEFAULT 'BOB'
+);
+CREATE INDEX "IDX_address_1" ON "address" ("person_id");
+
+INSERT INTO "address" ("id", "person_id", "street", "city") VALUES
+    (1, 1, '1 Love Drive', NULL),
+    (2, 2, '2 Doves and a Pine Cone Dr.', 'Dallas'),
+    (3, 3, '3 Gold Fish Pl.', 'New York'),
+    (4, 3, '323 W QCubed', 'New York'),
+    (5, 5, '22 Elm St', 'Palo Alto'),
+    (6, 7, '1 Pine St', 'San Jose'),
+    (7, 7, '421 Central Expw', 'Mountain View');
+
+CREATE TABLE "employee_info" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "person_id" INT NOT NULL REFERENCES "person" ("id") ON DELETE CASCADE ON UPDATE CASCADE,
+    "employee_number" INT NOT NULL
+);
+CREATE UNIQUE INDEX "employee_info_person_id" ON "employee_info" ("person_id");
+
+CREATE TABLE "gift" ( -- Table is keyed with an integer, but does not auto-increment
+    "number" INT NOT NULL PRIMARY KEY,
+    "name" VARCHAR(50) NOT NULL
+);
+
+INSERT INTO "gift" ("number", "name") VALUES
+    (1, 'Partridge in a pear tree'),
+    (2, 'Turtle doves'),
+    (3, 'French hens');
+
+CREATE TABLE "login" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "person_id" INT DEFAULT NULL REFERENCES "person" ("id") ON DELETE CASCADE ON UPDATE CASCADE,
+    "username" VARCHAR(20) NOT NULL,
+    "password" VARCHAR(20) DEFAULT NULL,
+    "is_enabled" BOOLEAN NOT NULL DEFAULT 1
+);
+CREATE UNIQUE INDEX "IDX_login_2" ON "login" ("username");
+CREATE UNIQUE INDEX "IDX_login_1" ON "login" ("person_id");
+
+INSERT INTO "login" ("id", "person_id", "username", "password", "is_enabled") VALUES
+    (1, 1, 'jdoe', 'p@$$.w0rd', 0),
+    (2, 3, 'brobinson', 'p@$$.w0rd', 1),
+    (3, 4, 'mho', 'p@$$.w0rd', 1),
+    (4, 7, 'kwolfe', 'p@$$.w0rd', 0),
+    (5, NULL, 'system', 'p@$$.w0rd', 1);
+
+CREATE TABLE "project" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "num" INT NOT NULL, -- To simplify checking test results and as a non pk id test
+    "status_id" INT NOT NULL REFERENCES "project_status_enum" ("id"),
+    "manager_id" INT DEFAULT NULL REFERENCES "person" ("id"),
+    "name" VARCHAR(100) NOT NULL,
+    "description" TEXT DEFAULT NULL,
+    "start_date" DATE DEFAULT NULL,
+    "end_date" DATE DEFAULT NULL,
+    "budget" DECIMAL(12,2) DEFAULT NULL,
+    "spent" DECIMAL(12,2) DEFAULT NULL
+);
+CREATE UNIQUE INDEX "project_num" ON "project" ("num");
+CREATE INDEX "IDX_project_1" ON "project" ("status_id");
+CREATE INDEX "IDX_project_2" ON "project" ("manager_id");
+
+INSERT INTO "project" ("id", "num", "status_id", "manager_id", "name", "description", "start_date", "end_date", "budget", "spent") VALUES
+    (1, 1, 3, 7, 'ACME Website Redesign', 'The redesign of the main website for ACME Incorporated', '2004-03-01', '2004-07-01', '9560.25', '10250.75'),
+    (2, 2, 1, 4, 'State College HR System', 'Implementation of a back-office Human Resources system for State College', '2006-02-15', NULL, '80500.00', '73200.00'),
+    (3, 3, 1, 1, 'Blueman Industrial Site Architecture', 'Main website architecture for the Blueman Industrial Group', '2006-03-01', '2006-04-15', '2500.00', '4200.50'),
+    (4, 4, 2, 7, 'ACME Payment System', 'Accounts Payable payment system for ACME Incorporated', '2005-08-15', '2005-10-20', '5124.67', '5175.30');
+
+CREATE TABLE "milestone" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "project_id" INT NOT NULL REFERENCES "project" ("id") ON DELETE CASCADE,
+    "name" VARCHAR(50) NOT NULL
+);
+CREATE INDEX "IDX_milestoneproj_1" ON "milestone" ("project_id");
+
+INSERT INTO "milestone" ("id", "project_id", "name") VALUES
+    (1, 1, 'Milestone A'),
+    (2, 1, 'Milestone B'),
+    (3, 1, 'Milestone C'),
+    (4, 2, 'Milestone D'),
+    (5, 2, 'Milestone E'),
+    (6, 3, 'Milestone F'),
+    (7, 4, 'Milestone G'),
+    (8, 4, 'Milestone H'),
+    (9, 4, 'Milestone I'),
+    (10, 4, 'Milestone J');
+
+CREATE TABLE "person_persontype_assn" (
+    "person_id" INT NOT NULL REFERENCES "person" ("id"),
+    "person_type_id" INT NOT NULL REFERENCES "person_type_enum" ("id"),
+    PRIMARY KEY ("person_id", "person_type_id")
+);
+CREATE INDEX "person_persontype_assn_person_type_id" ON "person_persontype_assn" ("person_type_id");
+
+INSERT INTO "person_persontype_assn" ("person_id", "person_type_id") VALUES
+    (1, 2),
+    (1, 3),
+    (2, 4),
+    (2, 5),
+    (3, 1),
+    (3, 2),
+    (3, 3),
+    (5, 5),
+    (7, 2),
+    (7, 4),
+    (9, 3),
+    (10, 1);
+
+CREATE TABLE "person_with_lock" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "first_name" VARCHAR(50) NOT NULL,
+    "last_name" VARCHAR(50) NOT NULL,
+    "sys_timestamp" TIMESTAMP DEFAULT NULL
+);
+
+INSERT INTO "person_with_lock" ("id", "first_name", "last_name", "sys_timestamp") VALUES
+    (1, 'John', 'Doe', NULL),
+    (2, 'Kendall', 'Public', NULL),
+    (3, 'Ben', 'Robinson', NULL),
+    (4, 'Mike', 'Ho', NULL),
+    (5, 'Alfred', 'Newman', NULL),
+    (6, 'Wendy', 'Johnson', NULL),
+    (7, 'Karen', 'Wolfe', NULL),
+    (8, 'Samantha', 'Jones', NULL),
+    (9, 'Linda', 'Brady', NULL),
+    (10, 'Jennifer', 'Smith', NULL),
+    (11, 'Brett', 'Carlisle', NULL),
+    (12, 'Jacob', 'Pratt', NULL);
+
+CREATE TABLE "related_project_assn" (
+    "parent_id" INT NOT NULL REFERENCES "project" ("id"),
+    "child_id" INT NOT NULL REFERENCES "project" ("id"),
+    PRIMARY KEY ("parent_id", "child_id")
+);
+CREATE INDEX "IDX_relatedprojectassn_2" ON "related_project_assn" ("child_id");
+
+INSERT INTO "related_project_assn" ("parent_id", "child_id") VALUES
+    (1, 3),
+    (1, 4),
+    (4, 1);
+
+CREATE TABLE "team_member_project_assn" (
+    "team_member_id" INT NOT NULL REFERENCES "person" ("id") ON DELETE CASCADE ON UPDATE CASCADE,
+    "project_id" INT NOT NULL REFERENCES "project" ("id") ON DELETE CASCADE ON UPDATE CASCADE,
+    PRIMARY KEY ("team_member_id", "project_id")
+);
+CREATE INDEX "IDX_teammemberprojectassn_2" ON "team_member_project_assn" ("project_id");
+
+INSERT INTO "team_member_project_assn" ("team_member_id", "project_id") VALUES
+    (1, 3),
+    (1, 4),
+    (2, 1),
+    (2, 2),
+    (2, 4),
+    (3, 4),
+    (4, 2),
+    (4, 3),
+    (5, 1),
+    (5, 2),
+    (5, 4),
+    (6, 1),
+    (6, 3),
+    (7, 1),
+    (7, 2),
+    (8, 1),
+    (8, 3),
+    (8, 4),
+    (9, 2),
+    (10, 2),
+    (10, 3),
+    (11, 4),
+    (12, 4);
+
+PRAGMA foreign_keys = ON;